@@ -46,12 +46,13 @@ if [[ -n "${providername}" ]]; then
 fi
 
 set +e
-go run ./cmd/verify-gpg-key -org "${namespace}" -username "${GH_USER}" -key-file=tmp.key -output=./output.json
+go run ./cmd/verify-gpg-key -org "${namespace}" -username "${GH_USER}" -key-file=tmp.key -output=./output.json -markdown-output=./output.md
 verification=$?
 set -euo pipefail
 
-gh issue comment "${NUMBER}" -b "$(jq -r '.' < ./output.json || true)"
-if [[ "${verification}" != 0 ]]; then
+gh issue comment "${NUMBER}" -b "$(cat ./output.md || true)"
+# 0 is a clean pass and 10 a pass with warnings for the reviewer, anything else rejects the key
+if [[ "${verification}" != 0 && "${verification}" != 10 ]]; then
   exit 1
 fi
 
@@ -84,10 +85,11 @@ git add "${keyfile}"
 # Commit and push result
 git config --global user.email "no-reply@opentofu.org"
 git config --global user.name "OpenTofu Automation"
+# The Submitted-by trailer lets audit-org find the owner of the key later
 if [[ -n "${providername}" ]]; then
-  git commit -s -m "Create provider key ${namespace}/${providername}"
+  git commit -s -m "Create provider key ${namespace}/${providername}" -m "Submitted-by: ${GH_USER}"
 else
-  git commit -s -m "Create provider key ${namespace}"
+  git commit -s -m "Create provider key ${namespace}" -m "Submitted-by: ${GH_USER}"
 fi
 git push -u origin "${branch}"
 
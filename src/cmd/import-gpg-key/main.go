@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/opentofu/registry-stable/internal/files"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+type Output struct {
+	File         string `json:"file"`
+	Namespace    string `json:"namespace"`
+	ProviderName string `json:"provider_name,omitempty"`
+	Validation   string `json:"validation"`
+	Exists       bool   `json:"exists"`
+}
+
+var namePattern = regexp.MustCompile("^[a-z0-9-]+$")
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	keyFile := flag.String("key-file", "", "Location of the verified GPG key to import")
+	namespace := flag.String("namespace", "", "Provider namespace (GitHub organization) the key belongs to")
+	providerName := flag.String("provider-name", "", "Provider the key is limited to, if it does not apply to the whole namespace")
+	verificationResult := flag.String("verification-result", "", "Path to the JSON result of verify-gpg-key, the import is refused if it reports a failure")
+	keyDataDir := flag.String("key-data", "../keys", "Directory containing the GPG keys")
+	outputFile := flag.String("output", "", "Path to write JSON result to")
+
+	flag.Parse()
+
+	output := Output{
+		Namespace:    strings.ToLower(*namespace),
+		ProviderName: strings.ToLower(*providerName),
+	}
+
+	err := func() error {
+		if !namePattern.MatchString(output.Namespace) {
+			return fmt.Errorf("Invalid namespace: %q", *namespace)
+		}
+		if output.ProviderName != "" && !namePattern.MatchString(output.ProviderName) {
+			return fmt.Errorf("Invalid provider name: %q", *providerName)
+		}
+
+		if *verificationResult != "" {
+			err := checkVerificationResult(*verificationResult)
+			if err != nil {
+				return err
+			}
+		}
+
+		data, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read key file: %w", err)
+		}
+
+		collection := gpg.KeyCollection{
+			Namespace:    output.Namespace,
+			ProviderName: output.ProviderName,
+			Directory:    *keyDataDir,
+		}
+		armored, err := canonicalKey(logger, data)
+		if err != nil {
+			return err
+		}
+		output.File, output.Exists, err = collection.AddKey(armored, time.Now())
+		return err
+	}()
+
+	if err != nil {
+		logger.Error("Unable to import key", slog.Any("err", err))
+		output.Validation = err.Error()
+		// Don't exit yet, still need to write the json.
+	} else if output.Exists {
+		logger.Info("Key already present in the registry", slog.String("file", output.File))
+	} else {
+		logger.Info("Imported key", slog.String("file", output.File))
+	}
+
+	if *outputFile != "" {
+		jsonErr := files.SafeWriteObjectToJSONFile(*outputFile, output)
+		if jsonErr != nil {
+			// This really should not happen
+			panic(jsonErr)
+		}
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// canonicalKey strips third-party certifications and other packets that are not needed to verify signatures from the
+// key, so that stored keys stay small and deterministic. Keys that declare designated revokers are stored as
+// submitted, as the OpenPGP library would drop the designation.
+func canonicalKey(logger *slog.Logger, data []byte) (string, error) {
+	armored, asSubmitted, err := gpg.CanonicalArmor(data)
+	if err != nil {
+		return "", err
+	}
+	if asSubmitted {
+		logger.Warn("Key declares designated revokers, storing it as submitted")
+	}
+	return armored, nil
+}
+
+// checkVerificationResult makes sure that the key passed verification before it is imported.
+func checkVerificationResult(location string) error {
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return fmt.Errorf("failed to read verification result: %w", err)
+	}
+
+	var result verification.Result
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return fmt.Errorf("failed to parse verification result: %w", err)
+	}
+	if result.DidFail() || result.Cancelled {
+		return fmt.Errorf("the key did not pass verification")
+	}
+	return nil
+}
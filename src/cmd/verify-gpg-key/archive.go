@@ -0,0 +1,180 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// archiveLimits bound what is read from a key archive, so that a small archive cannot expand to exhaust the memory or
+// keep the tool busy with millions of entries.
+type archiveLimits struct {
+	MaxFiles int   // Maximum number of entries in the archive, including those that are not keys
+	MaxBytes int64 // Maximum combined size of the extracted key files
+}
+
+// archivedKey is a key file read from a key archive.
+type archivedKey struct {
+	Name string // Location of the key file within the archive
+	Data []byte
+}
+
+// readKeyArchive reads the key files (.asc files) of a .zip, .tar.gz or .tgz archive into memory. Nothing is written
+// to disk, so the paths in the archive are only used as labels. The archive is rejected as a whole if it exceeds the
+// limits.
+func readKeyArchive(location string, limits archiveLimits) ([]archivedKey, error) {
+	switch {
+	case strings.HasSuffix(location, ".zip"):
+		return readZipKeys(location, limits)
+	case strings.HasSuffix(location, ".tar.gz"), strings.HasSuffix(location, ".tgz"):
+		return readTarGzKeys(location, limits)
+	default:
+		return nil, fmt.Errorf("unsupported key archive %s, expected a .zip, .tar.gz or .tgz file", location)
+	}
+}
+
+func readZipKeys(location string, limits archiveLimits) ([]archivedKey, error) {
+	r, err := zip.OpenReader(location)
+	if err != nil {
+		return nil, fmt.Errorf("could not open key archive %s: %w", location, err)
+	}
+	defer r.Close()
+
+	if len(r.File) > limits.MaxFiles {
+		return nil, fmt.Errorf("key archive %s has %d entries, more than the limit of %d", location, len(r.File), limits.MaxFiles)
+	}
+
+	reader := newArchiveKeyReader(limits)
+	for _, file := range r.File {
+		if !file.Mode().IsRegular() || !isKeyFile(file.Name) {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s from key archive %s: %w", file.Name, location, err)
+		}
+		err = reader.read(file.Name, rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s from key archive %s: %w", file.Name, location, err)
+		}
+	}
+	return reader.keys, nil
+}
+
+func readTarGzKeys(location string, limits archiveLimits) ([]archivedKey, error) {
+	file, err := os.Open(location)
+	if err != nil {
+		return nil, fmt.Errorf("could not open key archive %s: %w", location, err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not open key archive %s: %w", location, err)
+	}
+	defer gz.Close()
+
+	reader := newArchiveKeyReader(limits)
+	tr := tar.NewReader(gz)
+	for entries := 1; ; entries++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return reader.keys, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read key archive %s: %w", location, err)
+		}
+		if entries > limits.MaxFiles {
+			return nil, fmt.Errorf("key archive %s has more than the limit of %d entries", location, limits.MaxFiles)
+		}
+		if header.Typeflag != tar.TypeReg || !isKeyFile(header.Name) {
+			continue
+		}
+		if err := reader.read(header.Name, tr); err != nil {
+			return nil, fmt.Errorf("could not read %s from key archive %s: %w", header.Name, location, err)
+		}
+	}
+}
+
+// archiveKeyReader collects the key files of an archive while enforcing the size limit. The sizes declared in the
+// archive are not trusted, the limit applies to the bytes actually extracted.
+type archiveKeyReader struct {
+	keys     []archivedKey
+	size     int64 // Combined size of the keys read so far
+	maxBytes int64
+}
+
+func newArchiveKeyReader(limits archiveLimits) *archiveKeyReader {
+	return &archiveKeyReader{maxBytes: limits.MaxBytes}
+}
+
+func (r *archiveKeyReader) read(name string, contents io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(contents, r.maxBytes-r.size+1))
+	if err != nil {
+		return err
+	}
+	r.size += int64(len(data))
+	if r.size > r.maxBytes {
+		return fmt.Errorf("the extracted key files exceed the limit of %d bytes", r.maxBytes)
+	}
+	r.keys = append(r.keys, archivedKey{Name: path.Clean(name), Data: data})
+	return nil
+}
+
+// isKeyFile returns true if the archive entry is a key, only .asc files are considered keys.
+func isKeyFile(name string) bool {
+	return path.Ext(name) == ".asc"
+}
+
+// verifyKeyArchive verifies each key file of the -key-archive with the given options and combines the results into
+// one report. Unless an organization is given, a key stored in the registry layout (<first letter>/<namespace>/...) is
+// verified against that namespace, any other key requires -org. The results of the individual keys are returned as
+// well.
+func verifyKeyArchive(ctx context.Context, logger *slog.Logger, f *cliFlags, opts verification.VerifyKeyOptions) (*verification.Result, []*verification.Result, error) {
+	keys, err := readKeyArchive(f.KeyArchive, archiveLimits{MaxFiles: f.ArchiveMaxFiles, MaxBytes: f.ArchiveMaxBytes})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("key archive %s contains no key files (.asc)", f.KeyArchive)
+	}
+
+	labels := make([]string, len(keys))
+	keyOpts := make([]verification.VerifyKeyOptions, len(keys))
+	for i, key := range keys {
+		labels[i] = key.Name
+		keyOpts[i] = opts
+		keyOpts[i].KeyData = key.Data
+		keyOpts[i].KeyFile = key.Name
+		if keyOpts[i].Org == "" && !keyOpts[i].UserNamespace {
+			keyOpts[i].Org = keyNamespace(".", key.Name)
+			if keyOpts[i].Org == "" {
+				return nil, nil, fmt.Errorf("could not determine the namespace of %s, it is not stored in the registry layout in %s, use -org", key.Name, f.KeyArchive)
+			}
+		}
+	}
+
+	results, err := verifyEach(len(keys), f.Concurrency, func(i int) (*verification.Result, error) {
+		logger.Info("Verifying archived key", slog.String("key", keys[i].Name), slog.String("org", keyOpts[i].Org))
+		result, err := verification.Verify(ctx, keyOpts[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not verify %s: %w", keys[i].Name, err)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return verification.CombineResults(labels, results), results, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, contents := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	location := filepath.Join(t.TempDir(), "keys.zip")
+	if err := os.WriteFile(location, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func writeTarGz(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for name, contents := range files {
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	location := filepath.Join(t.TempDir(), "keys.tar.gz")
+	if err := os.WriteFile(location, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func TestReadKeyArchive(t *testing.T) {
+	files := map[string]string{
+		"o/opentofu/provider-1.asc": "key one",
+		"o/opentofu/README.md":      "not a key",
+		"b/bar/../baz/key.asc":      "key two",
+	}
+	limits := archiveLimits{MaxFiles: 10, MaxBytes: 1024}
+
+	for name, location := range map[string]string{"zip": writeZip(t, files), "tar.gz": writeTarGz(t, files)} {
+		t.Run(name, func(t *testing.T) {
+			keys, err := readKeyArchive(location, limits)
+			assert.NoError(t, err)
+
+			found := make(map[string]string)
+			for _, key := range keys {
+				found[key.Name] = string(key.Data)
+			}
+			assert.Equal(t, map[string]string{"o/opentofu/provider-1.asc": "key one", "b/baz/key.asc": "key two"}, found)
+		})
+	}
+}
+
+func TestReadKeyArchive_Limits(t *testing.T) {
+	files := map[string]string{
+		"a.asc": strings.Repeat("a", 600),
+		"b.asc": strings.Repeat("b", 600),
+		"c.txt": "not a key",
+	}
+
+	tests := []struct {
+		name   string
+		limits archiveLimits
+		err    string
+	}{
+		{name: "within limits", limits: archiveLimits{MaxFiles: 3, MaxBytes: 1200}},
+		{name: "too many entries", limits: archiveLimits{MaxFiles: 2, MaxBytes: 1200}, err: "than the limit of 2"},
+		{name: "too large", limits: archiveLimits{MaxFiles: 3, MaxBytes: 1000}, err: "exceed the limit of 1000 bytes"},
+	}
+
+	for _, tt := range tests {
+		for name, location := range map[string]string{"zip": writeZip(t, files), "tar.gz": writeTarGz(t, files)} {
+			t.Run(tt.name+" "+name, func(t *testing.T) {
+				keys, err := readKeyArchive(location, tt.limits)
+				if tt.err != "" {
+					assert.ErrorContains(t, err, tt.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Len(t, keys, 2)
+			})
+		}
+	}
+}
+
+func TestReadKeyArchive_Unsupported(t *testing.T) {
+	_, err := readKeyArchive("keys.rar", archiveLimits{MaxFiles: 10, MaxBytes: 1024})
+	assert.ErrorContains(t, err, "unsupported key archive keys.rar")
+}
+
+func TestVerifyKeyArchive_UnknownNamespace(t *testing.T) {
+	location := writeZip(t, map[string]string{"o/opentofu/provider-1.asc": "key one", "key.asc": "key two"})
+	f := &cliFlags{KeyArchive: location, ArchiveMaxFiles: 10, ArchiveMaxBytes: 1024, Concurrency: 1}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, _, err := verifyKeyArchive(context.Background(), logger, f, verification.VerifyKeyOptions{Local: true})
+	assert.ErrorContains(t, err, "could not determine the namespace of key.asc")
+}
+
+func TestVerifyKeyArchive_KeyResults(t *testing.T) {
+	files := map[string]string{}
+	for _, name := range []string{"o/opentofu/provider-1.asc", "o/opentofu/provider-2.asc"} {
+		key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		armored, err := key.GetArmoredPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = armored
+	}
+	f := &cliFlags{KeyArchive: writeZip(t, files), ArchiveMaxFiles: 10, ArchiveMaxBytes: 1 << 20, Concurrency: 1}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	combined, keyResults, err := verifyKeyArchive(context.Background(), logger, f, verification.VerifyKeyOptions{Local: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Each key is counted on its own in the metrics, while the report combines them
+	assert.Len(t, keyResults, 2)
+	assert.Len(t, combined.Steps, len(keyResults[0].Steps)+len(keyResults[1].Steps))
+}
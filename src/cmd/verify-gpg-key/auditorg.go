@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/files"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// noreplyEmailRegex matches the commit email GitHub assigns to a user, with or without the account ID prefix.
+var noreplyEmailRegex = regexp.MustCompile(`(?i)^(?:\d+\+)?([a-z0-9](?:[a-z0-9-]*[a-z0-9])?)@users\.noreply\.github\.com$`)
+
+// staleKey is a registered key whose owner is no longer a public member of the organization.
+type staleKey struct {
+	KeyFile     string `json:"key_file"`
+	Fingerprint string `json:"fingerprint"`
+	Owner       string `json:"owner"`
+}
+
+// auditReport is the JSON output of the audit-org subcommand.
+type auditReport struct {
+	Org    string               `json:"org"`
+	Stale  []staleKey           `json:"stale"`
+	Result *verification.Result `json:"result"`
+}
+
+// auditOrg checks that the owner of every key registered for an organization is still a member of it.
+func auditOrg(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("audit-org", flag.ExitOnError)
+	org := fs.String("org", "", "Organization whose registered keys are audited")
+	keyDataDir := fs.String("key-data", "../keys", "Directory containing the registry's GPG keys")
+	ownersFile := fs.String("owners", "", "JSON file mapping key fingerprints or key files, relative to -key-data, to the GitHub username of their owner")
+	outputFile := fs.String("output", "", "Path to write the JSON report to")
+	markdownFile := fs.String("markdown-output", "", "Path to write the rendered markdown report to")
+	_ = fs.Parse(args)
+
+	if *org == "" {
+		logger.Error("Invalid flags", slog.Any("err", fmt.Errorf("-org is required")))
+		os.Exit(1)
+	}
+
+	owners := map[string]string{}
+	if *ownersFile != "" {
+		data, err := os.ReadFile(*ownersFile)
+		if err == nil {
+			err = json.Unmarshal(data, &owners)
+		}
+		if err == nil {
+			err = validateOwners(owners)
+		}
+		if err != nil {
+			logger.Error("Initialization Error", slog.Any("err", fmt.Errorf("could not read %s: %w", *ownersFile, err)))
+			os.Exit(1)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(logger, cancel)
+
+	ghClient, err := newGithubClient(ctx, logger, 0, 0, "")
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	report, err := auditOrgKeys(ctx, logger, ghClient, *keyDataDir, *org, owners)
+	if err != nil {
+		logger.Error("Audit Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	if err := writeAuditMarkdown(os.Stdout, report); err != nil {
+		logger.Error("Unable to write the report", slog.Any("err", err))
+	}
+	if *outputFile != "" {
+		jsonErr := files.SafeWriteFileFunc(*outputFile, func(w io.Writer) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		})
+		if jsonErr != nil {
+			// This really should not happen
+			panic(jsonErr)
+		}
+	}
+	if *markdownFile != "" {
+		mdErr := files.SafeWriteFileFunc(*markdownFile, func(w io.Writer) error {
+			return writeAuditMarkdown(w, report)
+		})
+		if mdErr != nil {
+			// This really should not happen
+			panic(mdErr)
+		}
+	}
+
+	os.Exit(exitCode(report.Result, false))
+}
+
+// auditOrgKeys runs the organization membership check for the owner of every key registered for the organization and
+// combines the checks into one result, labelled by key file.
+func auditOrgKeys(ctx context.Context, logger *slog.Logger, client verification.OrganizationClient, keyDataDir string, org string, owners map[string]string) (*auditReport, error) {
+	keyFiles, err := orgKeyFiles(keyDataDir, org)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyFiles) == 0 {
+		return nil, fmt.Errorf("no keys are registered for %s in %s", org, keyDataDir)
+	}
+
+	report := &auditReport{Org: org, Stale: []staleKey{}}
+	results := make([]*verification.Result, 0, len(keyFiles))
+	for _, keyFile := range keyFiles {
+		result := &verification.Result{}
+		step := result.AddStep("Key owner is a member of the organization", verification.StatusSuccess)
+		results = append(results, result)
+
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := gpg.ParseKeyBytes(data)
+		if err != nil {
+			step.AddStep("Key is a valid PGP key", verification.StatusFailure, err.Error())
+			continue
+		}
+		fingerprint := strings.ToUpper(key.GetFingerprint())
+
+		owner, source := resolveKeyOwner(keyDataDir, keyFile, key, owners)
+		if owner == "" {
+			step.AddStep("Key owner is known", verification.StatusWarning, "no GitHub user could be associated with the key, add it to -owners")
+			continue
+		}
+		step.Remarks = append(step.Remarks, fmt.Sprintf("Owner %s, from %s", owner, source))
+
+		logger.Info("Checking key owner", slog.String("key", keyFile), slog.String("owner", owner))
+		membership := verification.VerifyOrgMembership(ctx, client, owner, org)
+		step.SubSteps = append(step.SubSteps, membership)
+		if membership.Status == verification.StatusFailure {
+			report.Stale = append(report.Stale, staleKey{KeyFile: keyFile, Fingerprint: fingerprint, Owner: owner})
+		}
+	}
+
+	report.Result = verification.CombineResults(keyFiles, results)
+	if ctx.Err() != nil {
+		report.Result.Cancelled = true
+	}
+	return report, nil
+}
+
+// orgKeyFiles returns the key files registered for the organization, including the keys of its providers, sorted.
+func orgKeyFiles(keyDataDir string, org string) ([]string, error) {
+	dir := gpg.KeyCollection{Namespace: org, Directory: keyDataDir}.NamespacePath()
+	var keyFiles []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".asc" {
+			keyFiles = append(keyFiles, path)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("could not list the keys of %s: %w", org, err)
+	}
+	sort.Strings(keyFiles)
+	return keyFiles, nil
+}
+
+// validateOwners checks the fingerprints in an owners mapping, entries naming a key file are left as they are.
+func validateOwners(owners map[string]string) error {
+	for key := range owners {
+		if strings.Contains(key, "/") || strings.HasSuffix(key, ".asc") {
+			continue
+		}
+		if err := gpg.ValidateFingerprint(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveKeyOwner returns the GitHub username of the owner of the key and where it was found. The owners mapping takes
+// precedence, keyed by fingerprint or by the key file relative to the key data directory. Otherwise the Submitted-by
+// trailer of the commit that added the key is used, and lastly a GitHub noreply address among the user IDs of the key.
+// An empty username is returned if none of them name the owner.
+func resolveKeyOwner(keyDataDir string, keyFile string, key *crypto.Key, owners map[string]string) (string, string) {
+	if owner := owners[strings.ToUpper(key.GetFingerprint())]; owner != "" {
+		return owner, "the owners file"
+	}
+	if rel, err := filepath.Rel(keyDataDir, keyFile); err == nil {
+		if owner := owners[filepath.ToSlash(rel)]; owner != "" {
+			return owner, "the owners file"
+		}
+	}
+
+	// Keys submitted through the issue workflow record the submitter in the commit that adds them
+	trailers, err := git(filepath.Dir(keyFile), "log", "--diff-filter=A", "--format=%(trailers:key=Submitted-by,valueonly)", "--", filepath.Base(keyFile))
+	if err == nil {
+		if fields := strings.Fields(trailers); len(fields) > 0 {
+			return strings.TrimPrefix(fields[len(fields)-1], "@"), "the commit that added the key"
+		}
+	}
+
+	var ids []string
+	for id := range key.GetEntity().Identities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		_, email, err := gpg.ParseUID(id)
+		if err != nil {
+			continue
+		}
+		if match := noreplyEmailRegex.FindStringSubmatch(email); match != nil {
+			return match[1], "the GitHub noreply address of the key"
+		}
+	}
+	return "", ""
+}
+
+// writeAuditMarkdown writes the combined result followed by the list of stale keys.
+func writeAuditMarkdown(w io.Writer, report *auditReport) error {
+	if err := report.Result.WriteMarkdownTo(w); err != nil {
+		return err
+	}
+	if len(report.Stale) == 0 {
+		_, err := fmt.Fprintf(w, "No stale keys found for %s.\n", report.Org)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "## ❌ Stale keys\nThe owners of %d key(s) are no longer public members of %s:\n", len(report.Stale), report.Org); err != nil {
+		return err
+	}
+	for _, stale := range report.Stale {
+		if _, err := fmt.Fprintf(w, "- `%s` (%s), owned by %s\n", stale.KeyFile, stale.Fingerprint, stale.Owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// orgMembers is an OrganizationClient that knows the members of every organization.
+type orgMembers map[string]bool
+
+func (m orgMembers) IsUserInOrganization(username string, _ string) (bool, error) {
+	return m[username], nil
+}
+
+// writeAuditKey generates a key with the given user ID and stores it as the key file, returning the key.
+func writeAuditKey(t *testing.T, keyFile string, name string, email string) *crypto.Key {
+	t.Helper()
+	key, err := crypto.GenerateKey(name, email, "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte(armored), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestAuditOrgKeys(t *testing.T) {
+	keyDataDir := filepath.Join(t.TempDir(), "keys")
+	current := filepath.Join(keyDataDir, "e/example/provider-1.asc")
+	writeAuditKey(t, current, "Current Member", "12345+current@users.noreply.github.com")
+	left := filepath.Join(keyDataDir, "e/example/aws/provider-2.asc")
+	leftKey := writeAuditKey(t, left, "Former Member", "former@example.com")
+	unknown := filepath.Join(keyDataDir, "e/example/provider-3.asc")
+	writeAuditKey(t, unknown, "Unknown Owner", "unknown@example.com")
+	// Another namespace sharing the first letter is not audited
+	writeAuditKey(t, filepath.Join(keyDataDir, "e/other/provider-1.asc"), "Other", "other@example.com")
+
+	owners := map[string]string{strings.ToUpper(leftKey.GetFingerprint()): "former"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	report, err := auditOrgKeys(context.Background(), logger, orgMembers{"current": true}, keyDataDir, "example", owners)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []staleKey{{KeyFile: left, Fingerprint: strings.ToUpper(leftKey.GetFingerprint()), Owner: "former"}}, report.Stale)
+	assert.Equal(t, verification.OutcomeFail, report.Result.Status)
+	assert.Len(t, report.Result.Steps, 3)
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeAuditMarkdown(&buf, report))
+	assert.Contains(t, buf.String(), "Owner current, from the GitHub noreply address of the key")
+	assert.Contains(t, buf.String(), "no GitHub user could be associated with the key, add it to -owners")
+	assert.Contains(t, buf.String(), "## ❌ Stale keys\nThe owners of 1 key(s) are no longer public members of example:\n- `"+left+"`")
+
+	_, err = auditOrgKeys(context.Background(), logger, orgMembers{}, keyDataDir, "missing", nil)
+	assert.ErrorContains(t, err, "no keys are registered for missing")
+}
+
+func TestResolveKeyOwner_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	keyDataDir := filepath.Join(repo, "keys")
+	keyFile := filepath.Join(keyDataDir, "e/example/provider-1.asc")
+	key := writeAuditKey(t, keyFile, "Test User", "test@example.com")
+	run("init", "-q")
+	run("add", "-A")
+	run("commit", "-q", "-s", "-m", "Create provider key example", "-m", "Submitted-by: octocat")
+
+	owner, source := resolveKeyOwner(keyDataDir, keyFile, key, nil)
+	assert.Equal(t, "octocat", owner)
+	assert.Equal(t, "the commit that added the key", source)
+
+	// The owners file takes precedence
+	owner, source = resolveKeyOwner(keyDataDir, keyFile, key, map[string]string{"e/example/provider-1.asc": "hubot"})
+	assert.Equal(t, "hubot", owner)
+	assert.Equal(t, "the owners file", source)
+}
+
+func TestValidateOwners(t *testing.T) {
+	assert.NoError(t, validateOwners(map[string]string{
+		"A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5E4": "first",
+		"e/example/provider-1.asc":                 "second",
+	}))
+	assert.EqualError(t, validateOwners(map[string]string{"5B4A39281706F5E4": "first"}),
+		`fingerprint "5B4A39281706F5E4" has 16 hex digits, expected 40 for a version 4 key or 64 for a version 5 or 6 key`)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/opentofu/registry-stable/internal/parallel"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// verifyEach runs verify for each of n keys, at most concurrency at a time. Every result is stored in the slot of its
+// key, so that the results are in the order of the keys no matter which verification finishes first. If verifications
+// fail, the error of the first key in that order is returned.
+func verifyEach(n int, concurrency int, verify func(i int) (*verification.Result, error)) ([]*verification.Result, error) {
+	results := make([]*verification.Result, n)
+	errs := make([]error, n)
+	actions := make([]parallel.Action, n)
+	for i := range actions {
+		i := i
+		actions[i] = func() error {
+			results[i], errs[i] = verify(i)
+			return nil
+		}
+	}
+	parallel.ForEach(actions, max(concurrency, 1))
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
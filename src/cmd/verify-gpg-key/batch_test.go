@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestVerifyEach(t *testing.T) {
+	// The first keys take the longest, so that they finish last when verified concurrently
+	delays := []time.Duration{40 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 0}
+	verify := func(i int) (*verification.Result, error) {
+		time.Sleep(delays[i])
+		return &verification.Result{Steps: []*verification.Step{{Name: fmt.Sprintf("key %d", i)}}}, nil
+	}
+
+	for _, concurrency := range []int{1, 2, len(delays)} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			results, err := verifyEach(len(delays), concurrency, verify)
+			assert.NoError(t, err)
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Steps[0].Name)
+			}
+			assert.Equal(t, []string{"key 0", "key 1", "key 2", "key 3", "key 4"}, names)
+		})
+	}
+
+	// The error of the first failing key is reported, even if a later key fails first
+	_, err := verifyEach(len(delays), len(delays), func(i int) (*verification.Result, error) {
+		time.Sleep(delays[i])
+		if i == 1 || i == 3 {
+			return nil, fmt.Errorf("could not verify key %d", i)
+		}
+		return &verification.Result{}, nil
+	})
+	assert.EqualError(t, err, "could not verify key 1")
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// changedKeyFiles returns the key files among the files that were added or modified between the base and head refs,
+// or among the explicitly listed files if no base ref is given. Only .asc files are considered keys.
+func changedKeyFiles(keyDataDir string, base string, head string, listed []string) ([]string, error) {
+	changed := listed
+	if base != "" {
+		var err error
+		changed, err = gitChangedFiles(keyDataDir, base, head)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keyFiles []string
+	for _, file := range changed {
+		if filepath.Ext(file) == ".asc" {
+			keyFiles = append(keyFiles, file)
+		}
+	}
+	return keyFiles, nil
+}
+
+// gitChangedFiles lists the files in dir that were added or modified between the base and head refs. Deleted files
+// are left out as there is nothing to verify.
+func gitChangedFiles(dir string, base string, head string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "diff", "--name-only", "--relative", "--diff-filter=AM", "-z", base+"..."+head)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not list the files changed between %s and %s, %w: %s", base, head, err, stderr.String())
+	}
+
+	var files []string
+	for _, name := range strings.Split(stdout.String(), "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files, nil
+}
+
+// keyNamespace returns the namespace a key file belongs to, from its location in the key data directory
+// (<first letter>/<namespace>/[<provider>/]<file>.asc), or an empty string if it is not located there.
+func keyNamespace(keyDataDir string, keyFile string) string {
+	rel, err := filepath.Rel(keyDataDir, keyFile)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 3 || parts[0] == ".." {
+		return ""
+	}
+	return parts[1]
+}
+
+// verifyChangedKeys verifies each changed key file with the given options and combines the results into one report.
+// Unless an organization is given, each key is verified against the namespace it is stored under. The results of the
+// individual keys are returned as well.
+func verifyChangedKeys(ctx context.Context, logger *slog.Logger, f *cliFlags, opts verification.VerifyKeyOptions) (*verification.Result, []*verification.Result, error) {
+	keyFiles, err := changedKeyFiles(f.KeyDataDir, f.ChangedBase, f.ChangedHead, splitList(f.ChangedFiles))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keyFiles) == 0 {
+		logger.Info("No key files were added or modified")
+	}
+
+	keyOpts := make([]verification.VerifyKeyOptions, len(keyFiles))
+	for i, keyFile := range keyFiles {
+		keyOpts[i] = opts
+		keyOpts[i].KeyFile = keyFile
+		if keyOpts[i].Org == "" {
+			keyOpts[i].Org = keyNamespace(f.KeyDataDir, keyFile)
+		}
+		if keyOpts[i].Org == "" {
+			return nil, nil, fmt.Errorf("could not determine the namespace of %s, it is not located in %s, use -org", keyFile, f.KeyDataDir)
+		}
+	}
+
+	results, err := verifyEach(len(keyFiles), f.Concurrency, func(i int) (*verification.Result, error) {
+		logger.Info("Verifying changed key", slog.String("key", keyFiles[i]), slog.String("org", keyOpts[i].Org))
+		result, err := verification.Verify(ctx, keyOpts[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not verify %s: %w", keyFiles[i], err)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return verification.CombineResults(keyFiles, results), results, nil
+}
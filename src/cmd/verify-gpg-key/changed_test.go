@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestKeyNamespace(t *testing.T) {
+	tests := []struct {
+		keyFile  string
+		expected string
+	}{
+		{keyFile: "../keys/o/opentofu/provider-1.asc", expected: "opentofu"},
+		{keyFile: "../keys/o/opentofu/aws/provider-1.asc", expected: "opentofu"},
+		{keyFile: "../keys/provider-1.asc", expected: ""},
+		{keyFile: "../other/o/opentofu/provider-1.asc", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyFile, func(t *testing.T) {
+			assert.Equal(t, tt.expected, keyNamespace("../keys", tt.keyFile))
+		})
+	}
+}
+
+func TestChangedKeyFiles_Listed(t *testing.T) {
+	files, err := changedKeyFiles("../keys", "", "", []string{"../keys/o/opentofu/provider-1.asc", "README.md", "../keys/o/opentofu/notes.txt"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"../keys/o/opentofu/provider-1.asc"}, files)
+}
+
+func TestChangedKeyFiles_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name string, contents string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("keys/a/unchanged/provider-1.asc", "unchanged")
+	write("keys/a/modified/provider-1.asc", "before")
+	write("keys/a/deleted/provider-1.asc", "deleted")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+
+	write("keys/a/modified/provider-1.asc", "after")
+	write("keys/a/added/provider-2.asc", "added")
+	write("keys/a/added/README.md", "not a key")
+	write("providers/a/added/provider.json", "{}")
+	if err := os.Remove(filepath.Join(repo, "keys/a/deleted/provider-1.asc")); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "head")
+
+	keyDataDir := filepath.Join(repo, "keys")
+	files, err := changedKeyFiles(keyDataDir, "base", "HEAD", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(keyDataDir, "a/added/provider-2.asc"),
+		filepath.Join(keyDataDir, "a/modified/provider-1.asc"),
+	}, files)
+
+	_, err = changedKeyFiles(keyDataDir, "missing", "HEAD", nil)
+	assert.ErrorContains(t, err, "could not list the files changed between missing and HEAD")
+}
+
+func TestVerifyChangedKeys_KeyResults(t *testing.T) {
+	keyDataDir := t.TempDir()
+	var keyFiles []string
+	for _, name := range []string{"o/opentofu/provider-1.asc", "o/opentofu/provider-2.asc"} {
+		key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		armored, err := key.GetArmoredPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(keyDataDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(armored), 0600); err != nil {
+			t.Fatal(err)
+		}
+		keyFiles = append(keyFiles, path)
+	}
+	f := &cliFlags{KeyDataDir: keyDataDir, ChangedFiles: strings.Join(keyFiles, ","), Concurrency: 1}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	combined, keyResults, err := verifyChangedKeys(context.Background(), logger, f, verification.VerifyKeyOptions{Local: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Each key is counted on its own in the metrics, while the report combines them
+	assert.Len(t, keyResults, 2)
+	assert.Len(t, combined.Steps, len(keyResults[0].Steps)+len(keyResults[1].Steps))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// newCheckRun converts the verification result into a check run. Failed and warning steps are annotated on
+// annotationPath, if given.
+func newCheckRun(result *verification.Result, name string, sha string, annotationPath string) github.CheckRun {
+	run := github.CheckRun{
+		Name:    name,
+		HeadSHA: sha,
+		Text:    result.RenderMarkdown(),
+	}
+
+	switch {
+	case result.Cancelled:
+		run.Conclusion, run.Title = "cancelled", "Verification was cancelled"
+	case result.Outcome() == verification.OutcomeFail:
+		run.Conclusion, run.Title = "failure", "GPG key verification failed"
+	case result.Outcome() == verification.OutcomeWarn:
+		run.Conclusion, run.Title = "neutral", "GPG key verification passed with warnings"
+	default:
+		run.Conclusion, run.Title = "success", "GPG key verification passed"
+	}
+	run.Summary = strings.TrimSpace(result.RenderSummary())
+
+	if annotationPath == "" {
+		return run
+	}
+	for _, step := range result.Steps {
+		for _, subStep := range step.SubSteps {
+			var level string
+			switch subStep.Status {
+			case verification.StatusFailure, verification.StatusError:
+				level = "failure"
+			case verification.StatusWarning:
+				level = "warning"
+			default:
+				continue
+			}
+			message := strings.Join(subStep.Errors, "\n")
+			if message == "" {
+				message = string(subStep.Status)
+			}
+			run.Annotations = append(run.Annotations, github.CheckRunAnnotation{
+				Path:            annotationPath,
+				StartLine:       1,
+				EndLine:         1,
+				AnnotationLevel: level,
+				Title:           fmt.Sprintf("%s: %s", step.Name, subStep.Name),
+				Message:         message,
+			})
+		}
+	}
+	return run
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestNewCheckRun(t *testing.T) {
+	result := &verification.Result{}
+	s := result.AddStep("Validate GPG key", verification.StatusSuccess)
+	s.AddStep("Key is not expired", verification.StatusFailure, "key is expired")
+	s.AddStep("Key is not revoked", verification.StatusSuccess)
+	s.AddStep("Key declares acceptable hash preferences", verification.StatusWarning)
+
+	run := newCheckRun(result, "GPG key verification", "abc123", "keys/e/example/provider.asc")
+	assert.Equal(t, "failure", run.Conclusion)
+	assert.Equal(t, "abc123", run.HeadSHA)
+	assert.Equal(t, []github.CheckRunAnnotation{
+		{
+			Path:            "keys/e/example/provider.asc",
+			StartLine:       1,
+			EndLine:         1,
+			AnnotationLevel: "failure",
+			Title:           "Validate GPG key: Key is not expired",
+			Message:         "key is expired",
+		},
+		{
+			Path:            "keys/e/example/provider.asc",
+			StartLine:       1,
+			EndLine:         1,
+			AnnotationLevel: "warning",
+			Title:           "Validate GPG key: Key declares acceptable hash preferences",
+			Message:         "warning",
+		},
+	}, run.Annotations)
+
+	run = newCheckRun(result, "GPG key verification", "abc123", "")
+	assert.Empty(t, run.Annotations)
+}
+
+func TestNewCheckRun_Conclusion(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     *verification.Result
+		conclusion string
+	}{
+		{name: "pass", result: &verification.Result{Status: verification.OutcomePass}, conclusion: "success"},
+		{name: "warn", result: &verification.Result{Status: verification.OutcomeWarn}, conclusion: "neutral"},
+		{name: "fail", result: &verification.Result{Status: verification.OutcomeFail}, conclusion: "failure"},
+		{name: "cancelled", result: &verification.Result{Status: verification.OutcomePass, Cancelled: true}, conclusion: "cancelled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.conclusion, newCheckRun(tt.result, "name", "sha", "").Conclusion)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// discoverOrgReport is the JSON output of the discover-org subcommand.
+type discoverOrgReport struct {
+	Fingerprint string                  `json:"fingerprint"`
+	Orgs        []verification.OrgMatch `json:"orgs"` // Best match first
+	Unreachable []string                `json:"unreachable,omitempty"`
+}
+
+// discoverOrg ranks candidate organizations by how many of their providers were signed by a key, for contributors
+// who do not know which organization their provider is published under.
+func discoverOrg(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("discover-org", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "Location of the GPG key to look for")
+	candidates := fs.String("candidates", "", "Comma separated list of organizations the key may sign providers in")
+	providerDataDir := fs.String("provider-data", "../providers", "Directory containing the provider data")
+	latest := fs.Bool("latest", false, "Only check the latest release of each provider")
+	format := fs.String("format", "table", "Output format, one of table or json")
+	cacheDir := fs.String("cache-dir", "", "Directory to cache downloaded release assets in, defaults to a directory in the user cache directory")
+	noCache := fs.Bool("no-cache", false, "Do not cache downloaded release assets")
+	downloadTimeout := fs.Duration("download-timeout", 30*time.Second, "Maximum duration of a single release asset download, a stuck download is skipped after it. 0 disables the timeout")
+	retryBudget := fs.Int("retry-budget", 50, "Maximum number of GitHub requests retried during the scan, further requests that need a retry fail right away. 0 removes the limit")
+	_ = fs.Parse(args)
+
+	var errs []error
+	if *keyFile == "" {
+		errs = append(errs, fmt.Errorf("-key-file is required"))
+	}
+	if len(splitList(*candidates)) == 0 {
+		errs = append(errs, fmt.Errorf("-candidates is required"))
+	}
+	if *retryBudget < 0 {
+		errs = append(errs, fmt.Errorf("-retry-budget cannot be negative"))
+	}
+	if *format != "table" && *format != "json" {
+		errs = append(errs, fmt.Errorf("unsupported format %q, expected table or json", *format))
+	}
+	if len(errs) > 0 {
+		for _, err := range errs {
+			logger.Error("Invalid flags", slog.Any("err", err))
+		}
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+	key, err := gpg.ParseKeyBytes(data)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(logger, cancel)
+
+	ghClient, err := newScanClient(ctx, logger, *cacheDir, *noCache, *downloadTimeout, *retryBudget)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	orgs, unreachable, err := verification.DiscoverOrgs(ctx, ghClient, key, *providerDataDir, splitList(*candidates), *latest)
+	if err != nil {
+		logger.Error("Scan Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	report := discoverOrgReport{Fingerprint: strings.ToUpper(key.GetFingerprint()), Orgs: orgs}
+	for _, err := range unreachable {
+		logger.Warn("Unable to check release", slog.Any("err", err))
+		report.Unreachable = append(report.Unreachable, err.Error())
+	}
+
+	if *format == "json" {
+		err = json.NewEncoder(os.Stdout).Encode(report)
+	} else {
+		err = writeDiscoverOrgTable(os.Stdout, report)
+	}
+	if err != nil {
+		logger.Error("Unable to write report", slog.Any("err", err))
+		os.Exit(1)
+	}
+}
+
+// writeDiscoverOrgTable writes the report as a table with one row per candidate organization, best match first.
+func writeDiscoverOrgTable(w io.Writer, report discoverOrgReport) error {
+	matched := 0
+	for _, org := range report.Orgs {
+		if len(org.SignedProviders) > 0 {
+			matched++
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Key %s signed providers in %d of %d candidate organization(s)\n", report.Fingerprint, matched, len(report.Orgs))
+	fmt.Fprintln(tw, "RANK\tORGANIZATION\tSIGNED PROVIDERS\tSIGNED RELEASES")
+	for i, org := range report.Orgs {
+		rank := "-"
+		if len(org.SignedProviders) > 0 {
+			rank = fmt.Sprint(i + 1)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d of %d\t%d of %d\n", rank, org.Org, len(org.SignedProviders), org.Providers, org.SignedReleases, org.CheckedReleases)
+	}
+	if matched > 0 {
+		fmt.Fprintf(tw, "Best match: %s\n", report.Orgs[0].Org)
+	}
+	if len(report.Unreachable) > 0 {
+		fmt.Fprintf(tw, "%d release(s) could not be checked\n", len(report.Unreachable))
+	}
+	return tw.Flush()
+}
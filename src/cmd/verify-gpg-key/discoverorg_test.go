@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestWriteDiscoverOrgTable(t *testing.T) {
+	report := discoverOrgReport{
+		Fingerprint: "4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE",
+		Orgs: []verification.OrgMatch{
+			{
+				Org:       "example",
+				Providers: 3,
+				SignedProviders: []verification.SignedProvider{
+					{Namespace: "example", Provider: "foo", Versions: []string{"1.1.0", "1.0.0"}},
+					{Namespace: "example", Provider: "bar", Versions: []string{"0.1.0"}},
+				},
+				SignedReleases:  3,
+				CheckedReleases: 5,
+			},
+			{Org: "other-example", Providers: 1, CheckedReleases: 1},
+		},
+		Unreachable: []string{"example/baz 1.0.0: 404 Not Found"},
+	}
+
+	var out strings.Builder
+	assert.NoError(t, writeDiscoverOrgTable(&out, report))
+	assert.Equal(t, `Key 4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE signed providers in 1 of 2 candidate organization(s)
+RANK  ORGANIZATION   SIGNED PROVIDERS  SIGNED RELEASES
+1     example        2 of 3            3 of 5
+-     other-example  0 of 1            0 of 1
+Best match: example
+1 release(s) could not be checked
+`, out.String())
+}
+
+func TestWriteDiscoverOrgTable_NoMatch(t *testing.T) {
+	report := discoverOrgReport{
+		Fingerprint: "4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE",
+		Orgs:        []verification.OrgMatch{{Org: "example", Providers: 1, CheckedReleases: 1}},
+	}
+
+	var out strings.Builder
+	assert.NoError(t, writeDiscoverOrgTable(&out, report))
+	assert.Equal(t, `Key 4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE signed providers in 0 of 1 candidate organization(s)
+RANK  ORGANIZATION  SIGNED PROVIDERS  SIGNED RELEASES
+-     example       0 of 1            0 of 1
+`, out.String())
+}
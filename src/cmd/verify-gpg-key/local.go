@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// localCheck runs the checks of CI on the contributor's machine before a key is pushed, for example from a pre-commit
+// hook that passes the changed key files as arguments. The GitHub checks only run if GH_TOKEN is set and -username is
+// given, otherwise only the keys themselves are checked.
+func localCheck(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("local", flag.ExitOnError)
+	username := fs.String("username", "", "GitHub username submitting the keys, the GitHub checks are run if it is given and GH_TOKEN is set")
+	org := fs.String("org", "", "Organization the keys are submitted for, defaults to the namespace of each key in -key-data")
+	keyDataDir := fs.String("key-data", "../keys", "Directory containing the registry's GPG keys")
+	providerDataDir := fs.String("provider-data", "", "Directory containing the registry's provider data, enables the provider signature check")
+	offline := fs.Bool("offline", false, "Only check the keys themselves, even if GH_TOKEN is set")
+	failOnWarning := fs.Bool("fail-on-warning", false, "Exit with the failure code if any step produced a warning")
+	lang := fs.String("lang", "en", "Language of the step names in the output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: verify-gpg-key local [flags] <key file>...\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	keyFiles := fs.Args()
+	if len(keyFiles) == 0 {
+		logger.Error("Invalid flags", slog.Any("err", fmt.Errorf("at least one key file is required")))
+		os.Exit(1)
+	}
+
+	catalog, err := verification.NewCatalog(*lang)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(logger, cancel)
+
+	opts := verification.VerifyKeyOptions{
+		Username:        *username,
+		Org:             *org,
+		KeyDataDir:      *keyDataDir,
+		ProviderDataDir: *providerDataDir,
+		Catalog:         catalog,
+		Progress: func(parent *verification.Step, step *verification.Step) {
+			fmt.Print(verification.RenderProgress(parent, step))
+		},
+		Local: true,
+	}
+	token, tokenErr := github.EnvAuthToken()
+	switch {
+	case *offline:
+		fmt.Println("Only checking the keys themselves, the GitHub and provider checks run in CI")
+	case tokenErr != nil || *username == "":
+		fmt.Println("GH_TOKEN or -username is not set, only checking the keys themselves. The GitHub and provider checks run in CI")
+	default:
+		// The GitHub client logs every request, which is noise for a local run
+		quiet := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+		opts.Github = github.NewClient(ctx, quiet, token)
+		opts.Local = false
+	}
+
+	result, err := verifyLocalKeys(ctx, opts, keyFiles)
+	if err != nil {
+		logger.Error("Verification Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	fmt.Print(result.RenderSummary())
+	if err := writeRemediation(os.Stdout, result); err != nil {
+		logger.Error("Unable to write the result", slog.Any("err", err))
+	}
+
+	os.Exit(exitCode(result, *failOnWarning))
+}
+
+// verifyLocalKeys verifies each key file with the given options and combines the results if there is more than one.
+// Unless an organization is given, each key stored in opts.KeyDataDir is verified against its namespace there.
+func verifyLocalKeys(ctx context.Context, opts verification.VerifyKeyOptions, keyFiles []string) (*verification.Result, error) {
+	results, err := verifyEach(len(keyFiles), 1, func(i int) (*verification.Result, error) {
+		keyOpts := opts
+		keyOpts.KeyFile = keyFiles[i]
+		if keyOpts.Org == "" {
+			keyOpts.Org = keyNamespace(opts.KeyDataDir, keyFiles[i])
+		}
+		result, err := verification.Verify(ctx, keyOpts)
+		if err != nil {
+			return nil, fmt.Errorf("could not verify %s: %w", keyFiles[i], err)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 1 {
+		return results[0], nil
+	}
+	return verification.CombineResults(keyFiles, results), nil
+}
+
+// writeRemediation explains why each check that failed or warned matters and how to fix it, from the step definitions
+// and the remediations of the step. Nothing is written if every check passed.
+func writeRemediation(w io.Writer, result *verification.Result) error {
+	definitions := make(map[string]verification.StepDefinition)
+	for _, definition := range verification.Steps() {
+		definitions[definition.ID] = definition
+	}
+
+	var b strings.Builder
+	var walk func(steps []*verification.Step)
+	walk = func(steps []*verification.Step) {
+		for _, step := range steps {
+			definition, ok := definitions[step.ID]
+			if ok && (step.Status == verification.StatusFailure || step.Status == verification.StatusWarning) {
+				fmt.Fprintf(&b, "\n[%s] %s\n", step.Status, step.Name)
+				for _, err := range step.Errors {
+					fmt.Fprintf(&b, "  - %s\n", strings.ReplaceAll(err, "\n", "\n    "))
+				}
+				fmt.Fprintf(&b, "  Why: %s.\n", definition.Rationale)
+				if definition.DocsURL != "" {
+					fmt.Fprintf(&b, "  How to fix: %s\n", definition.DocsURL)
+				}
+				for _, remediation := range step.Remediations {
+					fmt.Fprintf(&b, "  How to fix: %s. %s\n", remediation.Title, remediation.Description)
+					if remediation.URL != "" {
+						fmt.Fprintf(&b, "    %s\n", remediation.URL)
+					}
+				}
+			}
+			walk(step.SubSteps)
+		}
+	}
+	walk(result.Steps)
+
+	if b.Len() == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w, "\nBefore pushing, please address the following:\n"+b.String())
+	return err
+}
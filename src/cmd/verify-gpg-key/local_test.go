@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestVerifyLocalKeys(t *testing.T) {
+	keyDataDir := filepath.Join(t.TempDir(), "keys")
+	first := filepath.Join(keyDataDir, "e/example/provider-1.asc")
+	writeAuditKey(t, first, "First", "first@example.com")
+	second := filepath.Join(keyDataDir, "o/other/provider-1.asc")
+	writeAuditKey(t, second, "Second", "second@example.com")
+
+	opts := verification.VerifyKeyOptions{KeyDataDir: keyDataDir, Local: true}
+	result, err := verifyLocalKeys(context.Background(), opts, []string{first})
+	assert.NoError(t, err)
+	assert.Len(t, result.Steps, 1)
+	assert.False(t, result.DidFail())
+
+	result, err = verifyLocalKeys(context.Background(), opts, []string{first, second})
+	assert.NoError(t, err)
+	assert.Len(t, result.Steps, 2)
+	assert.Contains(t, result.Steps[0].Name, first)
+	assert.Contains(t, result.Steps[1].Name, second)
+
+	_, err = verifyLocalKeys(context.Background(), opts, []string{filepath.Join(keyDataDir, "missing.asc")})
+	assert.NoError(t, err, "an unreadable key is a failed check, not an error")
+}
+
+func TestWriteRemediation(t *testing.T) {
+	var buf bytes.Buffer
+	passed := &verification.Result{Steps: []*verification.Step{{Name: "Validate key", Status: verification.StatusSuccess}}}
+	assert.NoError(t, writeRemediation(&buf, passed))
+	assert.Empty(t, buf.String())
+
+	failed := &verification.Result{Steps: []*verification.Step{{
+		Name:   "Validate key",
+		Status: verification.StatusFailure,
+		SubSteps: []*verification.Step{
+			{ID: verification.StepIDExpiry, Name: "Key is not expired", Status: verification.StatusFailure, Errors: []string{"key is expired"}},
+			{ID: verification.StepIDRevocation, Name: "Key is not revoked", Status: verification.StatusSuccess},
+		},
+	}}}
+	assert.NoError(t, writeRemediation(&buf, failed))
+	assert.Contains(t, buf.String(), "Before pushing, please address the following:\n\n[failure] Key is not expired\n  - key is expired\n  Why: ")
+	assert.NotContains(t, buf.String(), "Key is not revoked")
+
+	buf.Reset()
+	notMember := &verification.Result{Steps: []*verification.Step{{
+		Name:   "Validate Github user",
+		Status: verification.StatusFailure,
+		SubSteps: []*verification.Step{{
+			ID:     verification.StepIDOrgMembership,
+			Name:   "User is a member of the organization example",
+			Status: verification.StatusFailure,
+			Errors: []string{"user is not a member of the organization"},
+			Remediations: []verification.Remediation{{
+				Title:       "Make your organization membership public",
+				Description: "Only public members of the organization can be verified.",
+				URL:         "https://example.com/membership",
+			}},
+		}},
+	}}}
+	assert.NoError(t, writeRemediation(&buf, notMember))
+	assert.Contains(t, buf.String(), "  How to fix: Make your organization membership public. Only public members of the organization can be verified.\n    https://example.com/membership\n")
+}
@@ -2,175 +2,422 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
-	"net/mail"
 	"os"
-	"regexp"
+	"os/signal"
+	"syscall"
 
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
 
 	"github.com/opentofu/registry-stable/internal/files"
 	"github.com/opentofu/registry-stable/internal/github"
-	"github.com/opentofu/registry-stable/internal/gpg"
 	"github.com/opentofu/registry-stable/pkg/verification"
 )
 
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	keyFile := flag.String("key-file", "", "Location of the GPG key to verify")
-	username := flag.String("username", "", "Github username to verify the GPG key against")
-	orgName := flag.String("org", "", "Github organization name to verify the GPG key against")
-	outputFile := flag.String("output", "", "Path to write JSON result to")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			serve(logger, os.Args[2:])
+			return
+		case "signed-providers":
+			signedProviders(logger, os.Args[2:])
+			return
+		case "discover-org":
+			discoverOrg(logger, os.Args[2:])
+			return
+		case "rotation":
+			rotation(logger, os.Args[2:])
+			return
+		case "audit-org":
+			auditOrg(logger, os.Args[2:])
+			return
+		case "local":
+			localCheck(logger, os.Args[2:])
+			return
+		case "submission":
+			submission(logger, os.Args[2:])
+			return
+		}
+	}
+
+	f := registerFlags(flag.CommandLine)
 	flag.Parse()
 
-	logger = logger.With(slog.String("github", *username), slog.String("org", *orgName))
-	slog.SetDefault(logger)
-	logger.Debug("Verifying GPG key from location", slog.String("location", *keyFile))
+	if f.ConfigFile != "" {
+		if err := applyConfig(flag.CommandLine, f.ConfigFile); err != nil {
+			logger.Error("Invalid config", slog.Any("err", err))
+			os.Exit(1)
+		}
+	}
 
-	token, err := github.EnvAuthToken()
-	if err != nil {
-		logger.Error("Initialization Error", slog.Any("err", err))
-		os.Exit(1)
+	if f.Explain {
+		if err := writeStepExplanation(os.Stdout); err != nil {
+			logger.Error("Unable to explain the steps", slog.Any("err", err))
+			os.Exit(1)
+		}
+		return
 	}
 
-	ctx := context.Background()
-	ghClient := github.NewClient(ctx, logger, token)
+	if f.ListSteps {
+		if err := writeStepList(os.Stdout); err != nil {
+			logger.Error("Unable to list the steps", slog.Any("err", err))
+			os.Exit(1)
+		}
+		return
+	}
 
-	result := &verification.Result{}
+	if err := f.validate(); err != nil {
+		logger.Error("Invalid flags", slog.Any("err", err))
+		os.Exit(1)
+	}
 
-	s := VerifyKey(*keyFile)
-	result.Steps = append(result.Steps, s)
+	logger = logger.With(slog.String("github", f.Username), slog.String("org", f.Org))
+	if f.PullRequest != 0 {
+		logger = logger.With(slog.Int("pr", f.PullRequest))
+	}
+	slog.SetDefault(logger)
+	logger.Debug("Verifying GPG key from location", slog.String("location", f.KeyFile))
+	keyData := f.keyData()
+	if isPrivateKey(keyData) {
+		logger.Warn("A private key was passed with -key, it may be visible to other users in the process list and shell history. Consider revoking it and only share the public key")
+	}
 
-	s = VerifyGithubUser(ghClient, *username, *orgName)
-	result.Steps = append(result.Steps, s)
+	filter, err := verification.NewStepFilter(f.Only, f.Skip)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
 
-	// TODO: Add verification to ensure that the key has been used to sign providers in this github organization
+	severity, err := verification.ParseSeverityOverrides(f.Severity)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
 
-	fmt.Println(result.RenderMarkdown())
+	catalog, err := verification.NewCatalog(f.Lang)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
 
-	if *outputFile != "" {
-		jsonErr := files.SafeWriteObjectToJSONFile(*outputFile, result.RenderMarkdown())
-		if jsonErr != nil {
-			// This really should not happen
-			panic(jsonErr)
+	var outputKey *crypto.Key
+	if f.SignOutput {
+		// Read before verifying, so that a wrong key or passphrase is reported right away
+		outputKey, err = readOutputSigningKey(f.SignOutputKey)
+		if err != nil {
+			logger.Error("Initialization Error", slog.Any("err", err))
+			os.Exit(1)
 		}
 	}
 
-	if result.DidFail() {
-		os.Exit(-1)
+	var denylist map[string]bool
+	if f.DenylistFile != "" {
+		denylist, err = files.ReadDenylist(f.DenylistFile)
+		if err != nil {
+			logger.Error("Initialization Error", slog.Any("err", err))
+			os.Exit(1)
+		}
 	}
-}
 
-func VerifyGithubUser(client github.Client, username string, orgName string) *verification.Step {
-	verifyStep := &verification.Step{
-		Name: "Validate Github user",
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(logger, cancel)
+	if f.TraceID != "" {
+		ctx = github.ContextWithTrace(ctx, f.TraceHeader, f.TraceID)
+		logger = logger.With(slog.String("trace_id", f.TraceID))
+		slog.SetDefault(logger)
 	}
 
-	s := verifyStep.RunStep(fmt.Sprintf("User is a member of the organization %s", orgName), func() error {
-		member, err := client.IsUserInOrganization(username, orgName)
+	var ghClient github.Client
+	var verifyClient verification.GithubClient
+	if !f.Local {
+		ghClient, err = newGithubClient(ctx, logger, f.AppID, f.AppInstallationID, f.AppPrivateKey)
 		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			logger.Error("Initialization Error", slog.Any("err", err))
+			os.Exit(1)
 		}
-		if member {
-			return nil
-		} else {
-			return fmt.Errorf("user is not a member of the organization")
+		cacheDir, err := f.assetCacheDir()
+		if err != nil {
+			logger.Error("Initialization Error", slog.Any("err", err))
+			os.Exit(1)
 		}
-	})
-	s.Remarks = []string{"If this is incorrect, please ensure that your organization membership is public. For more information, see [Github Docs - Publicizing or hiding organization membership](https://docs.github.com/en/account-and-profile/setting-up-and-managing-your-personal-account-on-github/managing-your-membership-in-organizations/publicizing-or-hiding-organization-membership)"}
+		if cacheDir != "" {
+			ghClient = ghClient.WithAssetCache(cacheDir)
+		}
+		ghClient = ghClient.WithAssetTimeout(f.DownloadTimeout).WithRetryBudget(f.RetryBudget).WithRetryJitter(f.RetryJitter, 0)
+		verifyClient = ghClient
+	}
 
-	return verifyStep
-}
+	var checkout *registryCheckout
+	if f.RegistryRef != "" {
+		checkout, err = checkoutRegistryData(f)
+		if err != nil {
+			logger.Error("Initialization Error", slog.Any("err", err))
+			os.Exit(1)
+		}
+		logger = logger.With(slog.String("registry_ref", checkout.commit))
+		slog.SetDefault(logger)
+	}
 
-var gpgNameEmailRegex = regexp.MustCompile(`.*\<(.*)\>`)
+	// Validated with the other flags
+	registries, _ := parseRegistries(f.Registries)
 
-func VerifyKey(location string) *verification.Step {
-	verifyStep := &verification.Step{
-		Name: "Validate GPG key",
+	var progress verification.ProgressFunc
+	if f.Stream {
+		progress = func(parent *verification.Step, step *verification.Step) {
+			if f.OnlyProblems && step.Status == verification.StatusSuccess {
+				return
+			}
+			fmt.Print(verification.RenderProgress(parent, step))
+		}
 	}
 
-	// read the key from the filesystem
-	data, err := os.ReadFile(location)
+	opts := verification.VerifyKeyOptions{
+		KeyData:               keyData,
+		KeyFile:               f.KeyFile,
+		KeyEnv:                f.KeyEnv,
+		Username:              f.Username,
+		Org:                   f.Org,
+		SkipOrgCheck:          f.SkipOrgCheck,
+		UserNamespace:         f.UserNamespace,
+		ProviderOrgs:          splitList(f.ProviderOrgs),
+		FullCoverage:          f.FullCoverage,
+		NewKey:                f.NewKey,
+		ProviderDataDir:       f.ProviderDataDir,
+		Registries:            registries,
+		ReleaseRepo:           f.ReleaseRepo,
+		ReleaseTag:            f.ReleaseTag,
+		VerifyReleaseDigests:  f.VerifyReleaseDigests,
+		KeyDataDir:            f.KeyDataDir,
+		RejectSHA1Prefs:       f.RejectSHA1Prefs,
+		Strict:                f.Strict,
+		RequireSignedProvider: f.RequireSignedProvider,
+		CheckFilename:         f.CheckFilename,
+		CheckTofuCompat:       f.CheckTofuCompat,
+		Denylist:              denylist,
+		AllowSuperseded:       f.AllowSuperseded,
+		RejectTrailingData:    f.RejectTrailingData,
+		MaxValidityYears:      f.MaxValidityYears,
+		MaxSelfSigAgeYears:    f.MaxSelfSigAgeYears,
+		CheckEncryption:       f.CheckEncryption,
+		CompareGithubKey:      f.CompareGithubKey,
+		CompareGithubKeyExact: f.CompareGithubKeyExact,
+		CompareGithubEmail:    f.CompareGithubEmail,
+		CheckOrgDomains:       f.CheckOrgDomains,
+		RejectInactiveAccount: f.RejectInactiveAccount,
+		ShowNotations:         f.ShowNotations,
+		EchoKey:               f.EchoKey,
+		SignatureFile:         f.SignatureFile,
+		MessageFile:           f.MessageFile,
+		Filter:                filter,
+		Severity:              severity,
+		Progress:              progress,
+		Catalog:               catalog,
+		Github:                verifyClient,
+		Local:                 f.Local,
+	}
+	var result *verification.Result
+	var keyResults []*verification.Result // One per verified key, for the metrics
+	switch {
+	case f.changedMode():
+		result, keyResults, err = verifyChangedKeys(ctx, logger, f, opts)
+	case f.KeyArchive != "":
+		result, keyResults, err = verifyKeyArchive(ctx, logger, f, opts)
+	default:
+		result, err = verification.Verify(ctx, opts)
+		keyResults = []*verification.Result{result}
+	}
+	var registryCommit string
+	if checkout != nil {
+		// The registry data is no longer needed once the verification is done
+		registryCommit = checkout.commit
+		if removeErr := checkout.remove(); removeErr != nil {
+			logger.Warn("Unable to clean up the registry checkout", slog.Any("err", removeErr))
+		}
+	}
 	if err != nil {
-		verifyStep.AddError(fmt.Errorf("failed to read key file: %w", err))
-		verifyStep.Status = verification.StatusFailure
-		return verifyStep
+		logger.Error("Verification Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+	if result.Cancelled {
+		logger.Warn("Verification was cancelled, writing partial result")
+	}
+	result.Metadata = &verification.Metadata{APIRequests: ghClient.RequestCount(), PullRequest: f.PullRequest, RegistryRef: registryCommit}
+	logger.Info("Verification finished", slog.Int64("api_requests", result.Metadata.APIRequests))
+
+	writeMarkdown := result.WriteMarkdownTo
+	switch {
+	case f.GroupBy == "provider" && f.OnlyProblems:
+		writeMarkdown = result.WriteMarkdownProblemsByProviderTo
+	case f.GroupBy == "provider":
+		writeMarkdown = result.WriteMarkdownByProviderTo
+	case f.OnlyProblems:
+		writeMarkdown = result.WriteMarkdownProblemsTo
 	}
 
-	var key *crypto.Key
-	verifyStep.RunStep("Key is a valid PGP key", func() error {
-		k, err := gpg.ParseKey(string(data))
-		if err != nil {
-			return fmt.Errorf("could not parse key: %w", err)
+	if f.Stream && f.OnlyProblems {
+		fmt.Print(result.RenderProblemsSummary())
+	} else if f.Stream {
+		fmt.Print(result.RenderSummary())
+	} else if f.Format == "junit" {
+		if err := result.WriteJUnitTo(os.Stdout); err != nil {
+			logger.Error("Unable to write the result", slog.Any("err", err))
 		}
-		key = k
-		return nil
-	})
+	} else {
+		if err := writeMarkdown(os.Stdout); err != nil {
+			logger.Error("Unable to write the result", slog.Any("err", err))
+		}
+		fmt.Println()
+	}
 
-	if key == nil {
-		// The previous step failed.
-		return verifyStep
+	writeFile := files.SafeWriteFileFunc
+	if f.LockOutput {
+		writeFile = files.LockedWriteFileFunc
 	}
 
-	verifyStep.RunStep("Key is not expired", func() error {
-		if key.IsExpired() {
-			return fmt.Errorf("key is expired")
+	if f.OutputFile != "" {
+		var jsonErr error
+		if outputKey != nil {
+			jsonErr = writeSignedJSON(writeFile, f.OutputFile, result, outputKey)
+		} else {
+			jsonErr = writeFile(f.OutputFile, result.WriteJSONTo)
 		}
-		return nil
-	})
+		if errors.Is(jsonErr, files.ErrLocked) {
+			logger.Error("Unable to write the result", slog.Any("err", jsonErr))
+			os.Exit(1)
+		}
+		if jsonErr != nil {
+			// This really should not happen
+			panic(jsonErr)
+		}
+	}
 
-	verifyStep.RunStep("Key is not revoked", func() error {
-		if key.IsRevoked() {
-			return fmt.Errorf("key is revoked")
+	if f.MarkdownFile != "" {
+		mdErr := writeFile(f.MarkdownFile, writeMarkdown)
+		if errors.Is(mdErr, files.ErrLocked) {
+			logger.Error("Unable to write the result", slog.Any("err", mdErr))
+			os.Exit(1)
 		}
-		return nil
-	})
+		if mdErr != nil {
+			// This really should not happen
+			panic(mdErr)
+		}
+	}
 
-	verifyStep.RunStep("Key can be used for signing", func() error {
-		if !key.CanVerify() {
-			return fmt.Errorf("key cannot be used for signing")
+	if f.AnnotateFile != "" {
+		annotateErr := writeFile(f.AnnotateFile, func(w io.Writer) error {
+			return result.WriteGithubAnnotationsTo(w, f.KeyFile)
+		})
+		if annotateErr != nil {
+			logger.Error("Unable to write the annotations", slog.Any("err", annotateErr))
+			if errors.Is(annotateErr, files.ErrLocked) {
+				os.Exit(1)
+			}
 		}
-		return nil
-	})
+	}
 
-	emailStep := verifyStep.RunStep("Key has a valid identity and email. (Email is preferable but optional)", func() error {
-		if key.GetFingerprint() == "" {
-			return fmt.Errorf("key has no fingerprint")
+	if f.MetricsFile != "" {
+		err = writeFile(f.MetricsFile, func(w io.Writer) error {
+			return verification.WritePrometheusMetrics(w, metricsResults(keyResults, result.Metadata))
+		})
+		if err != nil {
+			logger.Error("Unable to write metrics", slog.Any("err", err))
 		}
+	}
 
-		entity := key.GetEntity()
-		if entity == nil {
-			return fmt.Errorf("key has no entity")
+	if f.CheckRunRepo != "" {
+		annotationPath := f.CheckRunPath
+		if annotationPath == "" {
+			annotationPath = f.KeyFile
 		}
+		err = ghClient.UpsertCheckRun(f.CheckRunRepo, newCheckRun(result, f.CheckRunName, f.CheckRunSHA, annotationPath))
+		if err != nil {
+			logger.Error("Unable to report check run", slog.Any("err", err))
+		}
+	}
 
-		identities := entity.Identities
-		if len(identities) == 0 {
-			return fmt.Errorf("key has no identities")
+	os.Exit(exitCode(result, f.FailOnWarning))
+}
+
+// metricsResults returns the results of the individual keys for the metrics to count each key. The metadata of the
+// run covers all keys, it is attached to the first result only so that its GitHub requests are counted once.
+func metricsResults(keyResults []*verification.Result, metadata *verification.Metadata) []*verification.Result {
+	results := make([]*verification.Result, len(keyResults))
+	for i, r := range keyResults {
+		copied := *r
+		copied.Metadata = nil
+		if i == 0 {
+			copied.Metadata = metadata
 		}
+		results[i] = &copied
+	}
+	return results
+}
 
-		for idName, identity := range identities {
-			if identity.Name == "" {
-				return fmt.Errorf("key identity %s has no name", idName)
-			}
+// Exit codes of verify-gpg-key, so that CI can tell a clean pass from one that should be reviewed and from a rejected
+// key without parsing the output.
+const (
+	exitPass = 0  // Every step succeeded or was skipped
+	exitFail = 1  // A step failed, the run was cancelled or the key could not be verified at all
+	exitWarn = 10 // No step failed, but at least one produced a warning that should be reviewed
+)
 
-			email := gpgNameEmailRegex.FindStringSubmatch(identity.Name)
-			if len(email) != 2 {
-				return fmt.Errorf("key identity %s has no email", idName)
-			}
+// exitCode returns the exit code for the result. Warnings are treated as failures if failOnWarning is set.
+func exitCode(result *verification.Result, failOnWarning bool) int {
+	switch {
+	case result.DidFail() || result.Cancelled:
+		return exitFail
+	case result.Outcome() == verification.OutcomeWarn && failOnWarning:
+		return exitFail
+	case result.Outcome() == verification.OutcomeWarn:
+		return exitWarn
+	default:
+		return exitPass
+	}
+}
 
-			_, err := mail.ParseAddress(email[1])
-			if err != nil {
-				return fmt.Errorf("key identity %s has an invalid email: %w", idName, err)
-			}
+// newGithubClient authenticates as a GitHub App when its credentials are given and falls back to the GH_TOKEN personal
+// access token otherwise.
+func newGithubClient(ctx context.Context, logger *slog.Logger, appID int64, installationID int64, privateKeyFile string) (github.Client, error) {
+	if appID == 0 && installationID == 0 && privateKeyFile == "" {
+		token, err := github.EnvAuthToken()
+		if err != nil {
+			return github.Client{}, err
 		}
+		return github.NewClient(ctx, logger, token), nil
+	}
 
-		return nil
-	})
+	privateKey, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return github.Client{}, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
 
-	emailStep.FailureToWarning()
+	return github.NewAppClient(ctx, logger, github.AppCredentials{
+		AppID:          appID,
+		InstallationID: installationID,
+		PrivateKey:     privateKey,
+	})
+}
 
-	return verifyStep
+// handleSignals cancels the verification on the first SIGINT/SIGTERM so that the partial result can still be written.
+// A second signal exits immediately.
+func handleSignals(logger *slog.Logger, cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		logger.Warn("Received signal, cancelling verification. Send it again to exit immediately", slog.String("signal", sig.String()))
+		cancel()
+
+		sig = <-signals
+		logger.Error("Received second signal, exiting immediately", slog.String("signal", sig.String()))
+		os.Exit(1)
+	}()
 }
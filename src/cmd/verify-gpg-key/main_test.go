@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestExitCode(t *testing.T) {
+	result := func(status verification.Status) *verification.Result {
+		r := &verification.Result{}
+		r.AddStep("Step", verification.StatusSuccess).AddStep("Sub step", status)
+		r.ComputeStatus()
+		return r
+	}
+	cancelled := result(verification.StatusSuccess)
+	cancelled.Cancelled = true
+
+	tests := []struct {
+		name          string
+		result        *verification.Result
+		failOnWarning bool
+		code          int
+	}{
+		{
+			name:   "pass",
+			result: result(verification.StatusSuccess),
+			code:   exitPass,
+		},
+		{
+			name:   "skipped",
+			result: result(verification.StatusSkipped),
+			code:   exitPass,
+		},
+		{
+			name:   "warning",
+			result: result(verification.StatusWarning),
+			code:   exitWarn,
+		},
+		{
+			name:          "warning as failure",
+			result:        result(verification.StatusWarning),
+			failOnWarning: true,
+			code:          exitFail,
+		},
+		{
+			name:   "failure",
+			result: result(verification.StatusFailure),
+			code:   exitFail,
+		},
+		{
+			name:   "cancelled",
+			result: cancelled,
+			code:   exitFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, exitCode(tt.result, tt.failOnWarning))
+		})
+	}
+}
+
+func TestMetricsResults(t *testing.T) {
+	result := func(status verification.Status) *verification.Result {
+		r := &verification.Result{}
+		r.AddStep("Step", status)
+		r.ComputeStatus()
+		return r
+	}
+	keyResults := []*verification.Result{result(verification.StatusSuccess), result(verification.StatusFailure), result(verification.StatusSuccess)}
+	metadata := &verification.Metadata{APIRequests: 7}
+
+	var b strings.Builder
+	assert.NoError(t, verification.WritePrometheusMetrics(&b, metricsResults(keyResults, metadata)))
+	assert.Contains(t, b.String(), "verify_keys_total{status=\"pass\"} 2\n")
+	assert.Contains(t, b.String(), "verify_keys_total{status=\"fail\"} 1\n")
+	// The requests of the run are only counted once
+	assert.Contains(t, b.String(), "verify_github_api_requests_total 7\n")
+	// The results of the keys are left untouched
+	assert.Nil(t, keyResults[0].Metadata)
+}
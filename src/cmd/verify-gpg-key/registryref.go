@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// registryCheckout is a temporary worktree of the registry repository at a fixed commit, so that a key is verified
+// against exactly the provider and key data of that commit.
+type registryCheckout struct {
+	repo   string // Top level directory of the registry repository
+	dir    string // Directory of the worktree
+	commit string // Full SHA of the checked out commit
+}
+
+// checkoutRegistry checks out ref of the git repository containing dataDir into a temporary worktree.
+func checkoutRegistry(dataDir string, ref string) (*registryCheckout, error) {
+	repo, err := git(dataDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", dataDir, err)
+	}
+	commit, err := git(repo, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve registry ref %s: %w", ref, err)
+	}
+
+	dir, err := os.MkdirTemp("", "registry-"+commit[:12]+"-")
+	if err != nil {
+		return nil, fmt.Errorf("could not create the registry worktree: %w", err)
+	}
+	if _, err := git(repo, "worktree", "add", "--detach", "--quiet", dir, commit); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("could not check out registry ref %s: %w", ref, err)
+	}
+	return &registryCheckout{repo: repo, dir: dir, commit: commit}, nil
+}
+
+// checkoutRegistryData checks out -registry-ref and points -provider-data and -key-data at the worktree.
+func checkoutRegistryData(f *cliFlags) (*registryCheckout, error) {
+	dataDir := f.ProviderDataDir
+	if dataDir == "" {
+		dataDir = f.KeyDataDir
+	}
+	checkout, err := checkoutRegistry(dataDir, f.RegistryRef)
+	if err != nil {
+		return nil, err
+	}
+
+	providerDataDir, providerErr := checkout.path(f.ProviderDataDir)
+	keyDataDir, keyErr := checkout.path(f.KeyDataDir)
+	if err := errors.Join(providerErr, keyErr); err != nil {
+		_ = checkout.remove()
+		return nil, err
+	}
+	f.ProviderDataDir = providerDataDir
+	f.KeyDataDir = keyDataDir
+	return checkout, nil
+}
+
+// path returns the location of a directory of the registry repository within the worktree. An empty path stays empty,
+// so that disabled data directories remain disabled.
+func (c *registryCheckout) path(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	// The top level directory reported by git has its symlinks resolved
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(c.repo, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not in the registry repository %s", dir, c.repo)
+	}
+	return filepath.Join(c.dir, rel), nil
+}
+
+// remove deletes the worktree.
+func (c *registryCheckout) remove() error {
+	if _, err := git(c.repo, "worktree", "remove", "--force", c.dir); err != nil {
+		_ = os.RemoveAll(c.dir)
+		return fmt.Errorf("could not remove the registry worktree: %w", err)
+	}
+	return nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckoutRegistryData(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	gitCmd := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name string, contents string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gitCmd("init", "-q")
+	write("keys/a/example/provider-1.asc", "pinned")
+	write("providers/a/example/provider.json", "{}")
+	gitCmd("add", "-A")
+	gitCmd("commit", "-q", "-m", "pinned")
+	gitCmd("tag", "pinned")
+	write("keys/a/example/provider-1.asc", "latest")
+	gitCmd("add", "-A")
+	gitCmd("commit", "-q", "-m", "latest")
+
+	f := &cliFlags{ProviderDataDir: filepath.Join(repo, "providers"), KeyDataDir: filepath.Join(repo, "keys"), RegistryRef: "pinned"}
+	checkout, err := checkoutRegistryData(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, checkout.commit, 40)
+	assert.Equal(t, filepath.Join(checkout.dir, "providers"), f.ProviderDataDir)
+	assert.Equal(t, filepath.Join(checkout.dir, "keys"), f.KeyDataDir)
+
+	data, err := os.ReadFile(filepath.Join(f.KeyDataDir, "a/example/provider-1.asc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pinned", string(data))
+
+	assert.NoError(t, checkout.remove())
+	assert.NoDirExists(t, checkout.dir)
+}
+
+func TestCheckoutRegistryData_UnknownRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	_, err := checkoutRegistryData(&cliFlags{KeyDataDir: repo, RegistryRef: "does-not-exist"})
+	assert.ErrorContains(t, err, "could not resolve registry ref does-not-exist")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/files"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// rotation checks that a new key is a valid rotation of an old key and exits with the same codes as a verification.
+func rotation(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("rotation", flag.ExitOnError)
+	oldKeyFile := fs.String("old-key", "", "Location of the GPG key that is being replaced")
+	newKeyFile := fs.String("new-key", "", "Location of the GPG key that replaces the old key")
+	outputFile := fs.String("output", "", "Location to write the result as JSON to")
+	lang := fs.String("lang", "en", "Language of the step names in the result")
+	_ = fs.Parse(args)
+
+	if *oldKeyFile == "" || *newKeyFile == "" {
+		logger.Error("Invalid flags", slog.Any("err", fmt.Errorf("-old-key and -new-key are required")))
+		os.Exit(1)
+	}
+
+	catalog, err := verification.NewCatalog(*lang)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	result, err := verifyRotationFiles(verification.VerifyKeyOptions{Catalog: catalog}, *oldKeyFile, *newKeyFile)
+	if err != nil {
+		logger.Error("Verification Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	if err := result.WriteMarkdownTo(os.Stdout); err != nil {
+		logger.Error("Unable to write the result", slog.Any("err", err))
+	}
+	fmt.Println()
+
+	if *outputFile != "" {
+		jsonErr := files.SafeWriteFileFunc(*outputFile, result.WriteJSONTo)
+		if jsonErr != nil {
+			// This really should not happen
+			panic(jsonErr)
+		}
+	}
+
+	os.Exit(exitCode(result, false))
+}
+
+// verifyRotationFiles reads both keys and checks the rotation relationship between them.
+func verifyRotationFiles(opts verification.VerifyKeyOptions, oldKeyFile string, newKeyFile string) (*verification.Result, error) {
+	oldKey, err := readKeyFile(oldKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the old key: %w", err)
+	}
+	newKey, err := readKeyFile(newKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the new key: %w", err)
+	}
+
+	result := &verification.Result{Steps: []*verification.Step{verification.VerifyRotation(opts, oldKey, newKey)}}
+	result.ComputeStatus()
+	return result, nil
+}
+
+// readKeyFile parses the key stored in the file.
+func readKeyFile(path string) (*crypto.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return gpg.ParseKeyBytes(data)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestVerifyRotationFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name string) string {
+		t.Helper()
+		key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		armored, err := key.GetArmoredPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(armored), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	oldKey, newKey := writeKey("old.asc"), writeKey("new.asc")
+
+	result, err := verifyRotationFiles(verification.VerifyKeyOptions{}, oldKey, newKey)
+	assert.NoError(t, err)
+	assert.Equal(t, verification.OutcomeFail, result.Status)
+	assert.Equal(t, exitFail, exitCode(result, false))
+
+	_, err = verifyRotationFiles(verification.VerifyKeyOptions{}, filepath.Join(dir, "missing.asc"), newKey)
+	assert.ErrorContains(t, err, "could not read the old key")
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// serveConfig limits the resources a single verification request may use.
+type serveConfig struct {
+	Timeout         time.Duration // Maximum duration of a single verification
+	MaxBodySize     int64         // Maximum size of a request body, in bytes
+	MaxConcurrent   int           // Maximum number of verifications running at the same time
+	ProviderDataDir string        // Directory containing the provider data
+}
+
+// verifyRequest is the body accepted by POST /verify.
+type verifyRequest struct {
+	Key      string `json:"key"`      // The ascii armored key to verify
+	Username string `json:"username"` // GitHub username to verify the key against
+	Org      string `json:"org"`      // GitHub organization the user must be a member of
+}
+
+// serve runs verify-gpg-key as an HTTP service exposing POST /verify.
+func serve(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "Address to listen on")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Maximum duration of a single verification")
+	maxBodySize := fs.Int64("max-body-size", 1<<20, "Maximum size of a request body, in bytes")
+	maxConcurrent := fs.Int("max-concurrent", 4, "Maximum number of verifications running at the same time")
+	downloadTimeout := fs.Duration("download-timeout", 30*time.Second, "Maximum duration of a single provider release asset download, 0 disables the timeout")
+	providerDataDir := fs.String("provider-data", "../providers", "Directory containing the provider data, set to an empty string to skip the providers scan")
+	appID := fs.Int64("app-id", 0, "GitHub App ID to authenticate as, instead of using the GH_TOKEN personal access token")
+	appInstallationID := fs.Int64("app-installation-id", 0, "Installation ID of the GitHub App")
+	appPrivateKey := fs.String("app-private-key", "", "Location of the GitHub App's PEM encoded private key")
+	_ = fs.Parse(args)
+
+	cfg := serveConfig{
+		Timeout:         *timeout,
+		MaxBodySize:     *maxBodySize,
+		MaxConcurrent:   *maxConcurrent,
+		ProviderDataDir: *providerDataDir,
+	}
+	if err := cfg.validate(); err != nil {
+		logger.Error("Invalid flags", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ghClient, err := newGithubClient(ctx, logger, *appID, *appInstallationID, *appPrivateKey)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+	ghClient = ghClient.WithAssetTimeout(*downloadTimeout)
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           newVerifyHandler(logger, ghClient, cfg),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		// Leave room to write the result of a verification that ran up to the timeout
+		WriteTimeout: cfg.Timeout + 30*time.Second,
+	}
+
+	logger.Info("Starting verification server", slog.String("listen", *listen))
+	err = server.ListenAndServe()
+	if err != nil {
+		logger.Error("Failed to start server", slog.Any("err", err))
+		os.Exit(1)
+	}
+}
+
+// validate checks that the limits leave room for at least one verification.
+func (cfg serveConfig) validate() error {
+	var errs []error
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("-timeout must be positive"))
+	}
+	if cfg.MaxBodySize < 1 {
+		errs = append(errs, fmt.Errorf("-max-body-size must be at least 1"))
+	}
+	if cfg.MaxConcurrent < 1 {
+		errs = append(errs, fmt.Errorf("-max-concurrent must be at least 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// newVerifyHandler returns the handler serving POST /verify.
+func newVerifyHandler(logger *slog.Logger, client verification.GithubClient, cfg serveConfig) http.Handler {
+	slots := make(chan struct{}, cfg.MaxConcurrent)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		default:
+			w.Header().Set("Retry-After", "10")
+			writeJSONError(w, http.StatusServiceUnavailable, fmt.Errorf("too many verifications in progress"))
+			return
+		}
+
+		var req verifyRequest
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, cfg.MaxBodySize)).Decode(&req)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", cfg.MaxBodySize))
+				return
+			}
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		if req.Key == "" || req.Username == "" || req.Org == "" {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("key, username and org are required"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), cfg.Timeout)
+		defer cancel()
+
+		reqLogger := logger.With(slog.String("github", req.Username), slog.String("org", req.Org))
+		result, err := verification.Verify(ctx, verification.VerifyKeyOptions{
+			KeyData:         []byte(req.Key),
+			Username:        req.Username,
+			Org:             req.Org,
+			ProviderDataDir: cfg.ProviderDataDir,
+			Github:          client,
+		})
+		if err != nil {
+			reqLogger.Error("Verification Error", slog.Any("err", err))
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		reqLogger.Info("Verified key", slog.String("status", string(result.Status)))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	})
+	return mux
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
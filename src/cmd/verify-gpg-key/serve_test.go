@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+type memberClient struct {
+	entered chan struct{} // Signalled when a membership lookup starts, if set
+	block   chan struct{} // Membership lookups wait for this to be closed, if set
+}
+
+func (c memberClient) IsUserInOrganization(_ string, _ string) (bool, error) {
+	if c.entered != nil {
+		c.entered <- struct{}{}
+	}
+	if c.block != nil {
+		<-c.block
+	}
+	return true, nil
+}
+
+func (c memberClient) GetUserGPGKeys(_ string) ([]github.GPGKey, error) {
+	return nil, nil
+}
+
+func (c memberClient) GetUser(username string) (github.User, error) {
+	return github.User{Login: username, Type: "User"}, nil
+}
+
+func (c memberClient) DownloadAssetContents(_ string) ([]byte, error) {
+	return nil, nil
+}
+
+func (c memberClient) GetReleaseByTag(_ string, _ string) (github.Release, error) {
+	return github.Release{}, nil
+}
+
+func TestVerifyHandler(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validBody, err := json.Marshal(verifyRequest{Key: armored, Username: "user", Org: "org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := serveConfig{Timeout: time.Minute, MaxBodySize: 64 << 10, MaxConcurrent: 1}
+	handler := newVerifyHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), memberClient{}, cfg)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		error  string
+	}{
+		{name: "valid key", method: http.MethodPost, body: string(validBody), status: http.StatusOK},
+		{name: "wrong method", method: http.MethodGet, status: http.StatusMethodNotAllowed, error: "method GET not allowed"},
+		{name: "invalid json", method: http.MethodPost, body: "{", status: http.StatusBadRequest, error: "invalid request body"},
+		{name: "missing fields", method: http.MethodPost, body: `{"key": "abc"}`, status: http.StatusBadRequest, error: "key, username and org are required"},
+		{name: "body too large", method: http.MethodPost, body: `{"key": "` + strings.Repeat("a", 128<<10) + `"}`, status: http.StatusRequestEntityTooLarge, error: "request body exceeds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, "/verify", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.error != "" {
+				var body map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Contains(t, body["error"], tt.error)
+				return
+			}
+
+			var result verification.Result
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			// Generated keys never expire, which is only a warning
+			assert.Equal(t, verification.OutcomeWarn, result.Status)
+		})
+	}
+}
+
+func TestVerifyHandler_ConcurrencyLimit(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(verifyRequest{Key: armored, Username: "user", Org: "org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := memberClient{entered: make(chan struct{}), block: make(chan struct{})}
+	cfg := serveConfig{Timeout: time.Minute, MaxBodySize: 64 << 10, MaxConcurrent: 1}
+	handler := newVerifyHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), client, cfg)
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(string(body))))
+		done <- rec.Code
+	}()
+
+	// The first verification holds the only slot while it waits on the membership lookup
+	<-client.entered
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(string(body))))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	close(client.block)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestServeConfig_Validate(t *testing.T) {
+	valid := serveConfig{Timeout: time.Minute, MaxBodySize: 64 << 10, MaxConcurrent: 1}
+	assert.NoError(t, valid.validate())
+
+	tests := []struct {
+		name   string
+		modify func(cfg *serveConfig)
+		err    string
+	}{
+		{name: "no concurrency", modify: func(cfg *serveConfig) { cfg.MaxConcurrent = 0 }, err: "-max-concurrent must be at least 1"},
+		{name: "negative concurrency", modify: func(cfg *serveConfig) { cfg.MaxConcurrent = -1 }, err: "-max-concurrent must be at least 1"},
+		{name: "no timeout", modify: func(cfg *serveConfig) { cfg.Timeout = 0 }, err: "-timeout must be positive"},
+		{name: "no body", modify: func(cfg *serveConfig) { cfg.MaxBodySize = 0 }, err: "-max-body-size must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			assert.EqualError(t, cfg.validate(), tt.err)
+		})
+	}
+}
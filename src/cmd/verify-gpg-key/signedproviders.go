@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// signedProvidersReport is the JSON output of the signed-providers subcommand.
+type signedProvidersReport struct {
+	Fingerprint string                        `json:"fingerprint"`
+	Providers   []verification.SignedProvider `json:"providers"`
+	Unreachable []string                      `json:"unreachable,omitempty"`
+}
+
+// signedProviders reports which provider releases in the registry were signed by a key.
+func signedProviders(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("signed-providers", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "Location of the GPG key to look for")
+	orgs := fs.String("org", "", "Comma separated list of organizations whose providers are scanned, all providers in the registry are scanned if empty")
+	providerDataDir := fs.String("provider-data", "../providers", "Directory containing the provider data")
+	latest := fs.Bool("latest", false, "Only check the latest release of each provider")
+	format := fs.String("format", "table", "Output format, one of table or json")
+	cacheDir := fs.String("cache-dir", "", "Directory to cache downloaded release assets in, defaults to a directory in the user cache directory")
+	noCache := fs.Bool("no-cache", false, "Do not cache downloaded release assets")
+	downloadTimeout := fs.Duration("download-timeout", 30*time.Second, "Maximum duration of a single release asset download, a stuck download is skipped after it. 0 disables the timeout")
+	retryBudget := fs.Int("retry-budget", 50, "Maximum number of GitHub requests retried during the scan, further requests that need a retry fail right away. 0 removes the limit")
+	_ = fs.Parse(args)
+
+	if *keyFile == "" {
+		logger.Error("Invalid flags", slog.Any("err", fmt.Errorf("-key-file is required")))
+		os.Exit(1)
+	}
+	if *retryBudget < 0 {
+		logger.Error("Invalid flags", slog.Any("err", fmt.Errorf("-retry-budget cannot be negative")))
+		os.Exit(1)
+	}
+	if *format != "table" && *format != "json" {
+		logger.Error("Invalid flags", slog.Any("err", fmt.Errorf("unsupported format %q, expected table or json", *format)))
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+	key, err := gpg.ParseKeyBytes(data)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(logger, cancel)
+
+	ghClient, err := newScanClient(ctx, logger, *cacheDir, *noCache, *downloadTimeout, *retryBudget)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	signed, unreachable, err := verification.ScanSignedProviders(ctx, ghClient, key, *providerDataDir, splitList(*orgs), *latest)
+	if err != nil {
+		logger.Error("Scan Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	report := signedProvidersReport{Fingerprint: strings.ToUpper(key.GetFingerprint()), Providers: signed}
+	for _, err := range unreachable {
+		logger.Warn("Unable to check release", slog.Any("err", err))
+		report.Unreachable = append(report.Unreachable, err.Error())
+	}
+
+	if *format == "json" {
+		err = json.NewEncoder(os.Stdout).Encode(report)
+	} else {
+		err = writeSignedProvidersTable(os.Stdout, report)
+	}
+	if err != nil {
+		logger.Error("Unable to write report", slog.Any("err", err))
+		os.Exit(1)
+	}
+}
+
+// newScanClient returns a GitHub client for scanning the release assets of many providers, caching the assets in
+// cacheDir unless noCache is set.
+func newScanClient(ctx context.Context, logger *slog.Logger, cacheDir string, noCache bool, downloadTimeout time.Duration, retryBudget int) (github.Client, error) {
+	ghClient, err := newGithubClient(ctx, logger, 0, 0, "")
+	if err != nil {
+		return github.Client{}, err
+	}
+	cache, err := (&cliFlags{CacheDir: cacheDir, NoCache: noCache}).assetCacheDir()
+	if err != nil {
+		return github.Client{}, err
+	}
+	if cache != "" {
+		ghClient = ghClient.WithAssetCache(cache)
+	}
+	return ghClient.WithAssetTimeout(downloadTimeout).WithRetryBudget(retryBudget), nil
+}
+
+// writeSignedProvidersTable writes the report as a table with one row per provider.
+func writeSignedProvidersTable(w io.Writer, report signedProvidersReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Key %s signed %d provider(s)\n", report.Fingerprint, len(report.Providers))
+	fmt.Fprintln(tw, "PROVIDER\tVERSIONS")
+	for _, p := range report.Providers {
+		fmt.Fprintf(tw, "%s/%s\t%s\n", p.Namespace, p.Provider, strings.Join(p.Versions, ", "))
+	}
+	if len(report.Unreachable) > 0 {
+		fmt.Fprintf(tw, "%d release(s) could not be checked\n", len(report.Unreachable))
+	}
+	return tw.Flush()
+}
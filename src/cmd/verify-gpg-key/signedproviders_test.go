@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestWriteSignedProvidersTable(t *testing.T) {
+	report := signedProvidersReport{
+		Fingerprint: "4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE",
+		Providers: []verification.SignedProvider{
+			{Namespace: "example", Provider: "foo", Versions: []string{"1.1.0", "1.0.0"}},
+			{Namespace: "example", Provider: "longer-name", Versions: []string{"0.1.0"}},
+		},
+		Unreachable: []string{"example/bar 1.0.0: 404 Not Found"},
+	}
+
+	var out strings.Builder
+	assert.NoError(t, writeSignedProvidersTable(&out, report))
+	assert.Equal(t, `Key 4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE signed 2 provider(s)
+PROVIDER             VERSIONS
+example/foo          1.1.0, 1.0.0
+example/longer-name  0.1.0
+1 release(s) could not be checked
+`, out.String())
+}
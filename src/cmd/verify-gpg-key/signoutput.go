@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// signOutputPassphraseEnv names the environment variable holding the passphrase of a locked -sign-output-key.
+const signOutputPassphraseEnv = "SIGN_OUTPUT_PASSPHRASE"
+
+// readOutputSigningKey reads the private key the JSON result is signed with. This is the key of whoever runs the
+// verification, never the contributor's key.
+func readOutputSigningKey(location string) (*crypto.Key, error) {
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the output signing key: %w", err)
+	}
+	return gpg.ParseSigningKey(data, []byte(os.Getenv(signOutputPassphraseEnv)))
+}
+
+// writeSignedJSON writes the JSON result to location and an armored detached signature over exactly the written bytes
+// to location.sig, so that downstream systems can tell whether the result was altered.
+func writeSignedJSON(writeFile func(string, func(io.Writer) error) error, location string, result *verification.Result, key *crypto.Key) error {
+	var output bytes.Buffer
+	if err := result.WriteJSONTo(&output); err != nil {
+		return err
+	}
+	signature, err := gpg.SignDetached(key, output.Bytes())
+	if err != nil {
+		return fmt.Errorf("could not sign the result: %w", err)
+	}
+
+	if err := writeFile(location, func(w io.Writer) error {
+		_, err := w.Write(output.Bytes())
+		return err
+	}); err != nil {
+		return err
+	}
+	return writeFile(location+".sig", func(w io.Writer) error {
+		_, err := w.Write(signature)
+		return err
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/files"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestWriteSignedJSON(t *testing.T) {
+	privateKey, err := crypto.GenerateKey("Registry", "registry@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked, err := privateKey.Lock([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := locked.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "signing.asc")
+	if err := os.WriteFile(keyFile, []byte(armored), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = readOutputSigningKey(keyFile)
+	assert.ErrorContains(t, err, "the signing key is locked and no passphrase was given")
+
+	t.Setenv(signOutputPassphraseEnv, "secret")
+	key, err := readOutputSigningKey(keyFile)
+	assert.NoError(t, err)
+
+	result := &verification.Result{}
+	result.AddStep("Validate GPG key", verification.StatusSuccess)
+	output := filepath.Join(dir, "result.json")
+	assert.NoError(t, writeSignedJSON(files.SafeWriteFileFunc, output, result, key))
+
+	written, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(written), `"name": "Validate GPG key"`)
+	signature, err := os.ReadFile(output + ".sig")
+	assert.NoError(t, err)
+
+	publicKey, err := key.ToPublic()
+	assert.NoError(t, err)
+	_, err = gpg.VerifyDetachedSignature(publicKey, written, signature)
+	assert.NoError(t, err)
+}
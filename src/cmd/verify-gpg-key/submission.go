@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/registry-stable/internal/files"
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+// submissionNamePattern is the form of the namespaces and provider names keys are stored under.
+var submissionNamePattern = regexp.MustCompile("^[a-z0-9-]+$")
+
+// keySubmission is the JSON output of the submission subcommand: the key file it added to the registry and a suggested
+// commit and pull request to submit it with, following the submission workflow of the provider key issue template.
+type keySubmission struct {
+	File          string               `json:"file"` // Relative to the root of the registry repository
+	Namespace     string               `json:"namespace"`
+	ProviderName  string               `json:"provider_name,omitempty"`
+	Fingerprint   string               `json:"fingerprint"`
+	Outcome       verification.Outcome `json:"outcome"`
+	Branch        string               `json:"branch"`
+	CommitMessage string               `json:"commit_message"`
+	Title         string               `json:"title"`
+	Body          string               `json:"body"` // Markdown
+}
+
+// submission verifies a key and, if it passes, adds it to the registry's key data and suggests the commit and pull
+// request that submit it. Nothing is written if the key fails verification or is already in the registry. The GitHub
+// checks only run if GH_TOKEN is set, otherwise CI runs them once the pull request is opened.
+func submission(logger *slog.Logger, args []string) {
+	fs := flag.NewFlagSet("submission", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "Location of the GPG key to submit")
+	org := fs.String("org", "", "Provider namespace (GitHub organization) the key is submitted for")
+	providerName := fs.String("provider-name", "", "Provider the key is limited to, if it does not apply to the whole namespace")
+	username := fs.String("username", "", "GitHub username submitting the key")
+	keyDataDir := fs.String("key-data", "../keys", "Directory containing the registry's GPG keys, the key is added to it")
+	providerDataDir := fs.String("provider-data", "", "Directory containing the registry's provider data, enables the provider signature check")
+	newKey := fs.Bool("new-key", false, "The key is registered before the first release, skips the provider signature check")
+	outputFile := fs.String("output", "", "Location to write the submission as JSON to")
+	markdownOutputFile := fs.String("markdown-output", "", "Location to write the pull request body as markdown to")
+	lang := fs.String("lang", "en", "Language of the step names in the output")
+	_ = fs.Parse(args)
+
+	*org = strings.ToLower(*org)
+	*providerName = strings.ToLower(*providerName)
+	if err := validateSubmission(*keyFile, *org, *providerName, *username); err != nil {
+		logger.Error("Invalid flags", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	catalog, err := verification.NewCatalog(*lang)
+	if err != nil {
+		logger.Error("Initialization Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleSignals(logger, cancel)
+
+	opts := verification.VerifyKeyOptions{
+		KeyFile:         *keyFile,
+		Username:        *username,
+		Org:             *org,
+		KeyDataDir:      *keyDataDir,
+		ProviderDataDir: *providerDataDir,
+		NewKey:          *newKey,
+		Catalog:         catalog,
+		Progress: func(parent *verification.Step, step *verification.Step) {
+			fmt.Print(verification.RenderProgress(parent, step))
+		},
+		Local: true,
+	}
+	if token, err := github.EnvAuthToken(); err == nil {
+		// The GitHub client logs every request, which is noise for a local run
+		quiet := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+		opts.Github = github.NewClient(ctx, quiet, token)
+		opts.Local = false
+	} else {
+		fmt.Println("GH_TOKEN is not set, only checking the key itself. The GitHub and provider checks run in CI")
+	}
+
+	result, sub, err := prepareSubmission(ctx, opts, *providerName, time.Now())
+	if result != nil {
+		fmt.Print(result.RenderSummary())
+	}
+	if err != nil {
+		logger.Error("Submission Error", slog.Any("err", err))
+		os.Exit(1)
+	}
+	if sub == nil {
+		if err := writeRemediation(os.Stdout, result); err != nil {
+			logger.Error("Unable to write the result", slog.Any("err", err))
+		}
+		os.Exit(exitCode(result, false))
+	}
+
+	if err := writeSubmissionInstructions(os.Stdout, sub); err != nil {
+		logger.Error("Unable to write the submission", slog.Any("err", err))
+	}
+
+	if *outputFile != "" {
+		if err := files.SafeWriteObjectToJSONFile(*outputFile, sub); err != nil {
+			logger.Error("Unable to write the submission", slog.Any("err", err))
+			os.Exit(1)
+		}
+	}
+	if *markdownOutputFile != "" {
+		if err := files.SafeWriteFile(*markdownOutputFile, []byte(sub.Body)); err != nil {
+			logger.Error("Unable to write the submission", slog.Any("err", err))
+			os.Exit(1)
+		}
+	}
+
+	os.Exit(exitCode(result, false))
+}
+
+// validateSubmission checks the flags of the submission subcommand the way the provider key issue workflow checks the
+// issue form.
+func validateSubmission(keyFile string, org string, providerName string, username string) error {
+	var errs []error
+	if keyFile == "" {
+		errs = append(errs, fmt.Errorf("-key-file is required"))
+	}
+	if username == "" {
+		errs = append(errs, fmt.Errorf("-username is required"))
+	}
+	if !submissionNamePattern.MatchString(org) {
+		errs = append(errs, fmt.Errorf("invalid namespace: %q", org))
+	}
+	if providerName != "" && !submissionNamePattern.MatchString(providerName) {
+		errs = append(errs, fmt.Errorf("invalid provider name: %q", providerName))
+	}
+	if strings.HasPrefix(providerName, "terraform-provider-") {
+		errs = append(errs, fmt.Errorf("the provider name should not include the 'terraform-provider-' prefix: %q", providerName))
+	}
+	return errors.Join(errs...)
+}
+
+// prepareSubmission verifies the key in opts.KeyFile and, unless it fails, stores it canonicalized in opts.KeyDataDir
+// for the opts.Org namespace and the given provider. The submission is nil if the key failed verification, the result
+// is nil if it could not be verified.
+func prepareSubmission(ctx context.Context, opts verification.VerifyKeyOptions, providerName string, now time.Time) (*verification.Result, *keySubmission, error) {
+	result, err := verification.Verify(ctx, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not verify %s: %w", opts.KeyFile, err)
+	}
+	if result.DidFail() || result.Cancelled {
+		return result, nil, nil
+	}
+
+	data, err := os.ReadFile(opts.KeyFile)
+	if err != nil {
+		return result, nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	armored, _, err := gpg.CanonicalArmor(data)
+	if err != nil {
+		return result, nil, err
+	}
+	key, err := gpg.ParseKey(armored)
+	if err != nil {
+		return result, nil, fmt.Errorf("could not parse key: %w", err)
+	}
+
+	collection := gpg.KeyCollection{Namespace: opts.Org, ProviderName: providerName, Directory: opts.KeyDataDir}
+	location, exists, err := collection.AddKey(armored, now)
+	if err != nil {
+		return result, nil, err
+	}
+	if exists {
+		return result, nil, fmt.Errorf("the key is already in the registry at %s, there is nothing to submit", location)
+	}
+
+	// The key data directory is at the root of the registry repository
+	file := location
+	if rel, err := filepath.Rel(filepath.Dir(opts.KeyDataDir), location); err == nil {
+		file = filepath.ToSlash(rel)
+	}
+	target := opts.Org
+	if providerName != "" {
+		target += "/" + providerName
+	}
+	sub := &keySubmission{
+		File:          file,
+		Namespace:     opts.Org,
+		ProviderName:  providerName,
+		Fingerprint:   strings.ToUpper(key.GetFingerprint()),
+		Outcome:       result.Outcome(),
+		Branch:        "provider-key-submission_" + opts.Org,
+		CommitMessage: fmt.Sprintf("Create provider key %s\n\nSubmitted-by: %s\n", target, opts.Username),
+		Title:         "Provider Key: " + target,
+	}
+	sub.Body = fmt.Sprintf("Created %s for provider %s.\n\nFingerprint: `%s`\n\n%s", file, target, sub.Fingerprint, result.RenderMarkdownProblemsOnly())
+	return result, sub, nil
+}
+
+// writeSubmissionInstructions writes the git commands that commit the submitted key on its own branch.
+func writeSubmissionInstructions(w io.Writer, sub *keySubmission) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nAdded %s, submit it with:\n\n", sub.File)
+	fmt.Fprintf(&b, "  git checkout -b %s\n", sub.Branch)
+	fmt.Fprintf(&b, "  git add %s\n", sub.File)
+	b.WriteString("  git commit -s")
+	for _, paragraph := range strings.Split(strings.TrimSpace(sub.CommitMessage), "\n\n") {
+		fmt.Fprintf(&b, " -m %s", strconv.Quote(paragraph))
+	}
+	fmt.Fprintf(&b, "\n\nThen open a pull request titled %q.\n", sub.Title)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
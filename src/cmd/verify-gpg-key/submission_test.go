@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/pkg/verification"
+)
+
+func TestValidateSubmission(t *testing.T) {
+	assert.NoError(t, validateSubmission("key.asc", "example", "", "user"))
+	assert.NoError(t, validateSubmission("key.asc", "example", "aws", "user"))
+
+	err := validateSubmission("", "Example!", "terraform-provider-aws", "")
+	assert.EqualError(t, err, strings.Join([]string{
+		"-key-file is required",
+		"-username is required",
+		`invalid namespace: "Example!"`,
+		`the provider name should not include the 'terraform-provider-' prefix: "terraform-provider-aws"`,
+	}, "\n"))
+}
+
+func TestPrepareSubmission(t *testing.T) {
+	dir := t.TempDir()
+	keyDataDir := filepath.Join(dir, "keys")
+	keyFile := filepath.Join(dir, "submitted.asc")
+	key := writeAuditKey(t, keyFile, "Test User", "test@example.com")
+	now := time.Unix(1700000000, 0)
+
+	opts := verification.VerifyKeyOptions{KeyFile: keyFile, Username: "user", Org: "example", KeyDataDir: keyDataDir, Local: true}
+	result, sub, err := prepareSubmission(context.Background(), opts, "aws", now)
+	assert.NoError(t, err)
+	assert.False(t, result.DidFail())
+	if assert.NotNil(t, sub) {
+		assert.Equal(t, "keys/e/example/aws/provider-1700000000.asc", sub.File)
+		assert.FileExists(t, filepath.Join(keyDataDir, "e/example/aws/provider-1700000000.asc"))
+		assert.Equal(t, strings.ToUpper(key.GetFingerprint()), sub.Fingerprint)
+		assert.Equal(t, "provider-key-submission_example", sub.Branch)
+		assert.Equal(t, "Create provider key example/aws\n\nSubmitted-by: user\n", sub.CommitMessage)
+		assert.Equal(t, "Provider Key: example/aws", sub.Title)
+		assert.True(t, strings.HasPrefix(sub.Body, "Created keys/e/example/aws/provider-1700000000.asc for provider example/aws.\n\nFingerprint: `"+sub.Fingerprint+"`\n\n"))
+
+		var buf bytes.Buffer
+		assert.NoError(t, writeSubmissionInstructions(&buf, sub))
+		assert.Contains(t, buf.String(), "  git checkout -b provider-key-submission_example\n  git add keys/e/example/aws/provider-1700000000.asc\n  git commit -s -m \"Create provider key example/aws\" -m \"Submitted-by: user\"\n")
+	}
+
+	// Submitting the same key again
+	_, sub, err = prepareSubmission(context.Background(), opts, "aws", now.Add(time.Hour))
+	assert.ErrorContains(t, err, "the key is already in the registry")
+	assert.Nil(t, sub)
+}
+
+func TestPrepareSubmission_Failed(t *testing.T) {
+	dir := t.TempDir()
+	keyDataDir := filepath.Join(dir, "keys")
+	keyFile := filepath.Join(dir, "submitted.asc")
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := verification.VerifyKeyOptions{KeyFile: keyFile, Username: "user", Org: "example", KeyDataDir: keyDataDir, Local: true}
+	result, sub, err := prepareSubmission(context.Background(), opts, "", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, result.DidFail())
+	assert.Nil(t, sub)
+	assert.NoDirExists(t, keyDataDir)
+}
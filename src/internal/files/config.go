@@ -0,0 +1,57 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadConfig reads a JSON object mapping option names to values from the given file. Strings, numbers and booleans
+// are returned in their textual form, as if they were passed on the command line. Arrays of strings are joined with
+// commas, for options that take a comma separated list.
+func ReadConfig(filePath string) (map[string]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", filePath, err)
+	}
+
+	config := make(map[string]string, len(raw))
+	for name, value := range raw {
+		text, err := configValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid value for %q: %w", filePath, name, err)
+		}
+		config[name] = text
+	}
+	return config, nil
+}
+
+func configValue(value json.RawMessage) (string, error) {
+	value = bytes.TrimSpace(value)
+	switch {
+	case len(value) == 0 || bytes.Equal(value, []byte("null")):
+		return "", fmt.Errorf("a value is required")
+	case value[0] == '"':
+		var s string
+		err := json.Unmarshal(value, &s)
+		return s, err
+	case value[0] == '[':
+		var items []string
+		if err := json.Unmarshal(value, &items); err != nil {
+			return "", fmt.Errorf("only lists of strings are supported")
+		}
+		return strings.Join(items, ","), nil
+	case value[0] == '{':
+		return "", fmt.Errorf("objects are not supported")
+	default:
+		// Numbers and booleans
+		return string(value), nil
+	}
+}
@@ -0,0 +1,77 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiles_ReadConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "all value types",
+			contents: `{"org": "example", "strict": true, "max-validity-years": 5, "provider-orgs": ["first", "second"], "download-timeout": "1m"}`,
+			expected: map[string]string{
+				"org":                "example",
+				"strict":             "true",
+				"max-validity-years": "5",
+				"provider-orgs":      "first,second",
+				"download-timeout":   "1m",
+			},
+		},
+		{
+			name:     "empty",
+			contents: `{}`,
+			expected: map[string]string{},
+		},
+		{
+			name:     "not an object",
+			contents: `["org"]`,
+			err:      "failed to parse config",
+		},
+		{
+			name:     "nested object",
+			contents: `{"org": {"name": "example"}}`,
+			err:      `invalid value for "org": objects are not supported`,
+		},
+		{
+			name:     "null value",
+			contents: `{"org": null}`,
+			err:      `invalid value for "org": a value is required`,
+		},
+		{
+			name:     "list of numbers",
+			contents: `{"only": [1, 2]}`,
+			err:      `invalid value for "only": only lists of strings are supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.contents), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := ReadConfig(path)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, config)
+		})
+	}
+}
+
+func TestFiles_ReadConfig_Missing(t *testing.T) {
+	_, err := ReadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read config")
+}
@@ -0,0 +1,38 @@
+package files
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadDenylist reads a list of key fingerprints from the given file, one per line. Everything after a "#" is a
+// comment and blank lines are ignored. Spaces within a fingerprint are allowed, so that the output of
+// "gpg --fingerprint" can be pasted as is. The fingerprints are returned in upper case.
+func ReadDenylist(filePath string) (map[string]bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open denylist: %w", err)
+	}
+	defer file.Close()
+
+	fingerprints := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fingerprint := strings.ToUpper(strings.Join(strings.Fields(text), ""))
+		if fingerprint == "" {
+			continue
+		}
+		if _, err := hex.DecodeString(fingerprint); err != nil || (len(fingerprint) != 40 && len(fingerprint) != 64) {
+			return nil, fmt.Errorf("%s:%d: %q is not a key fingerprint", filePath, line, fingerprint)
+		}
+		fingerprints[fingerprint] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read denylist: %w", err)
+	}
+	return fingerprints, nil
+}
@@ -0,0 +1,70 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiles_ReadDenylist(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected map[string]bool
+		err      string
+	}{
+		{
+			name: "fingerprints and comments",
+			contents: `# Leaked on 2024-01-01
+a8b6d5e1f0c2b3a49d7e8f6c5b4a39281706f5e4
+
+4828 A1B8 9A8A CB20 54E8  D970 CEBC 23EE DF7A 0DCE # pasted from gpg --fingerprint
+CB186C4F0609A697E4D52DFA6C722B0C1F1E27C18A56708F6525EC27BAD9ACC9
+`,
+			expected: map[string]bool{
+				"A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5E4":                         true,
+				"4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE":                         true,
+				"CB186C4F0609A697E4D52DFA6C722B0C1F1E27C18A56708F6525EC27BAD9ACC9": true,
+			},
+		},
+		{
+			name:     "empty",
+			contents: "# Nothing here yet\n",
+			expected: map[string]bool{},
+		},
+		{
+			name:     "key ID instead of fingerprint",
+			contents: "# Comment\nCEBC23EEDF7A0DCE\n",
+			err:      `denylist.txt:2: "CEBC23EEDF7A0DCE" is not a key fingerprint`,
+		},
+		{
+			name:     "not hex",
+			contents: "4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCX\n",
+			err:      "is not a key fingerprint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "denylist.txt")
+			if err := os.WriteFile(path, []byte(tt.contents), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			fingerprints, err := ReadDenylist(path)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, fingerprints)
+		})
+	}
+}
+
+func TestFiles_ReadDenylist_Missing(t *testing.T) {
+	_, err := ReadDenylist(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.ErrorContains(t, err, "failed to open denylist")
+}
@@ -1,8 +1,11 @@
 package files
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
 )
@@ -15,12 +18,18 @@ func SafeWriteObjectToJSONFile(filePath string, data any) error {
 		return fmt.Errorf("failed to marshal for %s: %w", filePath, err)
 	}
 
-	err = os.MkdirAll(path.Dir(filePath), 0755) //nolint: gomnd // 0755 is the default for os.MkdirAll
+	return SafeWriteFile(filePath, marshalledJSON)
+}
+
+// SafeWriteFile writes the given contents to the given file path.
+// It also ensures that the destination directory exists and that the file is written correctly.
+func SafeWriteFile(filePath string, contents []byte) error {
+	err := os.MkdirAll(path.Dir(filePath), 0755) //nolint: gomnd // 0755 is the default for os.MkdirAll
 	if err != nil {
 		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
 	}
 
-	err = os.WriteFile(filePath, marshalledJSON, 0600) //nolint: gomnd // 0600 is fine for what we need, no other users should consume this
+	err = os.WriteFile(filePath, contents, 0600) //nolint: gomnd // 0600 is fine for what we need, no other users should consume this
 	if err != nil {
 		// Error already contains filePath so we don't need to add it again
 		return fmt.Errorf("failed to write to file: %w", err)
@@ -28,3 +37,97 @@ func SafeWriteObjectToJSONFile(filePath string, data any) error {
 
 	return nil
 }
+
+// SafeWriteFileFunc creates the file at the given path and passes it to write, so that large contents can be streamed
+// to it. Like SafeWriteFile, it ensures that the destination directory exists.
+func SafeWriteFileFunc(filePath string, write func(w io.Writer) error) error {
+	err := os.MkdirAll(path.Dir(filePath), 0755) //nolint: gomnd // 0755 is the default for os.MkdirAll
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600) //nolint: gomnd // Same as SafeWriteFile
+	if err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+
+	buffered := bufio.NewWriter(file)
+	err = write(buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// ErrLocked is returned by LockedWriteFileFunc if another process is writing the same file.
+var ErrLocked = errors.New("the file is being written by another process")
+
+// LockedWriteFileFunc is SafeWriteFileFunc for output paths that several processes might write to by mistake. It holds
+// an advisory lock on filePath+".lock" while writing and fails with ErrLocked instead of waiting if another process
+// holds it, as both writing the same file is a misconfiguration. The contents are written to a temporary file that is
+// renamed into place, so the file never holds partial output. The lock file is left behind, removing it would let a
+// waiting writer lock a file that is no longer the one at the path.
+func LockedWriteFileFunc(filePath string, write func(w io.Writer) error) error {
+	dir := path.Dir(filePath)
+	err := os.MkdirAll(dir, 0755) //nolint: gomnd // 0755 is the default for os.MkdirAll
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+	}
+
+	lock, err := os.OpenFile(filePath+".lock", os.O_WRONLY|os.O_CREATE, 0600) //nolint: gomnd // Same as SafeWriteFile
+	if err != nil {
+		return fmt.Errorf("failed to create lock file for %s: %w", filePath, err)
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", filePath, err)
+	}
+	defer unlockFile(lock) //nolint: errcheck // Closing the file releases the lock as well
+
+	return writeRenamed(filePath, write)
+}
+
+// AtomicWriteFile writes the given contents to the given file path like SafeWriteFile, but writes them to a temporary
+// file in the same directory that is renamed into place. Concurrent readers see either the previous or the new
+// contents, never partial ones, even if the write is interrupted.
+func AtomicWriteFile(filePath string, contents []byte) error {
+	err := os.MkdirAll(path.Dir(filePath), 0755) //nolint: gomnd // 0755 is the default for os.MkdirAll
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+	}
+	return writeRenamed(filePath, func(w io.Writer) error {
+		_, err := w.Write(contents)
+		return err
+	})
+}
+
+// writeRenamed writes to a temporary file next to filePath and renames it into place, the directory must exist.
+func writeRenamed(filePath string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(path.Dir(filePath), path.Base(filePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint: errcheck // Fails once the file has been renamed
+
+	buffered := bufio.NewWriter(tmp)
+	err = write(buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package files
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -68,3 +70,120 @@ func TestFiles_SafeWriteObjectToJsonFile_InvalidPath2(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "is a directory")
 }
+
+func TestFiles_SafeWriteFile_Success(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "subdir", "file.txt")
+
+	err := SafeWriteFile(path, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "hello", string(raw))
+}
+
+func TestFiles_AtomicWriteFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "subdir")
+	path := filepath.Join(dir, "file.txt")
+
+	assert.NoError(t, AtomicWriteFile(path, []byte("hello")))
+	assert.NoError(t, AtomicWriteFile(path, []byte("bye")))
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "bye", string(raw))
+
+	// The temporary files are renamed into place
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1)
+}
+
+func TestFiles_SafeWriteFileFunc_Success(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "subdir", "file.txt")
+
+	err := SafeWriteFileFunc(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "hello")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "hello", string(raw))
+}
+
+func TestFiles_SafeWriteFileFunc_WriteError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+
+	err := SafeWriteFileFunc(path, func(w io.Writer) error {
+		return errors.New("render failed")
+	})
+	assert.ErrorContains(t, err, "render failed")
+}
+
+func TestFiles_LockedWriteFileFunc(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "subdir", "file.txt")
+	writeString := func(contents string) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, contents)
+			return err
+		}
+	}
+
+	assert.NoError(t, LockedWriteFileFunc(path, writeString("hello")))
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "hello", string(raw))
+
+	// A failed write leaves the previous contents in place and no temporary file behind
+	err = LockedWriteFileFunc(path, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return errors.New("render failed")
+	})
+	assert.ErrorContains(t, err, "render failed")
+	raw, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "hello", string(raw))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 2) // file.txt and file.txt.lock
+
+	// Another writer holds the lock
+	lock, err := os.OpenFile(path+".lock", os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		t.Skipf("file locking is unavailable: %v", err)
+	}
+	err = LockedWriteFileFunc(path, writeString("other"))
+	assert.ErrorIs(t, err, ErrLocked)
+
+	assert.NoError(t, unlockFile(lock))
+	assert.NoError(t, LockedWriteFileFunc(path, writeString("other")))
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package files
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile reports that locking is unavailable, advisory locks are only implemented for unix systems.
+func lockFile(_ *os.File) error {
+	return errors.New("file locking is not supported on this platform")
+}
+
+func unlockFile(_ *os.File) error {
+	return nil
+}
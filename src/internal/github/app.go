@@ -0,0 +1,164 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenSource provides the token used to authenticate requests against GitHub.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token, such as a personal access token.
+type StaticToken string
+
+// Token returns the static token.
+func (t StaticToken) Token() (string, error) {
+	return string(t), nil
+}
+
+// AppCredentials identify a GitHub App installation that is used to mint installation access tokens.
+type AppCredentials struct {
+	AppID          int64  // The ID of the GitHub App
+	InstallationID int64  // The ID of the App's installation in the organization
+	PrivateKey     []byte // The PEM encoded private key of the GitHub App
+}
+
+const (
+	appTokenURL = "https://api.github.com/app/installations/%d/access_tokens"
+	// appJWTLifetime is the lifetime of the JWT used to request installation tokens, GitHub allows at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appTokenRefreshMargin is how long before expiry an installation token is replaced.
+	appTokenRefreshMargin = 5 * time.Minute
+)
+
+// appTokenSource mints GitHub App installation access tokens and caches them until they are about to expire.
+type appTokenSource struct {
+	ctx        context.Context
+	creds      AppCredentials
+	key        *rsa.PrivateKey
+	tokenURL   string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newAppTokenSource(ctx context.Context, creds AppCredentials) (*appTokenSource, error) {
+	key, err := parseAppPrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &appTokenSource{
+		ctx:        ctx,
+		creds:      creds,
+		key:        key,
+		tokenURL:   fmt.Sprintf(appTokenURL, creds.InstallationID),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Token returns a valid installation access token, minting a new one if required.
+func (s *appTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(appTokenRefreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	jwt, err := appJWT(s.creds.AppID, s.key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build installation token request: %w", err)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("unexpected status code %d when requesting installation token for installation %d", resp.StatusCode, s.creds.InstallationID)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode installation token response: %w", err)
+	}
+
+	s.token = body.Token
+	s.expires = body.ExpiresAt
+	return s.token, nil
+}
+
+func parseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// appJWT builds the RS256 signed JWT that authenticates as the GitHub App itself.
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		// Backdate the token to allow for clock drift, as recommended by GitHub
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, encoded
+}
+
+func TestAppJWT(t *testing.T) {
+	key, _ := generateAppKey(t)
+	now := time.Unix(1700000000, 0)
+
+	jwt, err := appJWT(1234, key, now)
+	assert.NoError(t, err)
+
+	parts := strings.Split(jwt, ".")
+	assert.Len(t, parts, 3)
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.NoError(t, err)
+	var claims map[string]int64
+	assert.NoError(t, json.Unmarshal(rawClaims, &claims))
+	assert.Equal(t, int64(1234), claims["iss"])
+	assert.Equal(t, now.Add(-time.Minute).Unix(), claims["iat"])
+	assert.Equal(t, now.Add(appJWTLifetime).Unix(), claims["exp"])
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestAppTokenSource(t *testing.T) {
+	_, encoded := generateAppKey(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      "installation-token",
+			"expires_at": time.Now().Add(time.Hour),
+		})
+	}))
+	defer server.Close()
+
+	source, err := newAppTokenSource(context.Background(), AppCredentials{AppID: 1, InstallationID: 2, PrivateKey: encoded})
+	assert.NoError(t, err)
+	source.tokenURL = server.URL
+
+	for i := 0; i < 3; i++ {
+		token, err := source.Token()
+		assert.NoError(t, err)
+		assert.Equal(t, "installation-token", token)
+	}
+	// The token is cached until it is about to expire
+	assert.Equal(t, 1, requests)
+}
+
+func TestAppTokenSource_InvalidKey(t *testing.T) {
+	_, err := newAppTokenSource(context.Background(), AppCredentials{PrivateKey: []byte("not a key")})
+	assert.ErrorContains(t, err, "not PEM encoded")
+}
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/registry-stable/internal/files"
+)
+
+// assetCache stores downloaded release assets on disk, keyed by the hash of their URL, so that repeated runs can
+// reuse them. Entries are revalidated with their ETag or Last-Modified date once their Cache-Control max-age has
+// passed. Entries are written to temporary files that are renamed into place, the body before its metadata, and a body
+// that does not match the hash in its metadata is ignored, so that concurrent workers or an interrupted run never use
+// a partial asset.
+type assetCache struct {
+	dir string
+}
+
+// cachedAsset holds the validators and freshness of a cached asset.
+type cachedAsset struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FreshUntil   time.Time `json:"fresh_until"`
+	Size         int       `json:"size"`
+	SHA256       string    `json:"sha256"`
+}
+
+func (c assetCache) paths(url string) (meta string, body string) {
+	sum := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name+".json"), filepath.Join(c.dir, name)
+}
+
+// load returns the cached asset for the URL, or nil if there is none or its body does not match its metadata.
+func (c assetCache) load(url string) (*cachedAsset, []byte) {
+	metaPath, bodyPath := c.paths(url)
+
+	metaData, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var meta cachedAsset
+	if err := json.Unmarshal(metaData, &meta); err != nil || meta.URL != url {
+		return nil, nil
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil || len(body) != meta.Size || bodyHash(body) != meta.SHA256 {
+		return nil, nil
+	}
+	return &meta, body
+}
+
+// store caches the asset unless the response forbids it.
+func (c assetCache) store(url string, header http.Header, body []byte, now time.Time) error {
+	cacheControl := strings.ToLower(header.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") {
+		return nil
+	}
+
+	meta := cachedAsset{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		FreshUntil:   now.Add(maxAge(cacheControl)),
+		Size:         len(body),
+		SHA256:       bodyHash(body),
+	}
+	_, bodyPath := c.paths(url)
+	if err := files.AtomicWriteFile(bodyPath, body); err != nil {
+		return fmt.Errorf("failed to cache asset %s: %w", url, err)
+	}
+	return c.storeMeta(meta)
+}
+
+// refresh updates the freshness of a cached asset after the server confirmed it is unchanged, along with the
+// validators the response carries. Validators the response leaves out are kept.
+func (c assetCache) refresh(meta cachedAsset, header http.Header, now time.Time) error {
+	cacheControl := strings.ToLower(header.Get("Cache-Control"))
+	if strings.Contains(cacheControl, "no-store") {
+		return nil
+	}
+
+	meta.FreshUntil = now.Add(maxAge(cacheControl))
+	if etag := header.Get("ETag"); etag != "" {
+		meta.ETag = etag
+	}
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		meta.LastModified = lastModified
+	}
+	return c.storeMeta(meta)
+}
+
+func (c assetCache) storeMeta(meta cachedAsset) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to cache asset %s: %w", meta.URL, err)
+	}
+	metaPath, _ := c.paths(meta.URL)
+	if err := files.AtomicWriteFile(metaPath, data); err != nil {
+		return fmt.Errorf("failed to cache asset %s: %w", meta.URL, err)
+	}
+	return nil
+}
+
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// maxAge returns the max-age of a Cache-Control header, or zero if the response must always be revalidated.
+func maxAge(cacheControl string) time.Duration {
+	if strings.Contains(cacheControl, "no-cache") {
+		return 0
+	}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(directive), "max-age=")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadAssetContents_Cache(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		requests     int // Requests reaching the server for three downloads
+		revalidated  int // Requests answered with 304 Not Modified
+	}{
+		{name: "revalidate with etag", cacheControl: "no-cache", requests: 3, revalidated: 2},
+		{name: "fresh for max-age", cacheControl: "public, max-age=3600", requests: 1, revalidated: 0},
+		{name: "no-store", cacheControl: "no-store", requests: 3, revalidated: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, revalidated := 0, 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Cache-Control", tt.cacheControl)
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					revalidated++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = w.Write([]byte("checksums"))
+			}))
+			defer server.Close()
+
+			ctx := context.Background()
+			client := Client{
+				ctx:           ctx,
+				log:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+				httpClient:    server.Client(),
+				assetThrottle: NewThrottle(ctx, time.Millisecond, 1),
+			}.WithAssetCache(t.TempDir())
+
+			for i := 0; i < 3; i++ {
+				contents, err := client.DownloadAssetContents(server.URL + "/SHA256SUMS")
+				assert.NoError(t, err)
+				assert.Equal(t, "checksums", string(contents))
+			}
+			assert.Equal(t, tt.requests, requests)
+			assert.Equal(t, tt.revalidated, revalidated)
+		})
+	}
+}
+
+func TestDownloadAssetContents_CacheKeepsValidators(t *testing.T) {
+	requests, revalidated := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			// A 304 without validators must not erase the ones of the cached asset
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("checksums"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := Client{
+		ctx:           ctx,
+		log:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+		httpClient:    server.Client(),
+		assetThrottle: NewThrottle(ctx, time.Millisecond, 1),
+	}.WithAssetCache(t.TempDir())
+
+	for i := 0; i < 3; i++ {
+		contents, err := client.DownloadAssetContents(server.URL + "/SHA256SUMS")
+		assert.NoError(t, err)
+		assert.Equal(t, "checksums", string(contents))
+	}
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 2, revalidated)
+}
+
+func TestAssetCache_PartialBody(t *testing.T) {
+	cache := assetCache{dir: t.TempDir()}
+	url := "https://example.com/SHA256SUMS"
+	header := http.Header{"Cache-Control": []string{"max-age=3600"}}
+	if err := cache.store(url, header, []byte("checksums"), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	meta, body := cache.load(url)
+	if assert.NotNil(t, meta) {
+		assert.Equal(t, "checksums", string(body))
+	}
+
+	// A body that does not match its metadata, as left by an interrupted write, is not used
+	_, bodyPath := cache.paths(url)
+	if err := os.WriteFile(bodyPath, []byte("check"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	meta, body = cache.load(url)
+	assert.Nil(t, meta)
+	assert.Nil(t, body)
+}
+
+func TestMaxAge(t *testing.T) {
+	assert.Equal(t, time.Hour, maxAge("public, max-age=3600"))
+	assert.Equal(t, time.Duration(0), maxAge("max-age=3600, no-cache"))
+	assert.Equal(t, time.Duration(0), maxAge("private"))
+	assert.Equal(t, time.Duration(0), maxAge("max-age=abc"))
+}
@@ -1,26 +1,95 @@
 package github
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
+// WithAssetCache returns a new Client that caches downloaded assets in the given directory.
+func (c Client) WithAssetCache(dir string) Client {
+	c.assetCache = &assetCache{dir: dir}
+	return c
+}
+
+// WithAssetTimeout returns a new Client that gives up on a single asset download, including reading its contents, after
+// the given duration. A zero duration disables the timeout.
+func (c Client) WithAssetTimeout(timeout time.Duration) Client {
+	c.assetTimeout = timeout
+	return c
+}
+
 // DownloadAssetContents downloads the contents of the asset at the given URL and returns it directly
 func (c Client) DownloadAssetContents(downloadURL string) ([]byte, error) {
+	return c.DownloadAssetContentsContext(c.ctx, downloadURL)
+}
+
+// DownloadAssetContentsContext downloads the asset like DownloadAssetContents, but gives up once ctx is done as well as
+// once the context of the client is.
+func (c Client) DownloadAssetContentsContext(ctx context.Context, downloadURL string) ([]byte, error) {
+	logger := c.log.With(slog.String("url", downloadURL))
+
+	var cached *cachedAsset
+	var cachedBody []byte
+	if c.assetCache != nil {
+		cached, cachedBody = c.assetCache.load(downloadURL)
+		if cached != nil && time.Now().Before(cached.FreshUntil) {
+			logger.Debug("Using cached asset")
+			return cachedBody, nil
+		}
+	}
+
 	done := c.assetThrottle()
 	defer done()
 
-	logger := c.log.With(slog.String("url", downloadURL))
 	logger.Info("Downloading asset")
 
-	resp, err := c.httpClient.Get(downloadURL)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.ctx != nil && c.ctx != ctx {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(c.ctx, cancel)
+		defer stop()
+	}
+	if c.assetTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.assetTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading asset %s: %w", downloadURL, err)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error downloading asset %s: %w", downloadURL, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		logger.Info("cached asset is still valid")
+		if err := c.assetCache.refresh(*cached, resp.Header, time.Now()); err != nil {
+			logger.Warn("failed to refresh cached asset", slog.Any("err", err))
+		}
+		return cachedBody, nil
+	}
+
 	if resp.StatusCode == http.StatusNotFound {
 		logger.Warn("asset not found")
 		return nil, nil
@@ -37,5 +106,11 @@ func (c Client) DownloadAssetContents(downloadURL string) ([]byte, error) {
 
 	logger.Info("asset successfully downloaded", slog.Int("size", len(contents)))
 
+	if c.assetCache != nil {
+		if err := c.assetCache.store(downloadURL, resp.Header, contents, time.Now()); err != nil {
+			logger.Warn("failed to cache asset", slog.Any("err", err))
+		}
+	}
+
 	return contents, nil
 }
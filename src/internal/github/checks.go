@@ -0,0 +1,148 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// apiURL is the base URL of the GitHub REST API.
+var apiURL = "https://api.github.com"
+
+// maxCheckRunAnnotations is the maximum number of annotations GitHub accepts in a single check run request.
+const maxCheckRunAnnotations = 50
+
+// CheckRun is a completed check run to report on a commit.
+type CheckRun struct {
+	Name        string               // Name of the check run, used to find an existing run to update
+	HeadSHA     string               // Commit the check run belongs to
+	Conclusion  string               // One of success, failure, neutral, cancelled, skipped, timed_out or action_required
+	Title       string               // Title of the check run output
+	Summary     string               // Markdown summary of the check run output
+	Text        string               // Markdown details of the check run output
+	Annotations []CheckRunAnnotation // Annotations on files in the repository
+}
+
+// CheckRunAnnotation marks a line range of a file in the repository.
+type CheckRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"` // One of notice, warning or failure
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+type checkRunRequest struct {
+	Name       string         `json:"name"`
+	HeadSHA    string         `json:"head_sha,omitempty"`
+	Status     string         `json:"status"`
+	Conclusion string         `json:"conclusion"`
+	Output     checkRunOutput `json:"output"`
+}
+
+type checkRunOutput struct {
+	Title       string               `json:"title"`
+	Summary     string               `json:"summary"`
+	Text        string               `json:"text,omitempty"`
+	Annotations []CheckRunAnnotation `json:"annotations,omitempty"`
+}
+
+// UpsertCheckRun reports the check run on the given repository ("owner/name"). An existing check run with the same
+// name on the same commit is updated instead of creating a second one. Creating check runs requires authenticating as
+// a GitHub App. Only the first 50 annotations are sent.
+func (c Client) UpsertCheckRun(repository string, run CheckRun) error {
+	done := c.apiThrottle()
+	defer done()
+
+	logger := c.log.With(slog.String("repository", repository), slog.String("sha", run.HeadSHA))
+
+	id, err := c.findCheckRun(repository, run.HeadSHA, run.Name)
+	if err != nil {
+		return err
+	}
+
+	annotations := run.Annotations
+	if len(annotations) > maxCheckRunAnnotations {
+		logger.Warn("Too many annotations, only sending the first ones", slog.Int("annotations", len(annotations)))
+		annotations = annotations[:maxCheckRunAnnotations]
+	}
+	body := checkRunRequest{
+		Name:       run.Name,
+		Status:     "completed",
+		Conclusion: run.Conclusion,
+		Output: checkRunOutput{
+			Title:       run.Title,
+			Summary:     run.Summary,
+			Text:        run.Text,
+			Annotations: annotations,
+		},
+	}
+
+	method, target := http.MethodPost, fmt.Sprintf("%s/repos/%s/check-runs", apiURL, repository)
+	expected := http.StatusCreated
+	if id != 0 {
+		method, target = http.MethodPatch, fmt.Sprintf("%s/repos/%s/check-runs/%d", apiURL, repository, id)
+		expected = http.StatusOK
+	} else {
+		body.HeadSHA = run.HeadSHA
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode check run: %w", err)
+	}
+	req, err := http.NewRequest(method, target, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to create check run request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to report check run: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != expected {
+		return fmt.Errorf("unexpected status code %d when reporting check run %q on %s", resp.StatusCode, run.Name, repository)
+	}
+
+	logger.Info("Check run reported", slog.String("name", run.Name), slog.String("conclusion", run.Conclusion))
+	return nil
+}
+
+// findCheckRun returns the ID of the check run with the given name on the commit, or zero if there is none.
+func (c Client) findCheckRun(repository string, sha string, name string) (int64, error) {
+	target := fmt.Sprintf("%s/repos/%s/commits/%s/check-runs?check_name=%s", apiURL, repository, sha, url.QueryEscape(name))
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create check run request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list check runs: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d when listing check runs of %s on %s", resp.StatusCode, sha, repository)
+	}
+
+	var list struct {
+		CheckRuns []struct {
+			ID int64 `json:"id"`
+		} `json:"check_runs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return 0, fmt.Errorf("failed to decode check runs: %w", err)
+	}
+	if len(list.CheckRuns) == 0 {
+		return 0, nil
+	}
+	return list.CheckRuns[0].ID, nil
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsertCheckRun(t *testing.T) {
+	var existing []map[string]any
+	var requests []string
+	var created checkRunRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "GPG key verification", r.URL.Query().Get("check_name"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"check_runs": existing})
+		case http.MethodPost:
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	original := apiURL
+	apiURL = server.URL
+	t.Cleanup(func() { apiURL = original })
+
+	ctx := context.Background()
+	client := Client{
+		ctx:         ctx,
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		httpClient:  server.Client(),
+		apiThrottle: NewThrottle(ctx, time.Millisecond, 1),
+	}
+
+	annotations := make([]CheckRunAnnotation, 60)
+	run := CheckRun{Name: "GPG key verification", HeadSHA: "abc123", Conclusion: "failure", Title: "Failed", Annotations: annotations}
+
+	err := client.UpsertCheckRun("opentofu/registry", run)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", created.HeadSHA)
+	assert.Equal(t, "completed", created.Status)
+	assert.Len(t, created.Output.Annotations, maxCheckRunAnnotations)
+
+	existing = []map[string]any{{"id": 42}}
+	err = client.UpsertCheckRun("opentofu/registry", run)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"GET /repos/opentofu/registry/commits/abc123/check-runs",
+		"POST /repos/opentofu/registry/check-runs",
+		"GET /repos/opentofu/registry/commits/abc123/check-runs",
+		"PATCH /repos/opentofu/registry/check-runs/42",
+	}, requests)
+}
@@ -2,11 +2,14 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/shurcooL/githubv4"
@@ -14,6 +17,13 @@ import (
 
 const UserAgent = "OpenTofu Registry/1.0"
 
+// IsUnreachable reports whether err means that GitHub could not be reached, such as a failed connection, a timeout or
+// an exhausted retry budget, as opposed to GitHub answering the request.
+func IsUnreachable(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, ErrRetryBudgetExhausted)
+}
+
 // EnvAuthToken returns the GitHub token from the environment.
 func EnvAuthToken() (string, error) {
 	token := os.Getenv("GH_TOKEN")
@@ -23,6 +33,11 @@ func EnvAuthToken() (string, error) {
 	return token, nil
 }
 
+// InActions reports whether the process runs in a GitHub Actions workflow.
+func InActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
 // Client is a GitHub client that abstracts away the different GitHub APIs and handles rate limiting/throttling.
 type Client struct {
 	ctx        context.Context
@@ -34,14 +49,32 @@ type Client struct {
 	apiThrottle   Throttle
 	assetThrottle Throttle
 	rssThrottle   Throttle
+
+	assetCache   *assetCache   // Optional on-disk cache for DownloadAssetContents
+	assetTimeout time.Duration // Maximum duration of a single asset download, no limit if zero
 }
 
 // NewClient creates a new GitHub client.
 func NewClient(ctx context.Context, log *slog.Logger, token string) Client {
-	httpClient := &http.Client{Transport: &transport{token: token, ctx: ctx}}
+	return newClient(ctx, log, StaticToken(token))
+}
+
+// NewAppClient creates a new GitHub client that authenticates as a GitHub App installation.
+// Installation access tokens are minted on demand and refreshed before they expire.
+func NewAppClient(ctx context.Context, log *slog.Logger, creds AppCredentials) (Client, error) {
+	tokens, err := newAppTokenSource(ctx, creds)
+	if err != nil {
+		return Client{}, err
+	}
+	return newClient(ctx, log, tokens), nil
+}
+
+func newClient(ctx context.Context, log *slog.Logger, tokens TokenSource) Client {
+	log = log.WithGroup("github")
+	httpClient := &http.Client{Transport: &transport{tokens: tokens, ctx: ctx, limits: newRateLimit(log)}}
 	return Client{
 		ctx:        ctx,
-		log:        log.WithGroup("github"),
+		log:        log,
 		httpClient: httpClient,
 		ghClient:   githubv4.NewClient(httpClient),
 
@@ -70,22 +103,51 @@ func (c Client) WithLogger(log *slog.Logger) Client {
 		apiThrottle:   c.apiThrottle,
 		assetThrottle: c.assetThrottle,
 		rssThrottle:   c.rssThrottle,
+
+		assetCache:   c.assetCache,
+		assetTimeout: c.assetTimeout,
 	}
 }
 
+// RequestCount returns the number of requests sent to GitHub by this client and all clients derived from it, including
+// retries and asset downloads.
+func (c Client) RequestCount() int64 {
+	if c.httpClient == nil {
+		return 0
+	}
+	if t, ok := c.httpClient.Transport.(*transport); ok {
+		return t.requests.Load()
+	}
+	return 0
+}
+
 // transport is a http.RoundTripper that makes sure all requests have the
 // correct User-Agent and Authorization headers set.
 type transport struct {
-	token  string
-	ctx    context.Context
-	parent http.Transport
+	tokens   TokenSource
+	ctx      context.Context
+	limits   *rateLimit
+	requests atomic.Int64                // Number of requests sent, including retries
+	budget   atomic.Pointer[retryBudget] // Limits the retries of the run, unlimited if nil
+	parent   http.Transport
 }
 
 // RoundTrip is needed to implement the http.RoundTripper interface.
 func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req = req.WithContext(t.ctx)
+	token, err := t.tokens.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain GitHub token: %w", err)
+	}
+
+	// Requests are bound to the client's context, unless the caller derived a shorter lived one from it
+	if req.Context() == context.Background() {
+		req = req.WithContext(t.ctx)
+	}
 	req.Header.Set("User-Agent", UserAgent)
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if tr, ok := traceFromContext(req.Context()); ok {
+		req.Header.Set(tr.header, tr.id)
+	}
 
 	parent := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -100,5 +162,41 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
-	return parent.RoundTrip(req)
+	if err := t.limits.wait(t.ctx); err != nil {
+		return nil, err
+	}
+
+	t.requests.Add(1)
+	resp, err := parent.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// When the rate limit was hit, wait for it to reset and retry the request once, provided it can be replayed.
+	if t.limits.update(resp) && (req.Body == nil || req.GetBody != nil) {
+		resp.Body.Close()
+
+		if budget := t.budget.Load(); budget != nil && !budget.take() {
+			return nil, fmt.Errorf("%w: all %d retries of this run have been used, GitHub keeps rejecting requests", ErrRetryBudgetExhausted, budget.max)
+		}
+
+		if err := t.limits.wait(t.ctx); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		t.requests.Add(1)
+		resp, err = parent.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.limits.update(resp)
+	}
+
+	return resp, nil
 }
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RequestCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("contents"))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewClient(context.Background(), logger, "token")
+	derived := client.WithLogger(logger)
+	assert.Equal(t, int64(0), client.RequestCount())
+
+	_, err := client.DownloadAssetContents(server.URL)
+	assert.NoError(t, err)
+	_, err = derived.DownloadAssetContents(server.URL)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(2), client.RequestCount())
+	assert.Equal(t, int64(2), derived.RequestCount())
+	assert.Equal(t, int64(0), Client{}.RequestCount())
+}
+
+func TestClient_AssetTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte("contents"))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewClient(context.Background(), logger, "token").WithAssetTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := client.DownloadAssetContents(server.URL + "/slow")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// The timeout only applies to the stuck download, the client keeps working
+	contents, err := client.WithLogger(logger).DownloadAssetContents(server.URL + "/fast")
+	assert.NoError(t, err)
+	assert.Equal(t, "contents", string(contents))
+}
+
+func TestClient_AssetContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewClient(context.Background(), logger, "token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.DownloadAssetContentsContext(ctx, server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestClient_Trace(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Request-ID")
+		_, _ = w.Write([]byte("contents"))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	_, err := NewClient(context.Background(), logger, "token").DownloadAssetContents(server.URL)
+	assert.NoError(t, err)
+	assert.Empty(t, header)
+
+	ctx := ContextWithTrace(context.Background(), "X-Request-ID", "run-1234")
+	assert.Equal(t, "run-1234", TraceID(ctx))
+	client := NewClient(ctx, logger, "token").WithAssetTimeout(time.Minute)
+	_, err = client.DownloadAssetContents(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "run-1234", header)
+}
+
+func TestClient_RetryBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Rate limited, with a reset in the past so that the retry is sent right away
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewClient(context.Background(), logger, "token").WithRetryBudget(2)
+	derived := client.WithLogger(logger)
+
+	_, err := client.DownloadAssetContents(server.URL)
+	assert.NotErrorIs(t, err, ErrRetryBudgetExhausted)
+	_, err = derived.DownloadAssetContents(server.URL)
+	assert.NotErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, int64(4), client.RequestCount())
+
+	// The budget is shared, the third retry is not sent
+	_, err = client.DownloadAssetContents(server.URL)
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.Equal(t, int64(5), client.RequestCount())
+}
+
+func TestIsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	server.Close()
+
+	// Nothing listens on the address of the closed server
+	original := apiURL
+	apiURL = server.URL
+	t.Cleanup(func() { apiURL = original })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewClient(context.Background(), logger, "token")
+
+	_, err := client.GetUser("octocat")
+	assert.True(t, IsUnreachable(err))
+	_, err = client.DownloadAssetContents(server.URL + "/SHA256SUMS")
+	assert.True(t, IsUnreachable(err))
+	assert.True(t, IsUnreachable(fmt.Errorf("failed: %w", ErrRetryBudgetExhausted)))
+
+	assert.False(t, IsUnreachable(fmt.Errorf("%w: %q", ErrUserNotFound, "octocat")))
+	assert.False(t, IsUnreachable(errors.New("unexpected status code 500")))
+	assert.False(t, IsUnreachable(nil))
+}
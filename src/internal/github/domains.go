@@ -0,0 +1,36 @@
+package github
+
+import (
+	"fmt"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// GetOrgVerifiedDomains returns the verified domains of the organization. GitHub only lists the domains to tokens that
+// may see them, so an empty list can also mean that the token lacks access.
+func (c Client) GetOrgVerifiedDomains(org string) ([]string, error) {
+	done := c.apiThrottle()
+	defer done()
+
+	var query struct {
+		Organization struct {
+			Domains struct {
+				Nodes []struct {
+					Domain string // The domain name, without a scheme.
+				}
+			} `graphql:"domains(first: 100, isVerified: true)"`
+		} `graphql:"organization(login: $login)"`
+	}
+	variables := map[string]interface{}{
+		"login": githubv4.String(org),
+	}
+	if err := c.ghClient.Query(c.ctx, &query, variables); err != nil {
+		return nil, fmt.Errorf("failed to fetch the verified domains of %s: %w", org, err)
+	}
+
+	domains := make([]string, 0, len(query.Organization.Domains.Nodes))
+	for _, node := range query.Organization.Domains.Nodes {
+		domains = append(domains, node.Domain)
+	}
+	return domains, nil
+}
@@ -0,0 +1,57 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrgVerifiedDomains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(request.Query, "domains(first: 100, isVerified: true)") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var nodes []map[string]string
+		if request.Variables["login"] == "example" {
+			nodes = []map[string]string{{"domain": "example.com"}, {"domain": "example.org"}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"organization": map[string]any{"domains": map[string]any{"nodes": nodes}}},
+		})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := Client{
+		ctx:         ctx,
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		ghClient:    githubv4.NewEnterpriseClient(server.URL, server.Client()),
+		apiThrottle: NewThrottle(ctx, time.Millisecond, 1),
+	}
+
+	domains, err := client.GetOrgVerifiedDomains("example")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "example.org"}, domains)
+
+	domains, err = client.GetOrgVerifiedDomains("unverified")
+	assert.NoError(t, err)
+	assert.Empty(t, domains)
+}
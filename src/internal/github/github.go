@@ -71,10 +71,11 @@ type GHRelease struct {
 
 // ReleaseAsset represents a single asset within a GitHub release.
 // This includes details such as the download URL and the name of the asset.
+// The JSON tags are used when the asset is fetched from the REST API instead, see GetReleaseByTag.
 type ReleaseAsset struct {
-	ID          string // The ID of the asset.
-	DownloadURL string // The URL to download the asset.
-	Name        string // The name of the asset.
+	ID          string `json:"node_id"`              // The ID of the asset.
+	DownloadURL string `json:"browser_download_url"` // The URL to download the asset.
+	Name        string `json:"name"`                 // The name of the asset.
 }
 
 // GHRepository encapsulates GitHub repository details with a focus on its releases.
@@ -0,0 +1,37 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GPGKey is a GPG key registered on a GitHub account.
+type GPGKey struct {
+	KeyID  string `json:"key_id"`  // Long key ID of the primary key, upper case hex
+	RawKey string `json:"raw_key"` // The key as it was uploaded, ascii armor
+}
+
+// GetUserGPGKeys returns the GPG keys registered on the GitHub account of the user. Only the first 100 keys are
+// returned.
+func (c Client) GetUserGPGKeys(username string) ([]GPGKey, error) {
+	done := c.apiThrottle()
+	defer done()
+
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/users/%s/gpg_keys?per_page=100", apiURL, url.PathEscape(username)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v when listing the GPG keys of %q", resp.StatusCode, username)
+	}
+
+	var keys []GPGKey
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to decode the GPG keys of %q: %w", username, err)
+	}
+	return keys, nil
+}
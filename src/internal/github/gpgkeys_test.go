@@ -0,0 +1,46 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserGPGKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/octocat/gpg_keys" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 3, "key_id": "3262EFF25BA0D270", "raw_key": "-----BEGIN PGP PUBLIC KEY BLOCK-----"},
+		})
+	}))
+	defer server.Close()
+
+	original := apiURL
+	apiURL = server.URL
+	t.Cleanup(func() { apiURL = original })
+
+	ctx := context.Background()
+	client := Client{
+		ctx:         ctx,
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		httpClient:  server.Client(),
+		apiThrottle: NewThrottle(ctx, time.Millisecond, 1),
+	}
+
+	keys, err := client.GetUserGPGKeys("octocat")
+	assert.NoError(t, err)
+	assert.Equal(t, []GPGKey{{KeyID: "3262EFF25BA0D270", RawKey: "-----BEGIN PGP PUBLIC KEY BLOCK-----"}}, keys)
+
+	_, err = client.GetUserGPGKeys("ghost")
+	assert.ErrorContains(t, err, "unexpected status code 404")
+}
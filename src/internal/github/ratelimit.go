@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitLogInterval is how often progress is logged while waiting for a rate limit to reset.
+const rateLimitLogInterval = 30 * time.Second
+
+// defaultRetryJitter is the fraction of a wait for the rate limit to reset that is added at random, unless configured
+// otherwise with WithRetryJitter.
+const defaultRetryJitter = 0.1
+
+// rateLimit tracks the GitHub rate limit reported in the response headers and blocks requests until it resets once
+// it has been exhausted.
+type rateLimit struct {
+	log         *slog.Logger
+	logInterval time.Duration
+
+	mu      sync.Mutex
+	resetAt time.Time
+	jitter  float64    // Each wait is extended by a random duration of up to this fraction of it
+	random  *rand.Rand // Source of the jitter, not safe for concurrent use on its own
+}
+
+func newRateLimit(log *slog.Logger) *rateLimit {
+	return &rateLimit{
+		log:         log,
+		logInterval: rateLimitLogInterval,
+		jitter:      defaultRetryJitter,
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// WithRetryJitter extends each wait for the rate limit to reset by a random duration of up to factor times the wait.
+// Parallel verifications that hit the rate limit together then spread their retries out instead of all retrying the
+// moment it resets and exhausting it again. The jitter is shared by this client and all clients derived from it, like
+// WithRetryBudget. A factor of zero disables the jitter, a seed other than zero makes it deterministic for tests.
+func (c Client) WithRetryJitter(factor float64, seed int64) Client {
+	t, ok := c.httpClient.Transport.(*transport)
+	if !ok {
+		return c
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.limits.mu.Lock()
+	defer t.limits.mu.Unlock()
+	t.limits.jitter = factor
+	t.limits.random = rand.New(rand.NewSource(seed))
+	return c
+}
+
+// jittered returns wait extended by a random duration of up to r.jitter times wait. The caller must hold r.mu.
+func (r *rateLimit) jittered(wait time.Duration) time.Duration {
+	if r.jitter <= 0 || wait <= 0 {
+		return wait
+	}
+	return wait + time.Duration(r.random.Float64()*r.jitter*float64(wait))
+}
+
+// update records the rate limit state from the response headers.
+// It returns true if the response was rejected because the rate limit was exceeded.
+func (r *rateLimit) update(resp *http.Response) bool {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return false
+	}
+
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	r.resetAt = time.Unix(reset, 0)
+	r.mu.Unlock()
+
+	return resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// wait blocks until the rate limit has reset, plus the jitter, logging progress periodically so that long waits do not
+// look like a hang.
+func (r *rateLimit) wait(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	resetAt := now.Add(r.jittered(r.resetAt.Sub(now)))
+	r.mu.Unlock()
+
+	if !time.Now().Before(resetAt) {
+		return nil
+	}
+
+	ticker := time.NewTicker(r.logInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(resetAt))
+	defer timer.Stop()
+
+	for {
+		remaining := time.Until(resetAt).Round(time.Second)
+		r.log.Info(fmt.Sprintf("rate limited, resuming at %s (%ds remaining)", resetAt.Format(time.RFC3339), int(remaining.Seconds())))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
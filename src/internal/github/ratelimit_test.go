@@ -0,0 +1,84 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rateLimitedResponse(status int, remaining string, reset time.Time) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", remaining)
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return resp
+}
+
+func TestRateLimit_Update(t *testing.T) {
+	limits := newRateLimit(slog.Default())
+	reset := time.Now().Add(time.Hour)
+
+	assert.False(t, limits.update(rateLimitedResponse(http.StatusOK, "10", reset)))
+	assert.True(t, limits.resetAt.IsZero())
+
+	assert.False(t, limits.update(rateLimitedResponse(http.StatusOK, "0", reset)))
+	assert.Equal(t, reset.Unix(), limits.resetAt.Unix())
+
+	assert.True(t, limits.update(rateLimitedResponse(http.StatusForbidden, "0", reset)))
+}
+
+func TestRateLimit_WaitLogsProgress(t *testing.T) {
+	var logs bytes.Buffer
+	limits := newRateLimit(slog.New(slog.NewTextHandler(&logs, nil)))
+	limits.logInterval = 100 * time.Millisecond
+	limits.resetAt = time.Now().Add(time.Second)
+
+	start := time.Now()
+	err := limits.wait(context.Background())
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	assert.Greater(t, strings.Count(logs.String(), "rate limited, resuming at"), 1)
+}
+
+func TestRateLimit_WaitCancelled(t *testing.T) {
+	limits := newRateLimit(slog.Default())
+	limits.resetAt = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := limits.wait(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRateLimit_Jitter(t *testing.T) {
+	limits := newRateLimit(slog.Default())
+	limits.jitter = 0
+	assert.Equal(t, time.Minute, limits.jittered(time.Minute))
+
+	// Seeded alike, two clients wait alike
+	first := NewClient(context.Background(), slog.Default(), "token").WithRetryJitter(0.5, 42)
+	second := NewClient(context.Background(), slog.Default(), "token").WithRetryJitter(0.5, 42)
+	firstLimits := first.httpClient.Transport.(*transport).limits
+	secondLimits := second.httpClient.Transport.(*transport).limits
+
+	spread := map[time.Duration]bool{}
+	for i := 0; i < 10; i++ {
+		wait := firstLimits.jittered(time.Minute)
+		assert.Equal(t, wait, secondLimits.jittered(time.Minute))
+		assert.GreaterOrEqual(t, wait, time.Minute)
+		assert.LessOrEqual(t, wait, 90*time.Second)
+		spread[wait] = true
+	}
+	assert.Greater(t, len(spread), 1, "the waits should be spread out")
+
+	// Nothing to wait for
+	assert.Equal(t, time.Duration(0), firstLimits.jittered(0))
+	assert.Equal(t, -time.Second, firstLimits.jittered(-time.Second))
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Release is a single GitHub release, as returned by GetReleaseByTag.
+type Release struct {
+	TagName string         `json:"tag_name"`
+	Draft   bool           `json:"draft"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+// GetReleaseByTag returns the release of the given repository ("owner/name") that was published for the tag.
+func (c Client) GetReleaseByTag(repository string, tag string) (Release, error) {
+	done := c.apiThrottle()
+	defer done()
+
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/repos/%s/releases/tags/%s", apiURL, repository, url.PathEscape(tag)))
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Release{}, fmt.Errorf("no release found for tag %q in %s", tag, repository)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("unexpected status code %v when fetching the release %q of %s", resp.StatusCode, tag, repository)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("failed to decode the release %q of %s: %w", tag, repository, err)
+	}
+	return release, nil
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReleaseByTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/example/terraform-provider-example/releases/tags/v1.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":       1,
+			"tag_name": "v1.0.0",
+			"assets": []map[string]any{
+				{"id": 2, "node_id": "RA_2", "name": "SHA256SUMS", "browser_download_url": "https://example.com/SHA256SUMS"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	original := apiURL
+	apiURL = server.URL
+	t.Cleanup(func() { apiURL = original })
+
+	ctx := context.Background()
+	client := Client{
+		ctx:         ctx,
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		httpClient:  server.Client(),
+		apiThrottle: NewThrottle(ctx, time.Millisecond, 1),
+	}
+
+	release, err := client.GetReleaseByTag("example/terraform-provider-example", "v1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, Release{
+		TagName: "v1.0.0",
+		Assets:  []ReleaseAsset{{ID: "RA_2", Name: "SHA256SUMS", DownloadURL: "https://example.com/SHA256SUMS"}},
+	}, release)
+
+	_, err = client.GetReleaseByTag("example/terraform-provider-example", "v2.0.0")
+	assert.ErrorContains(t, err, `no release found for tag "v2.0.0" in example/terraform-provider-example`)
+}
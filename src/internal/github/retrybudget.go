@@ -0,0 +1,38 @@
+package github
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrRetryBudgetExhausted is returned for requests that would need to be retried once the retry budget of the client
+// has been used up.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// retryBudget limits the number of retries across all requests of a run, so that a systemic outage makes the run fail
+// instead of retrying each of its requests in turn.
+type retryBudget struct {
+	max  int64
+	used atomic.Int64
+}
+
+// take reserves a retry and returns false once the budget has been used up.
+func (b *retryBudget) take() bool {
+	return b.used.Add(1) <= b.max
+}
+
+// WithRetryBudget limits the number of retries to max for the rest of the run. The budget is shared by this client and
+// all clients derived from it, like RequestCount. Once it is used up, requests that would be retried fail with
+// ErrRetryBudgetExhausted. A max of zero removes the limit.
+func (c Client) WithRetryBudget(max int) Client {
+	t, ok := c.httpClient.Transport.(*transport)
+	if !ok {
+		return c
+	}
+	if max == 0 {
+		t.budget.Store(nil)
+	} else {
+		t.budget.Store(&retryBudget{max: int64(max)})
+	}
+	return c
+}
@@ -0,0 +1,29 @@
+package github
+
+import "context"
+
+type traceKey struct{}
+
+// trace is a request ID sent along with every GitHub request, so that the requests of one verification run can be
+// correlated.
+type trace struct {
+	header string
+	id     string
+}
+
+// ContextWithTrace returns a context that makes clients created with it, and requests made with it, send the trace ID
+// in the given header. No header is sent unless a trace is set up this way, to avoid leaking internal IDs.
+func ContextWithTrace(ctx context.Context, header string, id string) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace{header: header, id: id})
+}
+
+// TraceID returns the trace ID set up with ContextWithTrace, or an empty string.
+func TraceID(ctx context.Context) string {
+	t, _ := ctx.Value(traceKey{}).(trace)
+	return t.id
+}
+
+func traceFromContext(ctx context.Context) (trace, bool) {
+	t, ok := ctx.Value(traceKey{}).(trace)
+	return t, ok && t.header != "" && t.id != ""
+}
@@ -1,9 +1,13 @@
 package github
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
 func (c Client) IsUserInOrganization(username string, org string) (bool, error) {
@@ -14,7 +18,7 @@ func (c Client) IsUserInOrganization(username string, org string) (bool, error)
 	}
 
 	// user/org is not case sensitive here
-	check_url := fmt.Sprintf("https://api.github.com/orgs/%s/public_members/%s", org, username)
+	check_url := fmt.Sprintf("%s/orgs/%s/public_members/%s", apiURL, org, username)
 
 	resp, err := c.httpClient.Get(check_url)
 	if err != nil {
@@ -27,7 +31,50 @@ func (c Client) IsUserInOrganization(username string, org string) (bool, error)
 		return false, nil
 	case http.StatusNoContent:
 		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, fmt.Errorf("%w: status code %v when checking if %q is a member of %q", ErrPermissionDenied, resp.StatusCode, username, org)
 	default:
 		return false, fmt.Errorf("unexpected status code %v when checking if %q is a member of %q", resp.StatusCode, username, org)
 	}
 }
+
+// ErrPermissionDenied is returned when the token is not allowed to read the membership of an organization.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// ErrUserNotFound is returned when the GitHub API does not know the user. The API also hides suspended accounts this
+// way.
+var ErrUserNotFound = errors.New("user not found")
+
+// User is the subset of a GitHub account used during verification.
+type User struct {
+	Login       string     `json:"login"`
+	Type        string     `json:"type"`         // "User", "Organization" or "Bot"
+	Email       string     `json:"email"`        // Public profile email, empty if the user keeps it private
+	SuspendedAt *time.Time `json:"suspended_at"` // Only set by GitHub Enterprise Server
+}
+
+// GetUser returns the GitHub account of the user, or ErrUserNotFound if it does not exist or is suspended.
+func (c Client) GetUser(username string) (User, error) {
+	done := c.apiThrottle()
+	defer done()
+
+	resp, err := c.httpClient.Get(fmt.Sprintf("%s/users/%s", apiURL, url.PathEscape(username)))
+	if err != nil {
+		return User{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return User{}, fmt.Errorf("%w: %q", ErrUserNotFound, username)
+	default:
+		return User{}, fmt.Errorf("unexpected status code %v when getting the user %q", resp.StatusCode, username)
+	}
+
+	var user User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return User{}, fmt.Errorf("failed to decode the user %q: %w", username, err)
+	}
+	return user, nil
+}
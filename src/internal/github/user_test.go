@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/octocat":
+			_ = json.NewEncoder(w).Encode(map[string]any{"login": "octocat", "id": 1, "type": "User"})
+		case "/users/release-bot":
+			_ = json.NewEncoder(w).Encode(map[string]any{"login": "release-bot[bot]", "type": "Bot"})
+		case "/users/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	original := apiURL
+	apiURL = server.URL
+	t.Cleanup(func() { apiURL = original })
+
+	ctx := context.Background()
+	client := Client{
+		ctx:         ctx,
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		httpClient:  server.Client(),
+		apiThrottle: NewThrottle(ctx, time.Millisecond, 1),
+	}
+
+	user, err := client.GetUser("octocat")
+	assert.NoError(t, err)
+	assert.Equal(t, User{Login: "octocat", Type: "User"}, user)
+
+	user, err = client.GetUser("release-bot")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bot", user.Type)
+
+	_, err = client.GetUser("suspended")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = client.GetUser("broken")
+	assert.ErrorContains(t, err, "unexpected status code 500")
+}
+
+func TestIsUserInOrganization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/example/public_members/octocat":
+			w.WriteHeader(http.StatusNoContent)
+		case "/orgs/private/public_members/octocat":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	original := apiURL
+	apiURL = server.URL
+	t.Cleanup(func() { apiURL = original })
+
+	client := Client{httpClient: server.Client()}
+
+	member, err := client.IsUserInOrganization("octocat", "example")
+	assert.NoError(t, err)
+	assert.True(t, member)
+
+	member, err = client.IsUserInOrganization("hubot", "example")
+	assert.NoError(t, err)
+	assert.False(t, member)
+
+	_, err = client.IsUserInOrganization("octocat", "private")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
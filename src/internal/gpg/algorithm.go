@@ -0,0 +1,28 @@
+package gpg
+
+import (
+	"fmt"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+var publicKeyAlgorithmNames = map[packet.PublicKeyAlgorithm]string{
+	packet.PubKeyAlgoRSA:            "RSA",
+	packet.PubKeyAlgoRSAEncryptOnly: "RSA",
+	packet.PubKeyAlgoRSASignOnly:    "RSA",
+	packet.PubKeyAlgoElGamal:        "ElGamal",
+	packet.PubKeyAlgoDSA:            "DSA",
+	packet.PubKeyAlgoECDH:           "ECDH",
+	packet.PubKeyAlgoECDSA:          "ECDSA",
+	packet.PubKeyAlgoEdDSA:          "EdDSA",
+}
+
+// KeyAlgorithm returns the name of the public key algorithm of the primary key, e.g. "RSA" or "EdDSA".
+func KeyAlgorithm(key *crypto.Key) string {
+	algorithm := key.GetEntity().PrimaryKey.PubKeyAlgo
+	if name, ok := publicKeyAlgorithmNames[algorithm]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", algorithm)
+}
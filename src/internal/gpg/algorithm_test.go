@@ -0,0 +1,29 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyAlgorithm(t *testing.T) {
+	tests := []struct {
+		keyType  string
+		bits     int
+		expected string
+	}{
+		{keyType: "x25519", expected: "EdDSA"},
+		{keyType: "rsa", bits: 2048, expected: "RSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyType, func(t *testing.T) {
+			key, err := crypto.GenerateKey("Test User", "test@example.com", tt.keyType, tt.bits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.expected, KeyAlgorithm(key))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package gpg
+
+import "bytes"
+
+const publicKeyBlockEnd = "-----END PGP PUBLIC KEY BLOCK-----"
+
+// TrailingData returns whatever follows the first public key block in ascii armored key data, with surrounding
+// whitespace removed. The OpenPGP library silently ignores it, so a signature or text pasted after the key would go
+// unnoticed. Binary keys and data without a complete public key block have no trailing data.
+func TrailingData(data []byte) []byte {
+	end := bytes.Index(data, []byte(publicKeyBlockEnd))
+	if end < 0 {
+		return nil
+	}
+	trailing := bytes.TrimSpace(data[end+len(publicKeyBlockEnd):])
+	if len(trailing) == 0 {
+		return nil
+	}
+	return trailing
+}
@@ -0,0 +1,35 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrailingData(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name: "key only",
+			data: "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nabc\n-----END PGP PUBLIC KEY BLOCK-----\n\n",
+		},
+		{
+			name:     "prose",
+			data:     "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nabc\n-----END PGP PUBLIC KEY BLOCK-----\nThis is my key.\n",
+			expected: "This is my key.",
+		},
+		{
+			name: "binary",
+			data: "\x99\x00\x33",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(TrailingData([]byte(tt.data))))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/constants"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// Canonicalize returns the public part of the key stripped down to what is needed to verify its signatures: the
+// primary key and its revocations, each user ID with its latest self-signature and its revocations, and the subkeys
+// with their binding signatures and revocations. Third-party certifications and superseded self-signatures are
+// dropped.
+//
+// The OpenPGP library does not retain direct key signatures, so designated revokers declared in one are lost. Check
+// DesignatedRevokers on the original key data first if they need to be kept.
+func Canonicalize(key *crypto.Key) (*crypto.Key, error) {
+	data, err := SerializeCanonical(key)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := crypto.NewKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse canonicalized key: %w", err)
+	}
+	return canonical, nil
+}
+
+// SerializeCanonical returns the packets Canonicalize retains, in binary form. User IDs are written in sorted order,
+// so that the same key always serializes to the same bytes, which the OpenPGP library does not guarantee.
+func SerializeCanonical(key *crypto.Key) ([]byte, error) {
+	entity := key.GetEntity()
+
+	var buf bytes.Buffer
+	if err := entity.PrimaryKey.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("could not serialize primary key: %w", err)
+	}
+	if err := serializeSignatures(&buf, entity.Revocations); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entity.Identities))
+	for name := range entity.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		identity := entity.Identities[name]
+		if err := identity.UserId.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("could not serialize user ID %s: %w", name, err)
+		}
+		if err := serializeSignatures(&buf, append([]*packet.Signature{identity.SelfSignature}, identity.Revocations...)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, subkey := range entity.Subkeys {
+		if err := subkey.PublicKey.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("could not serialize subkey %s: %w", subkey.PublicKey.KeyIdString(), err)
+		}
+		if err := serializeSignatures(&buf, append([]*packet.Signature{subkey.Sig}, subkey.Revocations...)); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ArmorCanonical returns SerializeCanonical of the key as an ascii armored public key block, without version or
+// comment headers that would change with the library version.
+func ArmorCanonical(key *crypto.Key) (string, error) {
+	data, err := SerializeCanonical(key)
+	if err != nil {
+		return "", err
+	}
+	armored, err := armor.ArmorWithTypeAndCustomHeaders(data, constants.PublicKeyHeader, "", "")
+	if err != nil {
+		return "", fmt.Errorf("could not armor key: %w", err)
+	}
+	return armored, nil
+}
+
+// CanonicalArmor returns the key in data, given as ascii armor or in its binary form, in the ascii armored form it is
+// stored in the registry with. Keys are canonicalized, so that stored keys stay small and deterministic, except keys
+// that declare designated revokers: Canonicalize would drop the designation, so these are returned as submitted with
+// asSubmitted set.
+func CanonicalArmor(data []byte) (armored string, asSubmitted bool, err error) {
+	revokers, err := DesignatedRevokers(data)
+	if err != nil {
+		return "", false, fmt.Errorf("could not read designated revokers: %w", err)
+	}
+	if len(revokers) > 0 {
+		return string(data), true, nil
+	}
+
+	key, err := ParseKeyBytes(data)
+	if err != nil {
+		return "", false, fmt.Errorf("could not parse key: %w", err)
+	}
+	canonical, err := Canonicalize(key)
+	if err != nil {
+		return "", false, fmt.Errorf("could not canonicalize key: %w", err)
+	}
+	armored, err = ArmorCanonical(canonical)
+	return armored, false, err
+}
+
+func serializeSignatures(buf *bytes.Buffer, sigs []*packet.Signature) error {
+	for _, sig := range sigs {
+		if sig == nil {
+			continue
+		}
+		if err := sig.Serialize(buf); err != nil {
+			return fmt.Errorf("could not serialize signature: %w", err)
+		}
+	}
+	return nil
+}
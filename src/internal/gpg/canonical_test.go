@@ -0,0 +1,139 @@
+package gpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certifier, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A third-party certification, which is not needed to verify signatures made by the key
+	if err := key.GetEntity().SignIdentity("Test User <test@example.com>", certifier.GetEntity(), nil); err != nil {
+		t.Fatal(err)
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err = ParseKey(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, key.GetEntity().Identities["Test User <test@example.com>"].Signatures, 2)
+
+	canonical, err := Canonicalize(key)
+	assert.NoError(t, err)
+	assert.Equal(t, key.GetFingerprint(), canonical.GetFingerprint())
+	assert.Len(t, canonical.GetEntity().Identities["Test User <test@example.com>"].Signatures, 1)
+	assert.Len(t, canonical.GetEntity().Subkeys, 1)
+
+	original, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripped, err := SerializeCanonical(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Less(t, len(stripped), len(original))
+
+	// Canonicalizing is idempotent
+	again, err := SerializeCanonical(canonical)
+	assert.NoError(t, err)
+	assert.Equal(t, stripped, again)
+
+	armored, err = ArmorCanonical(canonical)
+	assert.NoError(t, err)
+	reparsed, err := ParseKey(armored)
+	assert.NoError(t, err)
+	assert.Equal(t, key.GetFingerprint(), reparsed.GetFingerprint())
+}
+
+// twoUIDsKey was generated with gpg and has the user IDs "Jane Doe <jane@example.com>" and
+// "John Smith <john@other.example>".
+const twoUIDsKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCGPxYJKwYBBAHaRw8BAQdA52Y8JO9Y18gTdVkvyO5CmgXyeYrkGn3ElRnU
+QDbH2C20G0phbmUgRG9lIDxqYW5lQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEXQNT
+gw2UEmjM0f8yLn8LmDH5EPEFAmrQhj8CGwMFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQLn8LmDH5EPHu/QD/dNXP0tz9esVX5bEEoNON3RGiRkqSz45ZRLW8CQ/a
+4OYBALm7LZd8PE5rS9MxU9fERROiyg4Lpz4mm7HNFdzF48wLtB9Kb2huIFNtaXRo
+IDxqb2huQG90aGVyLmV4YW1wbGU+iJAEExYIADgWIQRdA1ODDZQSaMzR/zIufwuY
+MfkQ8QUCatCGPwIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRAufwuYMfkQ
+8bEPAP9KuEg2CjNn6OAxXd/IskZHqCxOnO3Z4BtCnwZ9Ws+IBwEArfQgyDpVqKYf
+hAeir43WR33hJw2XmX/iKzEtrZZO6AM=
+=muBm
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestCanonicalize_Deterministic(t *testing.T) {
+	// The OpenPGP library keeps user IDs in a map, so their order varies between serializations
+	key, err := ParseKey(twoUIDsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := SerializeCanonical(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		// Parsing again gives the OpenPGP library a chance to order the user IDs differently
+		reparsed, err := Canonicalize(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, reparsed.GetEntity().Identities, 2)
+		data, err := SerializeCanonical(reparsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, first, data)
+	}
+}
+
+func TestCanonicalize_KeepsRevocations(t *testing.T) {
+	key, err := ParseKey(revokedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := Canonicalize(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Revocations(key, time.Unix(1792050461, 0)), Revocations(canonical, time.Unix(1792050461, 0)))
+	assert.True(t, IsRevoked(canonical, time.Unix(1792050461, 0)))
+}
+
+func TestCanonicalArmor(t *testing.T) {
+	publicGPGKey, _ := generateGPGKey()
+	armored, asSubmitted, err := CanonicalArmor([]byte(publicGPGKey))
+	assert.NoError(t, err)
+	assert.False(t, asSubmitted)
+	key, err := ParseKey(publicGPGKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ArmorCanonical(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected, armored)
+
+	// The designation would be lost
+	armored, asSubmitted, err = CanonicalArmor([]byte(designatedRevokerKey))
+	assert.NoError(t, err)
+	assert.True(t, asSubmitted)
+	assert.Equal(t, designatedRevokerKey, armored)
+
+	_, _, err = CanonicalArmor([]byte("not a key"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package gpg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// SigningCapability describes which part of a key carries the signing capability.
+type SigningCapability int
+
+const (
+	SigningCapabilityNone    SigningCapability = iota // Neither the primary key nor any subkey may sign
+	SigningCapabilityPrimary                          // The primary key may sign
+	SigningCapabilitySubkey                           // At least one subkey may sign, the primary key may not
+)
+
+// KeySigningCapability inspects the key flags of the primary key and its subkeys to find signing-capable material.
+// Validity (expiry, revocation) is not taken into account here, this only reports what the key declares.
+func KeySigningCapability(key *crypto.Key) SigningCapability {
+	entity := key.GetEntity()
+
+	identity := entity.PrimaryIdentity()
+	if identity != nil && identity.SelfSignature != nil &&
+		identity.SelfSignature.FlagsValid && identity.SelfSignature.FlagSign &&
+		entity.PrimaryKey.PubKeyAlgo.CanSign() {
+		return SigningCapabilityPrimary
+	}
+
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig != nil && subkey.Sig.FlagsValid && subkey.Sig.FlagSign && subkey.PublicKey.PubKeyAlgo.CanSign() {
+			return SigningCapabilitySubkey
+		}
+	}
+
+	return SigningCapabilityNone
+}
+
+// KeyUsage lists the capabilities the primary key or a subkey declares in its key flags.
+type KeyUsage struct {
+	KeyID  string   // The hex key ID of the primary key or subkey
+	Subkey bool     // Whether this is a subkey
+	Flags  []string // "certify", "sign", "encrypt" and "authenticate", in that order, empty if the key declares none
+}
+
+// String formats the usage like "subkey 191E74ED1196A147: sign".
+func (u KeyUsage) String() string {
+	kind := "primary key"
+	if u.Subkey {
+		kind = "subkey"
+	}
+	flags := "no usage flags"
+	if len(u.Flags) > 0 {
+		flags = strings.Join(u.Flags, ", ")
+	}
+	return fmt.Sprintf("%s %s: %s", kind, u.KeyID, flags)
+}
+
+// KeyUsages returns the usage of the primary key and of each subkey. Keys on OpenPGP cards such as a YubiKey usually
+// keep signing, encryption and authentication in separate subkeys, one per slot of the card.
+func KeyUsages(key *crypto.Key) []KeyUsage {
+	entity := key.GetEntity()
+
+	primary := KeyUsage{KeyID: strings.ToUpper(entity.PrimaryKey.KeyIdString())}
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		primary.Flags = usageFlags(identity.SelfSignature)
+	}
+	usages := []KeyUsage{primary}
+	for _, subkey := range entity.Subkeys {
+		usages = append(usages, KeyUsage{
+			KeyID:  strings.ToUpper(subkey.PublicKey.KeyIdString()),
+			Subkey: true,
+			Flags:  usageFlags(subkey.Sig),
+		})
+	}
+	return usages
+}
+
+// usageFlags returns the names of the usage flags of a self-signature or subkey binding signature.
+func usageFlags(sig *packet.Signature) []string {
+	if sig == nil || !sig.FlagsValid {
+		return nil
+	}
+	var flags []string
+	if sig.FlagCertify {
+		flags = append(flags, "certify")
+	}
+	if sig.FlagSign {
+		flags = append(flags, "sign")
+	}
+	if sig.FlagEncryptCommunications || sig.FlagEncryptStorage {
+		flags = append(flags, "encrypt")
+	}
+	if sig.FlagAuthenticate {
+		flags = append(flags, "authenticate")
+	}
+	return flags
+}
@@ -0,0 +1,103 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// cardKey has the layout of a key kept on an OpenPGP card such as a YubiKey: a certify-only Ed25519 primary key with
+// separate signing, encryption and authentication subkeys, one per slot of the card. Exported with "gpg --export".
+const cardKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCTVhYJKwYBBAHaRw8BAQdA4+kbvqDJNT/GFOtlqITLLbJwG+kguI9WvJYc
+7fuZr2O0HENhcmQgVXNlciA8Y2FyZEBleGFtcGxlLmNvbT6IkAQTFggAOBYhBOgp
+OW9bT9AC2ju84kczqAa0HAdFBQJq0JNWAhsBBQsJCAcCBhUKCQgLAgQWAgMBAh4B
+AheAAAoJEEczqAa0HAdFoVIBAPb5XLHkowO00ad5tupFeY8XE6uwPcoTI/mznfKp
+vzg4AQDBg1ZHu5byZG0iOIBgVFL7a33DnyPO5Ot5glnJ4XC3DLgzBGrQk1YWCSsG
+AQQB2kcPAQEHQLPgIpF88evRwCkCXLR0pERLnVROyEdelNk6tjN7Pt0jiO8EGBYI
+ACAWIQToKTlvW0/QAto7vOJHM6gGtBwHRQUCatCTVgIbAgCBCRBHM6gGtBwHRXYg
+BBkWCAAdFiEEK/BR1rhvZoK9nEYAGR507RGWoUcFAmrQk1YACgkQGR507RGWoUe1
+4gD/eK7L3IiBvbCXAB6l3fTF7en0L6YTJzxExK0YITyx1CwBAK0Yd9137CmEZcxD
+l6bPyTaubwgkDJ+7TL3PTrByVMMGRRQA/jy5F62puKN6XHKQs+cgW8Th+RwX8wGa
+czjZzUrCbOo7AQCZqC5upOCWjWm5DKDXM22awYWK8GqaXsOZuiVYHFYuC7g4BGrQ
+k1YSCisGAQQBl1UBBQEBB0Dfp90iOM7SigGQZvwFyQawF2Vu6mwpIA1+ocSQTNsW
+NAMBCAeIdwQYFggAIBYhBOgpOW9bT9AC2ju84kczqAa0HAdFBQJq0JNWAhsMAAoJ
+EEczqAa0HAdF5lQA91syx+fnf8jM7zSFh+OCKf81h3pZrbNllBDvyzhTLiYBAPHI
+5cbN9Di7u04UNN2PzJBVXLVW1PIzWBtDCHAH7UEAuDMEatCTVhYJKwYBBAHaRw8B
+AQdAvlSQ3d6bXvxaBpJTOt86A5vQLC27FYv3ZCbm7wNvKfeIeAQYFggAIBYhBOgp
+OW9bT9AC2ju84kczqAa0HAdFBQJq0JNWAhsgAAoJEEczqAa0HAdF67sBAOLdZCcf
+gVmphikMnrZk58BVf99UZJZokmAyKMtA17hMAQCSGc4ZC27w6l+zVw5mtghZC57v
+jffx58k+EID6wvocDA==
+=6ow+
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestKeySigningCapability(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(key *crypto.Key)
+		expected SigningCapability
+	}{
+		{
+			name:     "primary key can sign",
+			modify:   func(key *crypto.Key) {},
+			expected: SigningCapabilityPrimary,
+		},
+		{
+			name: "only subkey can sign",
+			modify: func(key *crypto.Key) {
+				key.GetEntity().PrimaryIdentity().SelfSignature.FlagSign = false
+				key.GetEntity().Subkeys[0].Sig.FlagSign = true
+			},
+			expected: SigningCapabilitySubkey,
+		},
+		{
+			name: "nothing can sign",
+			modify: func(key *crypto.Key) {
+				key.GetEntity().PrimaryIdentity().SelfSignature.FlagSign = false
+			},
+			expected: SigningCapabilityNone,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			publicGPGKey, _ := generateGPGKey()
+			key, err := ParseKey(publicGPGKey)
+			assert.NoError(t, err)
+
+			test.modify(key)
+
+			assert.Equal(t, test.expected, KeySigningCapability(key))
+		})
+	}
+}
+
+func TestKeySigningCapability_Card(t *testing.T) {
+	key, err := ParseKey(cardKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, SigningCapabilitySubkey, KeySigningCapability(key))
+
+	certifications := CheckSigningSubkeyCrossCertifications(key)
+	assert.Equal(t, []SubkeyCrossCertification{{KeyID: "191E74ED1196A147"}}, certifications)
+}
+
+func TestKeyUsages(t *testing.T) {
+	key, err := ParseKey(cardKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usages := KeyUsages(key)
+	assert.Equal(t, []KeyUsage{
+		{KeyID: "4733A806B41C0745", Flags: []string{"certify"}},
+		{KeyID: "191E74ED1196A147", Subkey: true, Flags: []string{"sign"}},
+		{KeyID: "8EEC93F284FF7FC1", Subkey: true, Flags: []string{"encrypt"}},
+		{KeyID: "E681754592A76EDD", Subkey: true, Flags: []string{"authenticate"}},
+	}, usages)
+	assert.Equal(t, "primary key 4733A806B41C0745: certify", usages[0].String())
+	assert.Equal(t, "subkey E681754592A76EDD: authenticate", usages[3].String())
+	assert.Equal(t, "subkey 0123456789ABCDEF: no usage flags", KeyUsage{KeyID: "0123456789ABCDEF", Subkey: true}.String())
+}
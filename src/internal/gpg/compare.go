@@ -0,0 +1,55 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// SamePublicKey reports whether both keys serialize to the same public key packets, meaning that they share their
+// user IDs, subkeys and signatures. The OpenPGP library stores user IDs in a map and therefore does not serialize them
+// in a stable order, so the packets are compared regardless of their order.
+func SamePublicKey(a *crypto.Key, b *crypto.Key) (bool, error) {
+	aPackets, err := publicKeyPackets(a)
+	if err != nil {
+		return false, err
+	}
+	bPackets, err := publicKeyPackets(b)
+	if err != nil {
+		return false, err
+	}
+
+	if len(aPackets) != len(bPackets) {
+		return false, nil
+	}
+	for i := range aPackets {
+		if !bytes.Equal(aPackets[i], bPackets[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// publicKeyPackets returns the sorted packets of the serialized public key, each prefixed with its tag.
+func publicKeyPackets(key *crypto.Key) ([][]byte, error) {
+	data, err := key.GetPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize public key: %w", err)
+	}
+
+	var packets [][]byte
+	for len(data) > 0 {
+		tag, contents, rest, err := readPacket(data)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, append([]byte{tag}, contents...))
+		data = rest
+	}
+	sort.Slice(packets, func(i, j int) bool {
+		return bytes.Compare(packets[i], packets[j]) < 0
+	})
+	return packets, nil
+}
@@ -0,0 +1,39 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamePublicKey(t *testing.T) {
+	armored, err := generateGPGKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherArmored, err := generateGPGKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ParseKey(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := ParseKey(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ParseKey(otherArmored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same, err := SamePublicKey(key, reparsed)
+	assert.NoError(t, err)
+	assert.True(t, same)
+
+	same, err = SamePublicKey(key, other)
+	assert.NoError(t, err)
+	assert.False(t, same)
+}
@@ -0,0 +1,120 @@
+package gpg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// EncryptionKey is the state of the primary key or a subkey that declares encryption capability in its key flags. Its
+// binding signature is valid, the OpenPGP library rejects keys with invalid binding signatures when parsing them.
+type EncryptionKey struct {
+	KeyID   string    // The hex key ID of the primary key or subkey
+	Subkey  bool      // Whether this is a subkey
+	Expires time.Time // Zero if the key never expires
+	Expired bool      // Whether the key, or the primary key it belongs to, has expired
+	Revoked bool      // Whether the key, or the primary key it belongs to, is revoked
+}
+
+// Status is "revoked", "expired" or "valid".
+func (k EncryptionKey) Status() string {
+	switch {
+	case k.Revoked:
+		return "revoked"
+	case k.Expired:
+		return "expired"
+	default:
+		return "valid"
+	}
+}
+
+// String formats the key like "subkey 8EEC93F284FF7FC1: valid, does not expire".
+func (k EncryptionKey) String() string {
+	kind := "primary key"
+	if k.Subkey {
+		kind = "subkey"
+	}
+	state := k.Status()
+	switch {
+	case k.Revoked:
+	case k.Expired:
+		state = "expired on " + k.Expires.Format(time.DateOnly)
+	case k.Expires.IsZero():
+		state += ", does not expire"
+	default:
+		state += " until " + k.Expires.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s %s: %s", kind, k.KeyID, state)
+}
+
+// EncryptionKeys returns the state at now of the primary key and of each subkey flagged for encryption. A subkey
+// cannot outlive its primary key, so a subkey without an expiration of its own expires together with the primary key.
+func EncryptionKeys(key *crypto.Key, now time.Time) []EncryptionKey {
+	entity := key.GetEntity()
+
+	primary := EncryptionKey{
+		KeyID:   strings.ToUpper(entity.PrimaryKey.KeyIdString()),
+		Expired: IsExpired(key, now),
+		Revoked: IsRevoked(key, now),
+	}
+	identity := entity.PrimaryIdentity()
+	if identity != nil && identity.SelfSignature != nil {
+		primary.Expires = expiry(entity.PrimaryKey.CreationTime, identity.SelfSignature.KeyLifetimeSecs)
+	}
+
+	var result []EncryptionKey
+	if flagsEncrypt(identity.SelfSignature) {
+		result = append(result, primary)
+	}
+	for _, subkey := range entity.Subkeys {
+		if !flagsEncrypt(subkey.Sig) {
+			continue
+		}
+		k := EncryptionKey{
+			KeyID:   strings.ToUpper(subkey.PublicKey.KeyIdString()),
+			Subkey:  true,
+			Expires: expiry(subkey.PublicKey.CreationTime, subkey.Sig.KeyLifetimeSecs),
+			Expired: primary.Expired || subkey.PublicKey.KeyExpired(subkey.Sig, now) || subkey.Sig.SigExpired(now),
+			Revoked: primary.Revoked || subkey.Revoked(now),
+		}
+		if !primary.Expires.IsZero() && (k.Expires.IsZero() || k.Expires.After(primary.Expires)) {
+			k.Expires = primary.Expires
+		}
+		result = append(result, k)
+	}
+	return result
+}
+
+// flagsEncrypt reports whether a self-signature or subkey binding signature flags the key for encryption.
+func flagsEncrypt(sig *packet.Signature) bool {
+	return sig != nil && sig.FlagsValid && (sig.FlagEncryptCommunications || sig.FlagEncryptStorage)
+}
+
+// CheckEncryption encrypts a test message to the key as it would be at now and returns the hex key ID of the key the
+// OpenPGP library chose to encrypt it to. Without the private key the message cannot be decrypted again, this only
+// shows that a sender would find a usable encryption key.
+func CheckEncryption(key *crypto.Key, now time.Time) (string, error) {
+	entity := key.GetEntity()
+	encryptionKey, ok := entity.EncryptionKey(now)
+	if !ok {
+		return "", fmt.Errorf("no encryption key is valid")
+	}
+
+	config := &packet.Config{Time: func() time.Time { return now }}
+	plaintext, err := openpgp.Encrypt(io.Discard, []*openpgp.Entity{entity}, nil, nil, config)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(plaintext, "verify-gpg-key"); err != nil {
+		return "", err
+	}
+	if err := plaintext.Close(); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(encryptionKey.PublicKey.KeyIdString()), nil
+}
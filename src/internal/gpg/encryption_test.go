@@ -0,0 +1,67 @@
+package gpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encryptionKey was generated with gpg on 2026-10-15. Its encryption subkey expires after a year, on 2027-10-15.
+const encryptionKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCVXxYJKwYBBAHaRw8BAQdAt6gT6RXZ7q5Xb1tTIusr0UH3fwk/eqpbYKZS
+8Qk23GO0KEVuY3J5cHRpb24gVXNlciA8ZW5jcnlwdGlvbkBleGFtcGxlLmNvbT6I
+jwQTFggAOBYhBHzxK78yLTt0p/5oUvHZIL3X7oz+BQJq0JVfAhsDBQsJCAcCBhUK
+CQgLAgQWAgMBAh4BAheAAAoJEPHZIL3X7oz+TNYBAP3ZV7KY4JrsDNLZpLj1MuVS
+0yuH9Ly2rx369hzHZUBDAPjC2wRijv/bIyVaJbfkcp21fGAVhAch+Nc8cxGLQL0M
+uDgEatCVXxIKKwYBBAGXVQEFAQEHQMzkpPLozEWT0OGleXBzUcHnfqAQpqrpHNWM
+VJ+2TIwbAwEIB4h+BBgWCAAmFiEEfPErvzItO3Sn/mhS8dkgvdfujP4FAmrQlV8C
+GwwFCQHhM4AACgkQ8dkgvdfujP63YgEAwMYELPXDtrYm9bobNcXRXjYbHn0gJ7ki
+HPSp3wDhHdMBAI+PIObacIIU+LyJdCqxiWW1Vd1VZqFGf35WGyaK4ckG
+=aRB6
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestEncryptionKeys(t *testing.T) {
+	key, err := ParseKey(encryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Unix(1823590623, 0).UTC()
+
+	valid := EncryptionKeys(key, time.Unix(1800000000, 0))
+	assert.Equal(t, []EncryptionKey{{KeyID: "35F0227A3296ADA3", Subkey: true, Expires: expires}}, valid)
+	assert.Equal(t, "subkey 35F0227A3296ADA3: valid until 2027-10-15", valid[0].String())
+
+	expired := EncryptionKeys(key, time.Unix(1900000000, 0))
+	assert.Equal(t, []EncryptionKey{{KeyID: "35F0227A3296ADA3", Subkey: true, Expires: expires, Expired: true}}, expired)
+	assert.Equal(t, "subkey 35F0227A3296ADA3: expired on 2027-10-15", expired[0].String())
+
+	cardKey, err := ParseKey(cardKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := EncryptionKeys(cardKey, time.Unix(1800000000, 0))
+	assert.Equal(t, []EncryptionKey{{KeyID: "8EEC93F284FF7FC1", Subkey: true}}, card)
+	assert.Equal(t, "subkey 8EEC93F284FF7FC1: valid, does not expire", card[0].String())
+
+	signOnly, err := ParseKey(expiringKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, EncryptionKeys(signOnly, time.Unix(1800000000, 0)))
+}
+
+func TestCheckEncryption(t *testing.T) {
+	key, err := ParseKey(encryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyID, err := CheckEncryption(key, time.Unix(1800000000, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "35F0227A3296ADA3", keyID)
+
+	_, err = CheckEncryption(key, time.Unix(1900000000, 0))
+	assert.EqualError(t, err, "no encryption key is valid")
+}
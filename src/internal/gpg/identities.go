@@ -0,0 +1,51 @@
+package gpg
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// PrimaryUserIDs returns the user IDs whose self-signature marks them as the primary user ID, sorted by name.
+// A well-formed key designates exactly one primary user ID.
+func PrimaryUserIDs(key *crypto.Key) []string {
+	var primary []string
+	for name, identity := range key.GetEntity().Identities {
+		sig := identity.SelfSignature
+		if sig != nil && sig.IsPrimaryId != nil && *sig.IsPrimaryId {
+			primary = append(primary, name)
+		}
+	}
+	sort.Strings(primary)
+	return primary
+}
+
+// ParseUID splits a user ID of the conventional form "Name (Comment) <email>" into the part before the email and the
+// email itself. The email is taken from the last opening angle bracket up to the closing one that follows it, so that
+// brackets in the name or after the email do not end up in the email. A user ID without an email returns an empty
+// email and no error. An email that is not a plain address, such as one with a display name or quoted parts, returns
+// an error, so a non-empty email always parses back to itself with mail.ParseAddress.
+func ParseUID(uid string) (name string, email string, err error) {
+	start := strings.LastIndex(uid, "<")
+	if start == -1 {
+		return strings.TrimSpace(uid), "", nil
+	}
+	end := strings.Index(uid[start:], ">")
+	if end == -1 {
+		return strings.TrimSpace(uid), "", nil
+	}
+	name = strings.TrimSpace(uid[:start])
+	raw := uid[start+1 : start+end]
+
+	address, err := mail.ParseAddress(raw)
+	if err != nil {
+		return name, "", err
+	}
+	if address.Name != "" || address.Address != raw {
+		return name, "", fmt.Errorf("%q is not a plain email address", raw)
+	}
+	return name, raw, nil
+}
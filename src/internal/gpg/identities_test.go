@@ -0,0 +1,116 @@
+package gpg
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrimaryUserIDs(t *testing.T) {
+	t.Run("single primary user ID", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+
+		assert.Equal(t, []string{"test <test@example.com>"}, PrimaryUserIDs(key))
+	})
+
+	t.Run("no primary user ID", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+		for _, identity := range key.GetEntity().Identities {
+			identity.SelfSignature.IsPrimaryId = nil
+		}
+
+		assert.Empty(t, PrimaryUserIDs(key))
+	})
+
+	t.Run("multiple primary user IDs", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+		identities := key.GetEntity().Identities
+		for _, identity := range identities {
+			other := *identity
+			identities["other <other@example.com>"] = &other
+			break
+		}
+
+		assert.Equal(t, []string{"other <other@example.com>", "test <test@example.com>"}, PrimaryUserIDs(key))
+	})
+}
+
+func TestParseUID(t *testing.T) {
+	tests := []struct {
+		uid   string
+		name  string
+		email string
+		err   string
+	}{
+		{uid: "Test User <test@example.com>", name: "Test User", email: "test@example.com"},
+		{uid: "Test User (Signing Key) <test@example.com>", name: "Test User (Signing Key)", email: "test@example.com"},
+		{uid: "<test@example.com>", name: "", email: "test@example.com"},
+		{uid: "Test User", name: "Test User", email: ""},
+		{uid: "Test User <test@example.com", name: "Test User <test@example.com", email: ""},
+		// The greedy regex this replaces took everything up to the last closing bracket as the email
+		{uid: "Test User <test@example.com> trailing>", name: "Test User", email: "test@example.com"},
+		{uid: "Test <User> <test@example.com>", name: "Test <User>", email: "test@example.com"},
+		{uid: "Tëst Üser <tëst@bücher.de>", name: "Tëst Üser", email: "tëst@bücher.de"},
+		{uid: "Test User <not an email>", name: "Test User", err: "mail: "},
+		{uid: "Test User <Other <test@example.com>>", name: "Test User <Other", email: "test@example.com"},
+		{uid: `Test User <"test user"@example.com>`, name: "Test User", err: "is not a plain email address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			name, email, err := ParseUID(tt.uid)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.email, email)
+		})
+	}
+}
+
+func FuzzParseUID(f *testing.F) {
+	for _, uid := range []string{
+		"Test User <test@example.com>",
+		"Test User (Comment) <test@example.com>",
+		"Test User <test@example.com> trailing>",
+		"Test <User> <test@example.com>",
+		"Tëst Üser <tëst@xn--bcher-kva.de>",
+		`Test User <"quoted"@example.com>`,
+		"<>",
+		"<<>>",
+		"Test User",
+	} {
+		f.Add(uid)
+	}
+
+	f.Fuzz(func(t *testing.T, uid string) {
+		name, email, err := ParseUID(uid)
+		if err != nil && email != "" {
+			t.Fatalf("ParseUID(%q) returned the email %q along with the error %v", uid, email, err)
+		}
+		if email == "" {
+			return
+		}
+		if strings.ContainsAny(email, "<>") {
+			t.Fatalf("ParseUID(%q) returned the email %q, which contains an angle bracket", uid, email)
+		}
+		if !strings.Contains(uid, "<"+email+">") {
+			t.Fatalf("ParseUID(%q) returned the email %q, which is not enclosed in angle brackets in the user ID", uid, email)
+		}
+		if utf8.ValidString(uid) && !utf8.ValidString(name) {
+			t.Fatalf("ParseUID(%q) returned the name %q, which is not valid UTF-8", uid, name)
+		}
+		address, err := mail.ParseAddress(email)
+		if err != nil {
+			t.Fatalf("ParseUID(%q) returned the email %q, which does not parse: %v", uid, email, err)
+		}
+		if address.Address != email {
+			t.Fatalf("ParseUID(%q) returned the email %q, which parses as %q", uid, email, address.Address)
+		}
+	})
+}
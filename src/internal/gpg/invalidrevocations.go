@@ -0,0 +1,228 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// InvalidRevocation is a revocation signature of a key that does not verify, so it cannot be trusted as a revocation.
+type InvalidRevocation struct {
+	Target string // What the signature claims to revoke, "the key", "subkey <key ID>" or "user ID <user ID>"
+	Reason string // Why the signature does not verify
+}
+
+func (r InvalidRevocation) String() string {
+	return fmt.Sprintf("revocation of %s: %s", r.Target, r.Reason)
+}
+
+// DesignatedRevocation is a revocation of the key made by one of its designated revokers. The OpenPGP library cannot
+// verify it without the revoker's key, so it is left out of the parsed key and must be verified with Verify.
+type DesignatedRevocation struct {
+	Revoker    string     // Fingerprint of the designated revoker that made the revocation
+	Revocation Revocation // The reason of the revocation
+
+	primary *packet.PublicKey
+	sig     *packet.Signature
+}
+
+// Verify checks that the revocation was made by the given key of the designated revoker.
+func (r DesignatedRevocation) Verify(revoker *crypto.Key) error {
+	if !strings.EqualFold(revoker.GetFingerprint(), r.Revoker) {
+		return fmt.Errorf("the key %s is not the designated revoker %s", strings.ToUpper(revoker.GetFingerprint()), r.Revoker)
+	}
+	if !r.sig.Hash.Available() {
+		return fmt.Errorf("the revocation signature uses an unsupported hash algorithm")
+	}
+	h := r.sig.Hash.New()
+	if err := r.primary.SerializeForHash(h); err != nil {
+		return err
+	}
+	if err := revoker.GetEntity().PrimaryKey.VerifySignature(h, r.sig); err != nil {
+		return fmt.Errorf("the signature does not verify: %w", err)
+	}
+	return nil
+}
+
+// InEffect reports whether the revocation is in effect at now, as for Revocations.
+func (r DesignatedRevocation) InEffect(now time.Time) bool {
+	return revocationInEffect(r.sig, now)
+}
+
+// StripInvalidRevocations removes the revocation signatures that do not verify from the first key in data, given as
+// ascii armor or in its binary form, and returns it in its binary form with a description of each removed signature.
+// Key and subkey revocations must be made by the primary key and user ID revocations made by the primary key must
+// verify. The OpenPGP library rejects the whole key for any of these, which would also hide its valid revocations.
+// Revocations made by a designated revoker are removed as well, as the library cannot verify them, but they are not
+// invalid and are returned by DesignatedRevocations instead. User ID revocations made by other keys revoke their
+// certifications rather than the user ID and are kept. The data is returned unchanged, with no invalid revocations,
+// if all revocations verify.
+func StripInvalidRevocations(data []byte) ([]byte, []InvalidRevocation, error) {
+	stripped, invalid, _, err := stripRevocations(data)
+	return stripped, invalid, err
+}
+
+// stripRevocations removes the revocations the OpenPGP library would reject the key for, returning the invalid ones
+// and the ones made by a designated revoker separately.
+func stripRevocations(data []byte) ([]byte, []InvalidRevocation, []DesignatedRevocation, error) {
+	revokers, err := DesignatedRevokers(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		unarmored, err := armor.Unarmor(string(data))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not unarmor key: %w", err)
+		}
+		data = unarmored
+	}
+
+	_, _, rest, err := readPacket(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p, err := packet.Read(bytes.NewReader(data[:len(data)-len(rest)]))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not read primary key: %w", err)
+	}
+	var primary *packet.PublicKey
+	switch pk := p.(type) {
+	case *packet.PublicKey:
+		primary = pk
+	case *packet.PrivateKey:
+		primary = &pk.PublicKey
+	default:
+		return nil, nil, nil, fmt.Errorf("first packet is not a key")
+	}
+
+	var invalid []InvalidRevocation
+	var designated []DesignatedRevocation
+	kept := [][]byte{data[:len(data)-len(rest)]}
+	var userID string
+	var subkey *packet.PublicKey
+	for len(rest) > 0 {
+		tag, contents, next, err := readPacket(rest)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		raw := rest[:len(rest)-len(next)]
+		if tag == packetTagPublicKey || tag == packetTagSecretKey {
+			// The next key of a keyring starts here
+			break
+		}
+		rest = next
+
+		switch tag {
+		case packetTagUserID:
+			userID, subkey = string(contents), nil
+		case packetTagUserAttribute:
+			userID, subkey = "", nil
+		case packetTagPublicSubkey, packetTagSecretSubkey:
+			userID, subkey = "", nil
+			if p, err := packet.Read(bytes.NewReader(raw)); err == nil {
+				switch pk := p.(type) {
+				case *packet.PublicKey:
+					subkey = pk
+				case *packet.PrivateKey:
+					subkey = &pk.PublicKey
+				}
+			}
+		case packetTagSignature:
+			revocation, designatedRevocation := checkRevocation(primary, userID, subkey, contents, raw, revokers)
+			if revocation != nil {
+				invalid = append(invalid, *revocation)
+				continue
+			}
+			if designatedRevocation != nil {
+				designated = append(designated, *designatedRevocation)
+				continue
+			}
+		}
+		kept = append(kept, raw)
+	}
+	if len(invalid) == 0 && len(designated) == 0 {
+		return data, nil, nil, nil
+	}
+
+	stripped := bytes.Join(kept, nil)
+	return append(stripped, rest...), invalid, designated, nil
+}
+
+// InvalidRevocations returns the revocation signatures of the key in data that do not verify, which ParseKey and
+// ParseKeyBytes ignore.
+func InvalidRevocations(data []byte) ([]InvalidRevocation, error) {
+	normalized, _, err := NormalizePacketOrder(data)
+	if err != nil {
+		return nil, err
+	}
+	_, invalid, _, err := stripRevocations(normalized)
+	return invalid, err
+}
+
+// DesignatedRevocations returns the revocations of the key in data made by its designated revokers, which ParseKey and
+// ParseKeyBytes leave out. They need to be verified with the key of the revoker.
+func DesignatedRevocations(data []byte) ([]DesignatedRevocation, error) {
+	normalized, _, err := NormalizePacketOrder(data)
+	if err != nil {
+		return nil, err
+	}
+	_, _, designated, err := stripRevocations(normalized)
+	return designated, err
+}
+
+// checkRevocation returns why the signature packet is an invalid revocation of the primary key, of the subkey or of
+// the user ID it follows, or the revocation if it was made by a designated revoker of the key. Both are nil if it is
+// not a revocation or it verifies. Signatures the OpenPGP library cannot read are left for it to report.
+func checkRevocation(primary *packet.PublicKey, userID string, subkey *packet.PublicKey, contents []byte, raw []byte, revokers []DesignatedRevoker) (*InvalidRevocation, *DesignatedRevocation) {
+	sigType := signatureType(contents)
+	if sigType != signatureTypeKeyRevocation && sigType != signatureTypeSubkeyRevocation && sigType != signatureTypeCertRevocation {
+		return nil, nil
+	}
+	p, err := packet.Read(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return nil, nil
+	}
+
+	var target string
+	switch sigType {
+	case signatureTypeKeyRevocation:
+		target = "the key"
+		err = primary.VerifyRevocationSignature(sig)
+	case signatureTypeSubkeyRevocation:
+		if subkey == nil {
+			return nil, nil
+		}
+		target = "subkey " + strings.ToUpper(subkey.KeyIdString())
+		err = primary.VerifyKeySignature(subkey, sig)
+	case signatureTypeCertRevocation:
+		if userID == "" || !sig.CheckKeyIdOrFingerprint(primary) {
+			return nil, nil
+		}
+		target = "user ID " + userID
+		err = primary.VerifyUserIdSignature(userID, primary, sig)
+	}
+
+	if err == nil {
+		return nil, nil
+	}
+	if sig.IssuerKeyId != nil && !sig.CheckKeyIdOrFingerprint(primary) {
+		issuer := fmt.Sprintf("%016X", *sig.IssuerKeyId)
+		// Designated revokers can only revoke the key itself (RFC 4880, section 5.2.3.15)
+		for _, r := range revokers {
+			if sigType == signatureTypeKeyRevocation && strings.HasSuffix(r.Fingerprint, issuer) {
+				return nil, &DesignatedRevocation{Revoker: r.Fingerprint, Revocation: revocation("", sig), primary: primary, sig: sig}
+			}
+		}
+		return &InvalidRevocation{Target: target, Reason: fmt.Sprintf("made by the key %s rather than the key itself", issuer)}, nil
+	}
+	return &InvalidRevocation{Target: target, Reason: fmt.Sprintf("the signature does not verify: %s", err)}, nil
+}
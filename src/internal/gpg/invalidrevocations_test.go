@@ -0,0 +1,184 @@
+package gpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/stretchr/testify/assert"
+)
+
+// badRevocationKey was generated by GnuPG and carries a revocation signature with the reason "key superseded" whose
+// last byte was altered, so that it no longer verifies.
+const badRevocationKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdAB9+G6mNJEjmZ//2dwPH673+QvUKGQXBM7EEX
+YSDnv3CIjQQgFggANRYhBEgXsP9lhFRL4p/95W8wP1W8oC+hBQJq0JhjFx0DUmVw
+bGFjZWQgYnkgYSBuZXcga2V5AAoJEG8wP1W8oC+hpUABAMX5vvx2Jnpnv5GaAOIG
+tlUcGiaka2GUrXogbZcqEL7DAQCRicWJWQHkt4PR0XQXMEk+B/e7dHfHd6D6oFyI
+4IN/CbQhUmV2b2tlZCBPd25lciA8b3duZXJAZXhhbXBsZS5jb20+iJAEExYIADgW
+IQRIF7D/ZYRUS+Kf/eVvMD9VvKAvoQUCatCYTgIbAwULCQgHAgYVCgkICwIEFgID
+AQIeAQIXgAAKCRBvMD9VvKAvoeXuAQCdDHwMl6YWnRgdsLwl4PcC2v/YrcGnUO4T
+PNNhmAGuVwEAy3S70Ja88O0AupZ3kQ5EYYKjLw5X8j4+XbMo7yBbugo=
+=/7Rz
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// revokedWithInvalidRevocationKey is badRevocationKey with a valid revocation signature, generated by GnuPG, in front
+// of the altered one.
+const revokedWithInvalidRevocationKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdAB9+G6mNJEjmZ//2dwPH673+QvUKGQXBM7EEX
+YSDnv3CIeAQgFggAIBYhBEgXsP9lhFRL4p/95W8wP1W8oC+hBQJq0JhOAh0AAAoJ
+EG8wP1W8oC+hflwA/3Y6VCCN89R69O320JDBsCJItW4a3Sl3kVpBM/45ORBxAP9E
+rLBpCEyV0gN67EvJMnDerlTVHQK6Zv03Cc5ta5/pB4iNBCAWCAA1FiEESBew/2WE
+VEvin/3lbzA/VbygL6EFAmrQmGMXHQNSZXBsYWNlZCBieSBhIG5ldyBrZXkACgkQ
+bzA/VbygL6GlQAEAxfm+/HYmeme/kZoA4ga2VRwaJqRrYZSteiBtlyoQvsMBAJGJ
+xYlZAeS3g9HRdBcwST4H97t0d8d3oPqgXIjgg38JtCFSZXZva2VkIE93bmVyIDxv
+d25lckBleGFtcGxlLmNvbT6IkAQTFggAOBYhBEgXsP9lhFRL4p/95W8wP1W8oC+h
+BQJq0JhOAhsDBQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEG8wP1W8oC+h5e4B
+AJ0MfAyXphadGB2wvCXg9wLa/9itwadQ7hM802GYAa5XAQDLdLvQlrzw7QC6lneR
+DkRhgqMvDlfyPj5dsyjvIFu6Cg==
+=Ibi6
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// foreignRevocationKey carries the revocation signature another key made over itself in place of one of its own.
+const foreignRevocationKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdAB9+G6mNJEjmZ//2dwPH673+QvUKGQXBM7EEX
+YSDnv3CIeAQgFggAIBYhBJLVio9JI9kfdMMtukcQKDC+wKmvBQJq0JhOAh0AAAoJ
+EEcQKDC+wKmvOuEBAM1USdBDh7eInRb4s2ejgcOKUY+UFJTKr/P532EPRYI/AQCX
+fGSKVvOVwwxqvZPo/c+mVmhLKqVebv3NMRhgGnM2ArQhUmV2b2tlZCBPd25lciA8
+b3duZXJAZXhhbXBsZS5jb20+iJAEExYIADgWIQRIF7D/ZYRUS+Kf/eVvMD9VvKAv
+oQUCatCYTgIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRBvMD9VvKAvoeXu
+AQCdDHwMl6YWnRgdsLwl4PcC2v/YrcGnUO4TPNNhmAGuVwEAy3S70Ja88O0AupZ3
+kQ5EYYKjLw5X8j4+XbMo7yBbugo=
+=7zqt
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// designatedRevocationKey declares 92D58A8F4923D91F74C32DBA47102830BEC0A9AF as its designated revoker, which revoked it
+// with "gpg --desig-revoke".
+const designatedRevocationKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdAB9+G6mNJEjmZ//2dwPH673+QvUKGQXBM7EEX
+YSDnv3CIkAQfFggAOBYhBEgXsP9lhFRL4p/95W8wP1W8oC+hBQJq0JhWFwyAFpLV
+io9JI9kfdMMtukcQKDC+wKmvAgcAAAoJEG8wP1W8oC+hRhcBAMVMsadSGq+DkUaw
+rXpSTZ8BXDLO8+9w3X86gE7YAhSxAQDA5ek0CmdtQAYIKxsRTDuxflYLrYq71doW
+eRqvuOPcAoh4BCAWCAAgFiEEktWKj0kj2R90wy26RxAoML7Aqa8FAmrQmGcCHQAA
+CgkQRxAoML7Aqa8HsAEAmweMxiDN7LLeJpUCTZEixO6cD5DDOXOgYXcTSNj18CIA
+/R7Qyifv3kEMaLYL3r1EwaI2XyhhM3F2pPobof2r1fIBtCFSZXZva2VkIE93bmVy
+IDxvd25lckBleGFtcGxlLmNvbT6IkAQTFggAOBYhBEgXsP9lhFRL4p/95W8wP1W8
+oC+hBQJq0JhOAhsDBQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEG8wP1W8oC+h
+5e4BAJ0MfAyXphadGB2wvCXg9wLa/9itwadQ7hM802GYAa5XAQDLdLvQlrzw7QC6
+lneRDkRhgqMvDlfyPj5dsyjvIFu6Cg==
+=K9I2
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// revocationRevokerKey is the designated revoker of designatedRevocationKey.
+const revocationRevokerKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdArP/pBQ3tyfWD/3idb0kVNSAZZDKQw2xehuOo
+oISKMmW0KERlc2lnbmF0ZWQgUmV2b2tlciA8cmV2b2tlckBleGFtcGxlLmNvbT6I
+kAQTFggAOBYhBJLVio9JI9kfdMMtukcQKDC+wKmvBQJq0JhOAhsDBQsJCAcCBhUK
+CQgLAgQWAgMBAh4BAheAAAoJEEcQKDC+wKmv/LUBAOCiKvtSdfHgJHRgPMMG9OCl
+da/SebnCBWv5lR8i9vdGAQCLGZL5TCBXhc7lxooZyqJaS+w0dkZt9QB9K0GkR/WT
+CQ==
+=ESEh
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestStripInvalidRevocations(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		invalid []InvalidRevocation
+	}{
+		{
+			name:    "bad signature",
+			key:     badRevocationKey,
+			invalid: []InvalidRevocation{{Target: "the key", Reason: "the signature does not verify: openpgp: invalid signature: EdDSA verification failure"}},
+		},
+		{
+			name:    "valid and bad signature",
+			key:     revokedWithInvalidRevocationKey,
+			invalid: []InvalidRevocation{{Target: "the key", Reason: "the signature does not verify: openpgp: invalid signature: EdDSA verification failure"}},
+		},
+		{
+			name:    "made by another key",
+			key:     foreignRevocationKey,
+			invalid: []InvalidRevocation{{Target: "the key", Reason: "made by the key 47102830BEC0A9AF rather than the key itself"}},
+		},
+		{
+			name: "valid revocation",
+			key:  revokedKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stripped, invalid, err := StripInvalidRevocations([]byte(tt.key))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.invalid, invalid)
+			if tt.invalid == nil {
+				data, err := armor.Unarmor(tt.key)
+				assert.NoError(t, err)
+				assert.Equal(t, data, stripped)
+			}
+		})
+	}
+}
+
+func TestParseKey_InvalidRevocations(t *testing.T) {
+	now := time.Unix(1792060000, 0)
+
+	// The OpenPGP library rejects these keys outright
+	for _, data := range []string{badRevocationKey, foreignRevocationKey} {
+		key, err := ParseKey(data)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "4817b0ff6584544be29ffde56f303f55bca02fa1", key.GetFingerprint())
+			assert.False(t, IsRevoked(key, now), "an invalid revocation must not revoke the key")
+		}
+	}
+
+	// The valid revocation is not hidden by the invalid one
+	key, err := ParseKey(revokedWithInvalidRevocationKey)
+	if assert.NoError(t, err) {
+		assert.True(t, IsRevoked(key, now))
+		assert.Equal(t, []Revocation{{Reason: "no reason specified"}}, Revocations(key, now))
+	}
+}
+
+func TestDesignatedRevocations(t *testing.T) {
+	now := time.Unix(1792060000, 0)
+
+	// The revocation is left out of the parsed key, it is only in effect once verified with the revoker's key
+	key, err := ParseKey(designatedRevocationKey)
+	if assert.NoError(t, err) {
+		assert.False(t, IsRevoked(key, now))
+	}
+	invalid, err := InvalidRevocations([]byte(designatedRevocationKey))
+	assert.NoError(t, err)
+	assert.Empty(t, invalid)
+
+	designated, err := DesignatedRevocations([]byte(designatedRevocationKey))
+	if !assert.NoError(t, err) || !assert.Len(t, designated, 1) {
+		return
+	}
+	revocation := designated[0]
+	assert.Equal(t, "92D58A8F4923D91F74C32DBA47102830BEC0A9AF", revocation.Revoker)
+	assert.Equal(t, Revocation{Reason: "no reason specified"}, revocation.Revocation)
+	assert.True(t, revocation.InEffect(now))
+
+	revoker, err := ParseKey(revocationRevokerKey)
+	if assert.NoError(t, err) {
+		assert.NoError(t, revocation.Verify(revoker))
+	}
+	assert.EqualError(t, revocation.Verify(key), "the key 4817B0FF6584544BE29FFDE56F303F55BCA02FA1 is not the designated revoker 92D58A8F4923D91F74C32DBA47102830BEC0A9AF")
+
+	// A key without designated revokers has no such revocations
+	designated, err = DesignatedRevocations([]byte(revokedKey))
+	assert.NoError(t, err)
+	assert.Empty(t, designated)
+}
@@ -1,6 +1,7 @@
 package gpg
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -41,10 +42,70 @@ func buildKey(path string) (*Key, error) {
 
 // ParseKey parses a GPG key from ascii armor.
 func ParseKey(data string) (*crypto.Key, error) {
+	if key, err := parseRepaired([]byte(data)); key != nil || err != nil {
+		return key, err
+	}
+
 	key, err := crypto.NewKeyFromArmored(data)
 	if err != nil {
+		if versionErr := checkKeyVersion([]byte(data)); versionErr != nil {
+			return nil, versionErr
+		}
+		if crossErr := checkSubkeyCrossCertifications([]byte(data)); crossErr != nil {
+			return nil, crossErr
+		}
 		return nil, fmt.Errorf("could not build public key from ascii armor: %w", err)
 	}
 
 	return key, nil
 }
+
+// ParseKeyBytes parses a GPG key from either ascii armor or its binary representation.
+func ParseKeyBytes(data []byte) (*crypto.Key, error) {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		return ParseKey(string(data))
+	}
+
+	if key, err := parseRepaired(data); key != nil || err != nil {
+		return key, err
+	}
+
+	key, err := crypto.NewKey(data)
+	if err != nil {
+		if versionErr := checkKeyVersion(data); versionErr != nil {
+			return nil, versionErr
+		}
+		if crossErr := checkSubkeyCrossCertifications(data); crossErr != nil {
+			return nil, crossErr
+		}
+		return nil, fmt.Errorf("could not build public key from binary data: %w", err)
+	}
+
+	return key, nil
+}
+
+// parseRepaired parses a key the OpenPGP library would reject as is, after putting its packets in the order the library
+// expects and removing the revocation signatures it cannot verify, see StripInvalidRevocations. It returns neither a
+// key nor an error if there is nothing to repair or the packets cannot be read, leaving the key to be parsed as is.
+func parseRepaired(data []byte) (*crypto.Key, error) {
+	normalized, issues, err := NormalizePacketOrder(data)
+	if err != nil {
+		return nil, nil
+	}
+	stripped, invalid, designated, err := stripRevocations(normalized)
+	if err != nil || (len(issues) == 0 && len(invalid) == 0 && len(designated) == 0) {
+		return nil, nil
+	}
+
+	key, err := crypto.NewKey(stripped)
+	if err != nil {
+		if crossErr := checkSubkeyCrossCertifications(stripped); crossErr != nil {
+			return nil, crossErr
+		}
+		if len(issues) > 0 {
+			return nil, fmt.Errorf("could not build public key, its packets are in an unusual order (%s): %w", strings.Join(issues, ", "), err)
+		}
+		return nil, fmt.Errorf("could not build public key without its unverifiable revocation signatures: %w", err)
+	}
+	return key, nil
+}
@@ -84,3 +84,45 @@ func TestParseKey(t *testing.T) {
 		})
 	}
 }
+
+func TestParseKeyBytes(t *testing.T) {
+	publicGPGKey, _ := generateGPGKey()
+
+	key, err := ParseKey(publicGPGKey)
+	assert.NoError(t, err)
+	binaryKey, err := key.GetPublicKey()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}{
+		{
+			name: "armored key should succeed",
+			data: []byte(publicGPGKey),
+		},
+		{
+			name: "binary key should succeed",
+			data: binaryKey,
+		},
+		{
+			name:        "garbage should fail",
+			data:        []byte("not a key"),
+			expectedErr: "could not build public key from binary data",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := ParseKeyBytes(test.data)
+
+			if test.expectedErr != "" {
+				assert.ErrorContains(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, key.GetFingerprint(), parsed.GetFingerprint())
+			}
+		})
+	}
+}
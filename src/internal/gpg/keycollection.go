@@ -1,10 +1,14 @@
 package gpg
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/opentofu/registry-stable/internal/files"
 )
 
 // KeyCollection represents the GPG keys stored in the registry for a specific namespace and provider.
@@ -63,3 +67,77 @@ func (k KeyCollection) listKeysIn(location string) ([]Key, error) {
 
 	return keys, nil
 }
+
+// AddKey writes the ascii armored key into the collection as provider-<unix timestamp>.asc, the conventional file
+// name used for submitted keys. If the collection already holds the same key, nothing is written and the location of
+// the existing key is returned with exists set. A different key with the same fingerprint is rejected rather than
+// overwritten.
+func (k KeyCollection) AddKey(asciiArmor string, now time.Time) (location string, exists bool, err error) {
+	key, err := ParseKey(asciiArmor)
+	if err != nil {
+		return "", false, fmt.Errorf("could not parse key: %w", err)
+	}
+	keyData, err := key.GetPublicKey()
+	if err != nil {
+		return "", false, fmt.Errorf("could not serialize key: %w", err)
+	}
+
+	for _, dir := range []string{k.NamespacePath(), k.ProviderPath()} {
+		existingPath, existingData, err := findKeyByFingerprint(dir, key.GetFingerprint())
+		if err != nil {
+			return "", false, err
+		}
+		if existingPath == "" {
+			continue
+		}
+		if !bytes.Equal(existingData, keyData) {
+			return "", false, fmt.Errorf("a different key with fingerprint %s already exists at %s", strings.ToUpper(key.GetFingerprint()), existingPath)
+		}
+		return existingPath, true, nil
+	}
+
+	location = filepath.Join(k.ProviderPath(), fmt.Sprintf("provider-%d.asc", now.Unix()))
+	if _, err := os.Stat(location); err == nil {
+		return "", false, fmt.Errorf("refusing to overwrite existing key file %s", location)
+	}
+	err = files.SafeWriteFile(location, []byte(asciiArmor))
+	if err != nil {
+		return "", false, err
+	}
+	return location, false, nil
+}
+
+// findKeyByFingerprint returns the path and serialized public key of the key with the given fingerprint in the
+// directory, or an empty path if there is none.
+func findKeyByFingerprint(location string, fingerprint string) (string, []byte, error) {
+	entries, err := os.ReadDir(location)
+	if os.IsNotExist(err) {
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("error reading directory %s: %w", location, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		keyPath := filepath.Join(location, entry.Name())
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read key file %s: %w", keyPath, err)
+		}
+		key, err := ParseKey(string(data))
+		if err != nil {
+			return "", nil, fmt.Errorf("error building key at %s: %w", keyPath, err)
+		}
+		if !strings.EqualFold(key.GetFingerprint(), fingerprint) {
+			continue
+		}
+		publicKey, err := key.GetPublicKey()
+		if err != nil {
+			return "", nil, fmt.Errorf("could not serialize key at %s: %w", keyPath, err)
+		}
+		return keyPath, publicKey, nil
+	}
+	return "", nil, nil
+}
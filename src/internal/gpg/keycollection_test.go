@@ -0,0 +1,87 @@
+package gpg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyCollection_AddKey(t *testing.T) {
+	privateKey, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Adding a subkey keeps the fingerprint of the primary key but changes the key
+	err = privateKey.GetEntity().AddSigningSubkey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+
+	t.Run("new key", func(t *testing.T) {
+		collection := KeyCollection{Namespace: "Example", ProviderName: "test", Directory: t.TempDir()}
+
+		location, exists, err := collection.AddKey(original, now)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, filepath.Join(collection.Directory, "e", "Example", "test", "provider-1700000000.asc"), location)
+
+		written, err := os.ReadFile(location)
+		assert.NoError(t, err)
+		assert.Equal(t, original, string(written))
+
+		keys, err := collection.ListKeys()
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+
+	t.Run("same key already present", func(t *testing.T) {
+		collection := KeyCollection{Namespace: "example", Directory: t.TempDir()}
+		first, _, err := collection.AddKey(original, now)
+		assert.NoError(t, err)
+
+		location, exists, err := collection.AddKey(original, now.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, first, location)
+	})
+
+	t.Run("same key present for the namespace", func(t *testing.T) {
+		dir := t.TempDir()
+		first, _, err := KeyCollection{Namespace: "example", Directory: dir}.AddKey(original, now)
+		assert.NoError(t, err)
+
+		location, exists, err := KeyCollection{Namespace: "example", ProviderName: "test", Directory: dir}.AddKey(original, now)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, first, location)
+	})
+
+	t.Run("different key with the same fingerprint", func(t *testing.T) {
+		collection := KeyCollection{Namespace: "example", Directory: t.TempDir()}
+		_, _, err := collection.AddKey(original, now)
+		assert.NoError(t, err)
+
+		_, _, err = collection.AddKey(modified, now.Add(time.Hour))
+		assert.ErrorContains(t, err, "a different key with fingerprint")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		collection := KeyCollection{Namespace: "example", Directory: t.TempDir()}
+		_, _, err := collection.AddKey("not a key", now)
+		assert.ErrorContains(t, err, "could not parse key")
+	})
+}
@@ -0,0 +1,79 @@
+package gpg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// keybaseProfileRegex matches links to Keybase profiles, which Keybase users tend to add as a user ID.
+var keybaseProfileRegex = regexp.MustCompile(`(?i)\b(?:https?://)?keybase\.io/([a-z0-9_]+)`)
+
+// Notation is a notation data subpacket on the self-signature of a user ID. Key owners use notations to annotate their
+// user IDs, e.g. with identity proofs such as the "proof@ariadne.id" notations of Keyoxide.
+type Notation struct {
+	UserID        string
+	Name          string
+	Value         string // Hex encoded, unless the notation is flagged as human readable
+	HumanReadable bool
+}
+
+func (n Notation) String() string {
+	return fmt.Sprintf("%s=%s", n.Name, n.Value)
+}
+
+// Notations returns the notations on the self-signatures of the key's user IDs, sorted by user ID and in the order
+// they appear in each signature.
+func Notations(key *crypto.Key) []Notation {
+	var result []Notation
+	for name, identity := range key.GetEntity().Identities {
+		if identity.SelfSignature == nil {
+			continue
+		}
+		for _, n := range identity.SelfSignature.Notations {
+			value := string(n.Value)
+			if !n.IsHumanReadable {
+				value = hex.EncodeToString(n.Value)
+			}
+			result = append(result, Notation{UserID: name, Name: n.Name, Value: value, HumanReadable: n.IsHumanReadable})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].UserID < result[j].UserID
+	})
+	return result
+}
+
+// IdentityProofs returns links to the identity proofs of the key, sorted and without duplicates: the values of proof
+// notations and the Keybase profiles mentioned in its user IDs. The proofs are not verified, they only point reviewers
+// to where the key owner claims their identity can be confirmed.
+func IdentityProofs(key *crypto.Key) []string {
+	proofs := make(map[string]bool)
+	for _, n := range Notations(key) {
+		if n.HumanReadable && strings.HasPrefix(n.Name, "proof@") {
+			proofs[n.Value] = true
+		}
+	}
+	for name := range key.GetEntity().Identities {
+		for _, match := range keybaseProfileRegex.FindAllStringSubmatch(name, -1) {
+			proofs["https://keybase.io/"+strings.ToLower(match[1])] = true
+		}
+		if address, err := mail.ParseAddress(name); err == nil {
+			if user, domain, ok := strings.Cut(address.Address, "@"); ok && strings.EqualFold(domain, "keybase.io") {
+				proofs["https://keybase.io/"+strings.ToLower(user)] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(proofs))
+	for proof := range proofs {
+		result = append(result, proof)
+	}
+	sort.Strings(result)
+	return result
+}
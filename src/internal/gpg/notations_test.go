@@ -0,0 +1,58 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keybaseKey was generated with gpg. Its second user ID "keybase.io/janedoe <janedoe@keybase.io>" was added with
+// --cert-notation proof@ariadne.id=https://keybase.io/janedoe.
+const keybaseKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCLERYJKwYBBAHaRw8BAQdA7pAB3BqZ6CmMAJSBqLrp9DYkXGumXGs4t6uC
+tkcI7SC0G0phbmUgRG9lIDxqYW5lQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEbTEL
+mvL4brqaSZSQGof6GxbUnewFAmrQixECGwMFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQGof6GxbUnewkHQEA/KKg8E4XB5WzEBe6XT6aEHV3ElHsEgPLADcV4WWW
+UUQBAMAOtc1MzQiOSQsD5HTkSH2M6XFqGtYWoex2snZbWIQMtCdrZXliYXNlLmlv
+L2phbmVkb2UgPGphbmVkb2VAa2V5YmFzZS5pbz6IxAQTFggAbBYhBG0xC5ry+G66
+mkmUkBqH+hsW1J3sBQJq0IsRMxSAAAAAABAAGnByb29mQGFyaWFkbmUuaWRodHRw
+czovL2tleWJhc2UuaW8vamFuZWRvZQIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRAah/obFtSd7NXUAP9+9/kIEUbd/ZPXblk2eZhMlSKR4+AVgBoYaBh444gr
+TQD9HVWUByaorXy6HDsPmp+Ed4iiXMCejOtPtqS7rryp+Qg=
+=8UUM
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestNotations(t *testing.T) {
+	key, err := ParseKey(keybaseKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Notation{{
+		UserID:        "keybase.io/janedoe <janedoe@keybase.io>",
+		Name:          "proof@ariadne.id",
+		Value:         "https://keybase.io/janedoe",
+		HumanReadable: true,
+	}}, Notations(key))
+
+	plain, err := ParseKey(twoUIDsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, Notations(plain))
+}
+
+func TestIdentityProofs(t *testing.T) {
+	key, err := ParseKey(keybaseKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The notation, the user ID and its email address all point to the same profile
+	assert.Equal(t, []string{"https://keybase.io/janedoe"}, IdentityProofs(key))
+
+	plain, err := ParseKey(twoUIDsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, IdentityProofs(plain))
+}
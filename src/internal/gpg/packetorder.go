@@ -0,0 +1,162 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+)
+
+// OpenPGP packet tags and signature types used to put the packets of a key back in order (RFC 4880, sections 4.3,
+// 5.2.1 and 11.1).
+const (
+	packetTagSecretSubkey         = 7
+	packetTagTrust                = 12
+	packetTagUserID               = 13
+	signatureTypeSubkeyBinding    = 0x18
+	signatureTypeKeyRevocation    = 0x20
+	signatureTypeSubkeyRevocation = 0x28
+	signatureTypeCertRevocation   = 0x30
+)
+
+// signatureTypeUnknown is returned by signatureType for signatures whose type cannot be read.
+const signatureTypeUnknown = -1
+
+// keyComponent is a user ID, user attribute or subkey packet with the signatures that belong to it.
+type keyComponent struct {
+	packets [][]byte
+}
+
+// NormalizePacketOrder puts the packets of the first key in data, given as ascii armor or in its binary form, in the
+// order of RFC 4880, section 11.1, and returns it in its binary form with a description of each unusual structure that
+// was found. Keys produced by older tools may place signatures before the user ID or subkey they belong to, or contain
+// keyring trust packets, which the OpenPGP library either rejects or silently drops. Signatures are attached to
+// components by their type only, the library still verifies every one of them when the normalized key is parsed.
+// Signatures that are not followed by a component they can belong to are dropped. The data is returned unchanged, with
+// no issues, if the packets are already in order.
+func NormalizePacketOrder(data []byte) ([]byte, []string, error) {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		unarmored, err := armor.Unarmor(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not unarmor key: %w", err)
+		}
+		data = unarmored
+	}
+
+	tag, _, rest, err := readPacket(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != packetTagPublicKey && tag != packetTagSecretKey {
+		return nil, nil, fmt.Errorf("first packet is not a key but has tag %d", tag)
+	}
+
+	var issues []string
+	issue := func(description string) {
+		for _, existing := range issues {
+			if existing == description {
+				return
+			}
+		}
+		issues = append(issues, description)
+	}
+
+	primary := &keyComponent{packets: [][]byte{data[:len(data)-len(rest)]}}
+	var identities, subkeys []*keyComponent
+	var pendingCerts, pendingBindings [][]byte
+	current := primary
+	for len(rest) > 0 {
+		tag, contents, next, err := readPacket(rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		packet := rest[:len(rest)-len(next)]
+		if tag == packetTagPublicKey || tag == packetTagSecretKey {
+			// The next key of a keyring starts here
+			break
+		}
+		rest = next
+
+		switch tag {
+		case packetTagTrust:
+			issue("the key contains keyring trust packets")
+		case packetTagUserID, packetTagUserAttribute:
+			if len(subkeys) > 0 {
+				issue("a user ID follows a subkey")
+			}
+			current = &keyComponent{packets: append([][]byte{packet}, pendingCerts...)}
+			pendingCerts = nil
+			identities = append(identities, current)
+		case packetTagPublicSubkey, packetTagSecretSubkey:
+			current = &keyComponent{packets: append([][]byte{packet}, pendingBindings...)}
+			pendingBindings = nil
+			subkeys = append(subkeys, current)
+		case packetTagSignature:
+			switch sigType := signatureType(contents); {
+			case sigType == signatureTypeDirectKey || sigType == signatureTypeKeyRevocation:
+				if current != primary {
+					issue("a direct key signature follows a user ID or subkey")
+				}
+				primary.packets = append(primary.packets, packet)
+			case sigType >= signatureTypeCertGeneric && sigType <= signatureTypeCertPositive || sigType == signatureTypeCertRevocation:
+				switch {
+				case current == primary:
+					issue("a user ID certification precedes its user ID")
+					pendingCerts = append(pendingCerts, packet)
+				case len(subkeys) > 0 && current == subkeys[len(subkeys)-1]:
+					issue("a user ID certification follows a subkey")
+					if len(identities) == 0 {
+						pendingCerts = append(pendingCerts, packet)
+					} else {
+						last := identities[len(identities)-1]
+						last.packets = append(last.packets, packet)
+					}
+				default:
+					current.packets = append(current.packets, packet)
+				}
+			case sigType == signatureTypeSubkeyBinding || sigType == signatureTypeSubkeyRevocation:
+				if len(subkeys) == 0 || current != subkeys[len(subkeys)-1] {
+					issue("a subkey binding signature precedes its subkey")
+					pendingBindings = append(pendingBindings, packet)
+				} else {
+					current.packets = append(current.packets, packet)
+				}
+			default:
+				current.packets = append(current.packets, packet)
+			}
+		default:
+			current.packets = append(current.packets, packet)
+		}
+	}
+	if len(pendingCerts) > 0 {
+		issue("a user ID certification is not followed by a user ID")
+	}
+	if len(pendingBindings) > 0 {
+		issue("a subkey binding signature is not followed by a subkey")
+	}
+	if len(issues) == 0 {
+		return data, nil, nil
+	}
+
+	var normalized bytes.Buffer
+	for _, component := range append(append([]*keyComponent{primary}, identities...), subkeys...) {
+		for _, packet := range component.packets {
+			normalized.Write(packet)
+		}
+	}
+	normalized.Write(rest)
+	return normalized.Bytes(), issues, nil
+}
+
+// signatureType returns the type of a version 3, 4 or 5 signature packet, or signatureTypeUnknown if it cannot be read.
+func signatureType(sig []byte) int {
+	switch {
+	case len(sig) > 2 && sig[0] == 3:
+		// version (1), length of the hashed material (1), signature type (1)
+		return int(sig[2])
+	case len(sig) > 1 && (sig[0] == 4 || sig[0] == 5):
+		return int(sig[1])
+	default:
+		return signatureTypeUnknown
+	}
+}
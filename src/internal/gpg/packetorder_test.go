@@ -0,0 +1,73 @@
+package gpg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyLayoutKey has the packet layout of keys exported by older tools or copied out of an old keyring: the user ID
+// self-signature precedes the user ID, a keyring trust packet follows it and the subkey binding signature precedes
+// the subkey.
+const legacyLayoutKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCU2hYJKwYBBAHaRw8BAQdAjrsFaftHeiZrDs3ioqZRgtTYpcHq3IXk4Emg
+a0qzykKIkAQTFggAOBYhBGTt1ddrSFRraYsTNDrdRmBNWjH1BQJq0JTaAhsDBQsJ
+CAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEDrdRmBNWjH124wA/igwuRpPPjW+7Lgi
+XKMWcz2tbyEePHOvwBkplBsj+Bp+AP0eiIGQS0tVf6SwwqGM9KqV+/tdjBRtqzpa
+RrqQ5CILBrQiTGVnYWN5IExheW91dCA8bGVnYWN5QGV4YW1wbGUuY29tPrACAACI
+eAQYFggAIBYhBGTt1ddrSFRraYsTNDrdRmBNWjH1BQJq0JTaAhsMAAoJEDrdRmBN
+WjH1Zu4BAP0k4jh1/WVbBm+7GBueEgCzjGNoEcFWCiXPpeJzLPGCAP47BKnxFF+b
+khmzNu57vFOm+QciNNqeFc2qJR2t05X3Brg4BGrQlNoSCisGAQQBl1UBBQEBB0Bj
+AxenNtUFhwEs4M8HNuOUs8zqyUMcEhmfiTDziaLtSQMBCAc=
+=w41x
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestNormalizePacketOrder(t *testing.T) {
+	// The OpenPGP library takes the misplaced subkey binding signature for a user ID signature and rejects the key
+	_, err := crypto.NewKeyFromArmored(legacyLayoutKey)
+	assert.ErrorContains(t, err, "user ID signature with wrong type")
+
+	normalized, issues, err := NormalizePacketOrder([]byte(legacyLayoutKey))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"a user ID certification precedes its user ID",
+		"the key contains keyring trust packets",
+		"a subkey binding signature precedes its subkey",
+	}, issues)
+
+	key, err := crypto.NewKey(normalized)
+	assert.NoError(t, err)
+	assert.Equal(t, "64edd5d76b48546b698b13343add46604d5a31f5", key.GetFingerprint())
+	assert.Contains(t, key.GetEntity().Identities, "Legacy Layout <legacy@example.com>")
+	assert.Len(t, key.GetEntity().Subkeys, 1)
+
+	// A key in order is returned unchanged
+	binaryKey, err := key.GetPublicKey()
+	assert.NoError(t, err)
+	unchanged, issues, err := NormalizePacketOrder(binaryKey)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+	assert.Equal(t, binaryKey, unchanged)
+}
+
+func TestNormalizePacketOrder_NotAKey(t *testing.T) {
+	_, _, err := NormalizePacketOrder([]byte{0x88, 0x01, 0x04})
+	assert.EqualError(t, err, "first packet is not a key but has tag 2")
+}
+
+func TestParseKey_UnusualPacketOrder(t *testing.T) {
+	key, err := ParseKey(legacyLayoutKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "3add46604d5a31f5", key.GetHexKeyID())
+
+	// Misplaced signatures are still verified
+	data, err := armor.Unarmor(legacyLayoutKey)
+	assert.NoError(t, err)
+	tampered := bytes.Replace(data, []byte("Legacy Layout"), []byte("Lunacy Layout"), 1)
+	_, err = ParseKeyBytes(tampered)
+	assert.ErrorContains(t, err, "its packets are in an unusual order (a user ID certification precedes its user ID")
+}
@@ -0,0 +1,43 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+)
+
+// packetTagUserAttribute is the tag of a user attribute packet, which holds a photo user ID (RFC 4880, section 5.12).
+const packetTagUserAttribute = 17
+
+// PhotoUserIDs returns the number of photo user IDs of the key, given the key as ascii armor or in its binary form.
+// Photo user IDs are user attribute packets, which the underlying OpenPGP library skips together with their
+// certifications, so they never show up among the identities of the parsed key. Only the first key of data is
+// inspected.
+func PhotoUserIDs(data []byte) (int, error) {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		unarmored, err := armor.Unarmor(string(data))
+		if err != nil {
+			return 0, fmt.Errorf("could not unarmor key: %w", err)
+		}
+		data = unarmored
+	}
+
+	photos := 0
+	for first := true; len(data) > 0; first = false {
+		tag, _, rest, err := readPacket(data)
+		if err != nil {
+			return 0, err
+		}
+		data = rest
+
+		if !first && (tag == packetTagPublicKey || tag == packetTagSecretKey) {
+			// The next key begins
+			break
+		}
+		if tag == packetTagUserAttribute {
+			photos++
+		}
+	}
+	return photos, nil
+}
@@ -0,0 +1,45 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// photoKey was generated with gpg, its second user ID is a 1x1 pixel JPEG added with addphoto.
+const photoKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCQZRYJKwYBBAHaRw8BAQdANsiQ4UuaEiZcBAqMlhGHQmJA4MFoEKg6JmIW
+GWZEux+0G0phbmUgRG9lIDxqYW5lQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEKMTx
+IQPPQ4LBIkR9Y6LrjZVHWr8FAmrQkGUCGwMFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQY6LrjZVHWr+UyQEAkNJLN+rLzYwOBOfEitkmewwHGIkDsNEKjX7ABZzf
+oIUA/1tkVAr6Iq+QXbk9x7LHAR6TwuUR7Ne0tmqg8aG5Hg8D0ZiXARAAAQEAAAAA
+AAAAAAAAAAD/2P/gABBKRklGAAEBAQBIAEgAAP/bAEMA////////////////////
+////////////////////////////////////////////////////////////////
+///CAAsIAAEAAQEBEQD/xAAUEAEAAAAAAAAAAAAAAAAAAAAA/9oACAEBAAE/EIiQ
+BBMWCAA4FiEEKMTxIQPPQ4LBIkR9Y6LrjZVHWr8FAmrQkGUCGwMFCwkIBwIGFQoJ
+CAsCBBYCAwECHgECF4AACgkQY6LrjZVHWr+NLQEA9mj3t4TRxRr+rkxmhalML0PF
+kXtZBD0EIONRGVQBAW0A/039JeCtrNYFIA8uWLCQznFk+jUgEfdraxEQgCN8zGIF
+=39Yy
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestPhotoUserIDs(t *testing.T) {
+	photos, err := PhotoUserIDs([]byte(photoKey))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, photos)
+
+	// The photo is not mistaken for a text user ID
+	key, err := ParseKey(photoKey)
+	assert.NoError(t, err)
+	assert.Len(t, key.GetEntity().Identities, 1)
+	assert.Contains(t, key.GetEntity().Identities, "Jane Doe <jane@example.com>")
+
+	generated, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	assert.NoError(t, err)
+	armored, err := generated.GetArmoredPublicKey()
+	assert.NoError(t, err)
+	photos, err = PhotoUserIDs([]byte(armored))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, photos)
+}
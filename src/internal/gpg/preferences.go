@@ -0,0 +1,83 @@
+package gpg
+
+import (
+	"fmt"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// hashAlgorithmSHA1 is the OpenPGP identifier for SHA-1 (RFC 4880, section 9.4).
+const hashAlgorithmSHA1 = 2
+
+// hashAlgorithmNames maps OpenPGP hash algorithm identifiers to their names (RFC 4880, section 9.4).
+var hashAlgorithmNames = map[uint8]string{
+	1:  "MD5",
+	2:  "SHA1",
+	3:  "RIPEMD160",
+	8:  "SHA256",
+	9:  "SHA384",
+	10: "SHA512",
+	11: "SHA224",
+	12: "SHA3-256",
+	14: "SHA3-512",
+}
+
+// symmetricAlgorithmNames maps OpenPGP symmetric algorithm identifiers to their names (RFC 4880, section 9.2).
+var symmetricAlgorithmNames = map[uint8]string{
+	1:  "IDEA",
+	2:  "TripleDES",
+	3:  "CAST5",
+	4:  "Blowfish",
+	7:  "AES128",
+	8:  "AES192",
+	9:  "AES256",
+	10: "Twofish",
+	11: "Camellia128",
+	12: "Camellia192",
+	13: "Camellia256",
+}
+
+// Preferences contains the algorithm preferences declared in the self-signature of the key's primary identity.
+type Preferences struct {
+	Hash      []uint8 // Preferred hash algorithms, in order of preference
+	Symmetric []uint8 // Preferred symmetric algorithms, in order of preference
+}
+
+// KeyPreferences returns the algorithm preferences declared by the key.
+func KeyPreferences(key *crypto.Key) Preferences {
+	identity := key.GetEntity().PrimaryIdentity()
+	if identity == nil || identity.SelfSignature == nil {
+		return Preferences{}
+	}
+	return Preferences{
+		Hash:      identity.SelfSignature.PreferredHash,
+		Symmetric: identity.SelfSignature.PreferredSymmetric,
+	}
+}
+
+// PrefersSHA1 returns true if SHA-1 is the key's most preferred (or only) hash algorithm.
+func (p Preferences) PrefersSHA1() bool {
+	return len(p.Hash) > 0 && p.Hash[0] == hashAlgorithmSHA1
+}
+
+// HashNames returns the human-readable names of the preferred hash algorithms.
+func (p Preferences) HashNames() []string {
+	return algorithmNames(p.Hash, hashAlgorithmNames)
+}
+
+// SymmetricNames returns the human-readable names of the preferred symmetric algorithms.
+func (p Preferences) SymmetricNames() []string {
+	return algorithmNames(p.Symmetric, symmetricAlgorithmNames)
+}
+
+func algorithmNames(ids []uint8, names map[uint8]string) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name, ok := names[id]
+		if !ok {
+			name = fmt.Sprintf("unknown (%d)", id)
+		}
+		result = append(result, name)
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferences(t *testing.T) {
+	tests := []struct {
+		name              string
+		prefs             Preferences
+		prefersSHA1       bool
+		expectedHash      []string
+		expectedSymmetric []string
+	}{
+		{
+			name:              "modern preferences",
+			prefs:             Preferences{Hash: []uint8{10, 8}, Symmetric: []uint8{9, 7}},
+			prefersSHA1:       false,
+			expectedHash:      []string{"SHA512", "SHA256"},
+			expectedSymmetric: []string{"AES256", "AES128"},
+		},
+		{
+			name:              "sha1 first",
+			prefs:             Preferences{Hash: []uint8{2, 8}, Symmetric: []uint8{3}},
+			prefersSHA1:       true,
+			expectedHash:      []string{"SHA1", "SHA256"},
+			expectedSymmetric: []string{"CAST5"},
+		},
+		{
+			name:              "unknown algorithm",
+			prefs:             Preferences{Hash: []uint8{100}},
+			prefersSHA1:       false,
+			expectedHash:      []string{"unknown (100)"},
+			expectedSymmetric: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.prefersSHA1, test.prefs.PrefersSHA1())
+			assert.Equal(t, test.expectedHash, test.prefs.HashNames())
+			assert.Equal(t, test.expectedSymmetric, test.prefs.SymmetricNames())
+		})
+	}
+}
+
+func TestKeyPreferences(t *testing.T) {
+	publicGPGKey, _ := generateGPGKey()
+	key, err := ParseKey(publicGPGKey)
+	assert.NoError(t, err)
+
+	prefs := KeyPreferences(key)
+	assert.NotEmpty(t, prefs.Hash)
+	assert.False(t, prefs.PrefersSHA1())
+}
@@ -0,0 +1,80 @@
+package gpg
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+var revocationReasonNames = map[packet.ReasonForRevocation]string{
+	packet.NoReason:       "no reason specified",
+	packet.KeySuperseded:  "key superseded",
+	packet.KeyCompromised: "key compromised",
+	packet.KeyRetired:     "key no longer used",
+	32:                    "user ID no longer valid",
+}
+
+// Revocation is a revocation signature in effect on the key or its primary user ID.
+type Revocation struct {
+	UserID string // The revoked user ID, empty if the key itself is revoked
+	Reason string // The reason code of the revocation signature, e.g. "key compromised"
+	Text   string // The explanation given by the key owner, may be empty
+}
+
+// Superseded reports whether the key was revoked because it was replaced by a new key, which is a benign reason.
+func (r Revocation) Superseded() bool {
+	return r.Reason == revocationReasonNames[packet.KeySuperseded]
+}
+
+func (r Revocation) String() string {
+	result := r.Reason
+	if r.Text != "" {
+		result += fmt.Sprintf(" (%q)", r.Text)
+	}
+	if r.UserID != "" {
+		result = fmt.Sprintf("user ID %s: %s", r.UserID, result)
+	}
+	return result
+}
+
+// Revocations returns the revocations of the key and of its primary user ID that are in effect at now. As in the
+// OpenPGP library, a revocation because of a compromised key is in effect regardless of its creation time.
+func Revocations(key *crypto.Key, now time.Time) []Revocation {
+	entity := key.GetEntity()
+
+	var result []Revocation
+	for _, sig := range entity.Revocations {
+		if revocationInEffect(sig, now) {
+			result = append(result, revocation("", sig))
+		}
+	}
+	if identity := entity.PrimaryIdentity(); identity != nil {
+		for _, sig := range identity.Revocations {
+			if revocationInEffect(sig, now) {
+				result = append(result, revocation(identity.Name, sig))
+			}
+		}
+	}
+	return result
+}
+
+func revocationInEffect(sig *packet.Signature, now time.Time) bool {
+	if sig.RevocationReason != nil && *sig.RevocationReason == packet.KeyCompromised {
+		return true
+	}
+	return !sig.SigExpired(now)
+}
+
+func revocation(userID string, sig *packet.Signature) Revocation {
+	r := Revocation{UserID: userID, Reason: "no reason given", Text: sig.RevocationReasonText}
+	if sig.RevocationReason != nil {
+		if name, ok := revocationReasonNames[*sig.RevocationReason]; ok {
+			r.Reason = name
+		} else {
+			r.Reason = fmt.Sprintf("reason %d", *sig.RevocationReason)
+		}
+	}
+	return r
+}
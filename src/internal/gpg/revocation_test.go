@@ -0,0 +1,73 @@
+package gpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocations(t *testing.T) {
+	tests := []struct {
+		name       string
+		reason     packet.ReasonForRevocation
+		text       string
+		expected   string
+		superseded bool
+	}{
+		{
+			name:       "superseded",
+			reason:     packet.KeySuperseded,
+			text:       "replaced by a new key",
+			expected:   `key superseded ("replaced by a new key")`,
+			superseded: true,
+		},
+		{
+			name:     "compromised",
+			reason:   packet.KeyCompromised,
+			expected: "key compromised",
+		},
+		{
+			name:     "unknown reason",
+			reason:   99,
+			expected: "reason 99",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := key.GetEntity().RevokeKey(tt.reason, tt.text, nil); err != nil {
+				t.Fatal(err)
+			}
+			armored, err := key.GetArmoredPublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseKey(armored)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			revocations := Revocations(parsed, time.Now().Add(time.Hour))
+			if assert.Len(t, revocations, 1) {
+				assert.Equal(t, tt.expected, revocations[0].String())
+				assert.Equal(t, tt.superseded, revocations[0].Superseded())
+			}
+			assert.True(t, IsRevoked(parsed, time.Now().Add(time.Hour)))
+		})
+	}
+}
+
+func TestRevocations_GPG(t *testing.T) {
+	key, err := ParseKey(revokedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Revocation{{Reason: "no reason specified"}}, Revocations(key, time.Unix(1792050461, 0)))
+}
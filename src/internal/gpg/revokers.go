@@ -0,0 +1,182 @@
+package gpg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+)
+
+// OpenPGP packet tags, signature types and subpacket types used to find designated revokers (RFC 4880, sections 4.3,
+// 5.2.1 and 5.2.3.1).
+const (
+	packetTagSignature        = 2
+	packetTagPublicSubkey     = 14
+	signatureTypeCertGeneric  = 0x10
+	signatureTypeCertPositive = 0x13
+	signatureTypeDirectKey    = 0x1F
+	subpacketRevocationKey    = 12
+)
+
+// DesignatedRevoker is a key that the key owner has authorized to revoke their key (RFC 4880, section 5.2.3.15).
+type DesignatedRevoker struct {
+	Fingerprint string // The upper case hex fingerprint of the revoking key
+	Sensitive   bool   // Whether the designation should not be exported to others
+}
+
+// DesignatedRevokers returns the designated revokers declared in the direct key and user ID signatures of the primary
+// key, given the key as ascii armor or in its binary form. The underlying OpenPGP library drops direct key signatures
+// and does not parse the revocation key subpacket, so the original key data is inspected directly. The signatures
+// themselves are not verified, the result is informational only.
+func DesignatedRevokers(data []byte) ([]DesignatedRevoker, error) {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		unarmored, err := armor.Unarmor(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("could not unarmor key: %w", err)
+		}
+		data = unarmored
+	}
+
+	var revokers []DesignatedRevoker
+	seen := make(map[string]bool)
+	for len(data) > 0 {
+		tag, contents, rest, err := readPacket(data)
+		if err != nil {
+			return nil, err
+		}
+		data = rest
+
+		if tag == packetTagPublicSubkey {
+			// Subkey binding signatures cannot designate revokers for the primary key
+			break
+		}
+		if tag != packetTagSignature {
+			continue
+		}
+
+		found, err := signatureRevokers(contents)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range found {
+			if !seen[r.Fingerprint] {
+				seen[r.Fingerprint] = true
+				revokers = append(revokers, r)
+			}
+		}
+	}
+	return revokers, nil
+}
+
+// readPacket splits the first OpenPGP packet off data, supporting both the old and the new packet format.
+func readPacket(data []byte) (tag byte, contents []byte, rest []byte, err error) {
+	if len(data) < 2 || data[0]&0x80 == 0 {
+		return 0, nil, nil, fmt.Errorf("invalid packet header")
+	}
+
+	var length, offset int
+	if data[0]&0x40 != 0 {
+		tag = data[0] & 0x3f
+		length, offset, err = readLength(data[1:])
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		offset++
+	} else {
+		tag = (data[0] >> 2) & 0x0f
+		switch data[0] & 0x03 {
+		case 0:
+			length, offset = int(data[1]), 2
+		case 1:
+			if len(data) < 3 {
+				return 0, nil, nil, fmt.Errorf("truncated packet header")
+			}
+			length, offset = int(binary.BigEndian.Uint16(data[1:3])), 3
+		case 2:
+			if len(data) < 5 {
+				return 0, nil, nil, fmt.Errorf("truncated packet header")
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[1:5])), 5
+		default:
+			return 0, nil, nil, fmt.Errorf("unsupported indeterminate packet length")
+		}
+	}
+
+	if length < 0 || offset+length > len(data) {
+		return 0, nil, nil, fmt.Errorf("truncated packet")
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// readLength reads a new format packet or subpacket length, returning the length and the number of bytes it took up.
+// Partial body lengths are not used for key material and are rejected.
+func readLength(data []byte) (length int, size int, err error) {
+	if len(data) < 1 {
+		return 0, 0, fmt.Errorf("truncated length")
+	}
+	switch {
+	case data[0] < 192:
+		return int(data[0]), 1, nil
+	case data[0] < 224:
+		if len(data) < 2 {
+			return 0, 0, fmt.Errorf("truncated length")
+		}
+		return (int(data[0])-192)<<8 + int(data[1]) + 192, 2, nil
+	case data[0] == 255:
+		if len(data) < 5 {
+			return 0, 0, fmt.Errorf("truncated length")
+		}
+		return int(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	default:
+		return 0, 0, fmt.Errorf("unsupported partial body length")
+	}
+}
+
+// signatureRevokers returns the revocation key subpackets in the hashed area of a v4 direct key or certification
+// signature. Other signatures are ignored.
+func signatureRevokers(sig []byte) ([]DesignatedRevoker, error) {
+	if len(sig) < 6 || sig[0] != 4 {
+		return nil, nil
+	}
+	sigType := sig[1]
+	if sigType != signatureTypeDirectKey && (sigType < signatureTypeCertGeneric || sigType > signatureTypeCertPositive) {
+		return nil, nil
+	}
+
+	hashedLength := int(binary.BigEndian.Uint16(sig[4:6]))
+	if 6+hashedLength > len(sig) {
+		return nil, fmt.Errorf("truncated signature subpackets")
+	}
+	subpackets := sig[6 : 6+hashedLength]
+
+	var revokers []DesignatedRevoker
+	for len(subpackets) > 0 {
+		length, size, err := readLength(subpackets)
+		if err != nil {
+			return nil, err
+		}
+		if length < 1 || size+length > len(subpackets) {
+			return nil, fmt.Errorf("truncated signature subpacket")
+		}
+		subpacket := subpackets[size : size+length]
+		subpackets = subpackets[size+length:]
+
+		// The top bit of the type marks the subpacket as critical
+		if subpacket[0]&0x7f != subpacketRevocationKey {
+			continue
+		}
+		// class (1), public key algorithm (1), fingerprint (20 for v4 keys)
+		body := subpacket[1:]
+		if len(body) < 3 || body[0]&0x80 == 0 {
+			continue
+		}
+		revokers = append(revokers, DesignatedRevoker{
+			Fingerprint: strings.ToUpper(hex.EncodeToString(body[2:])),
+			Sensitive:   body[0]&0x40 != 0,
+		})
+	}
+	return revokers, nil
+}
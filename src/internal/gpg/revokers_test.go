@@ -0,0 +1,79 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/stretchr/testify/assert"
+)
+
+// designatedRevokerKey was generated by GnuPG with a "Revoker:" parameter, which stores the designation in a direct
+// key signature.
+const designatedRevokerKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatB9EhYJKwYBBAHaRw8BAQdA+ciKo+riK3O+WLiHCZuKkQBfI1ArFCaoG02W
+pdmYDdWIkAQfFggAOBYhBG3rTU+tJdwr4Os/Gw0PBAEiLmUgBQJq0H0SFwyAFsFq
+oK0OkWIHotsdMiEXYBDNLyXJAgcAAAoJEA0PBAEiLmUgtm0BAM2VS1PY0HVkAL+1
+vZCM+mnpUkVIm0MU6JvHPzayqnvvAPsHc6/gZzmXaRaVM+yX5ucFM1I25uS4Kfd2
+pE8r9Q3HCLQcVGVzdCBVc2VyIDx0ZXN0QGV4YW1wbGUuY29tPoiQBBMWCAA4FiEE
+betNT60l3Cvg6z8bDQ8EASIuZSAFAmrQfRICGwMFCwkIBwIGFQoJCAsCBBYCAwEC
+HgECF4AACgkQDQ8EASIuZSDKVQEA2UJ6llfBufe20mdbpaJXDn5UXMOt3yf+HSnT
+wzCWC7kA/0oI76PH8eBs4DukhhWmvoN5slh5pEbD4yU4mycMSdIA
+=2fCr
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestDesignatedRevokers(t *testing.T) {
+	t.Run("designated revoker", func(t *testing.T) {
+		revokers, err := DesignatedRevokers([]byte(designatedRevokerKey))
+		assert.NoError(t, err)
+		assert.Equal(t, []DesignatedRevoker{{Fingerprint: "C16AA0AD0E916207A2DB1D3221176010CD2F25C9"}}, revokers)
+	})
+
+	t.Run("binary key", func(t *testing.T) {
+		data, err := armor.Unarmor(designatedRevokerKey)
+		assert.NoError(t, err)
+
+		revokers, err := DesignatedRevokers(data)
+		assert.NoError(t, err)
+		assert.Len(t, revokers, 1)
+	})
+
+	t.Run("no designated revoker", func(t *testing.T) {
+		publicGPGKey, _ := generateGPGKey()
+
+		revokers, err := DesignatedRevokers([]byte(publicGPGKey))
+		assert.NoError(t, err)
+		assert.Empty(t, revokers)
+	})
+}
+
+func TestReadPacket(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		tag      byte
+		contents []byte
+		err      string
+	}{
+		{name: "new format", data: []byte{0xC2, 0x02, 0xAA, 0xBB, 0xCC}, tag: 2, contents: []byte{0xAA, 0xBB}},
+		{name: "new format two octet length", data: append([]byte{0xC2, 0xC0, 0x00}, make([]byte, 192)...), tag: 2, contents: make([]byte, 192)},
+		{name: "old format", data: []byte{0x88, 0x01, 0xAA}, tag: 2, contents: []byte{0xAA}},
+		{name: "truncated", data: []byte{0xC2, 0x05, 0xAA}, err: "truncated packet"},
+		{name: "not a packet", data: []byte{0x00, 0x00}, err: "invalid packet header"},
+		{name: "partial length", data: []byte{0xC2, 0xE0, 0xAA}, err: "unsupported partial body length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, contents, _, err := readPacket(tt.data)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tag, tag)
+			assert.Equal(t, tt.contents, contents)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package gpg
+
+import (
+	"sort"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// CertifiedBy returns the user IDs of key that carry a valid certification made by certifier, sorted by name. When a key
+// is rotated, the old key certifying the user IDs of the new one shows that the owner of the old key vouches for it.
+func CertifiedBy(key *crypto.Key, certifier *crypto.Key) []string {
+	certifierKeys := []*packet.PublicKey{certifier.GetEntity().PrimaryKey}
+	for _, subkey := range certifier.GetEntity().Subkeys {
+		certifierKeys = append(certifierKeys, subkey.PublicKey)
+	}
+
+	var result []string
+	primary := key.GetEntity().PrimaryKey
+	for name, identity := range key.GetEntity().Identities {
+		if certifiedByAny(identity.Signatures, name, primary, certifierKeys) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// certifiedByAny returns true if any of the signatures is a valid certification of the user ID made by one of the keys.
+func certifiedByAny(sigs []*packet.Signature, userID string, primary *packet.PublicKey, keys []*packet.PublicKey) bool {
+	for _, sig := range sigs {
+		if sig.SigType < packet.SigTypeGenericCert || sig.SigType > packet.SigTypePositiveCert || sig.IssuerKeyId == nil {
+			continue
+		}
+		for _, k := range keys {
+			if k.KeyId == *sig.IssuerKeyId && k.VerifyUserIdSignature(userID, primary, sig) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertifiedBy(t *testing.T) {
+	oldKey, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, CertifiedBy(newKey, oldKey))
+
+	if err := newKey.GetEntity().SignIdentity("Test User <test@example.com>", oldKey.GetEntity(), nil); err != nil {
+		t.Fatal(err)
+	}
+	armored, err := newKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseKey(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"Test User <test@example.com>"}, CertifiedBy(parsed, oldKey))
+	assert.Empty(t, CertifiedBy(parsed, otherKey))
+}
@@ -0,0 +1,71 @@
+package gpg
+
+import (
+	stdcrypto "crypto"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// signatureHashNames maps the hash functions used by signatures to the names used in hashAlgorithmNames.
+var signatureHashNames = map[stdcrypto.Hash]string{
+	stdcrypto.MD5:       "MD5",
+	stdcrypto.SHA1:      "SHA1",
+	stdcrypto.RIPEMD160: "RIPEMD160",
+	stdcrypto.SHA224:    "SHA224",
+	stdcrypto.SHA256:    "SHA256",
+	stdcrypto.SHA384:    "SHA384",
+	stdcrypto.SHA512:    "SHA512",
+	stdcrypto.SHA3_256:  "SHA3-256",
+	stdcrypto.SHA3_512:  "SHA3-512",
+}
+
+// SignatureHash describes the hash algorithm of one of the key's own certification signatures.
+type SignatureHash struct {
+	Signature string // Which signature this is, e.g. the self-signature of a user ID
+	Hash      string // Name of the hash algorithm
+	SHA1      bool   // Whether the hash algorithm is SHA-1, which is vulnerable to collision attacks
+}
+
+// SelfSignatureHashes returns the hash algorithm of every user ID self-signature and subkey binding signature of the
+// key. User IDs are listed first, sorted by name, followed by the subkeys in key order.
+func SelfSignatureHashes(key *crypto.Key) []SignatureHash {
+	entity := key.GetEntity()
+
+	names := make([]string, 0, len(entity.Identities))
+	for name := range entity.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var hashes []SignatureHash
+	for _, name := range names {
+		sig := entity.Identities[name].SelfSignature
+		if sig == nil {
+			continue
+		}
+		hashes = append(hashes, signatureHash(fmt.Sprintf("self-signature of user ID %s", name), sig.Hash))
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig == nil {
+			continue
+		}
+		keyID := strings.ToUpper(subkey.PublicKey.KeyIdString())
+		hashes = append(hashes, signatureHash(fmt.Sprintf("binding signature of subkey %s", keyID), subkey.Sig.Hash))
+	}
+	return hashes
+}
+
+func signatureHash(signature string, hash stdcrypto.Hash) SignatureHash {
+	name, ok := signatureHashNames[hash]
+	if !ok {
+		name = fmt.Sprintf("unknown (%d)", hash)
+	}
+	return SignatureHash{
+		Signature: signature,
+		Hash:      name,
+		SHA1:      hash == stdcrypto.SHA1,
+	}
+}
@@ -0,0 +1,34 @@
+package gpg
+
+import (
+	stdcrypto "crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfSignatureHashes(t *testing.T) {
+	t.Run("SHA256 signatures", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+
+		hashes := SelfSignatureHashes(key)
+		// One user ID, the default encryption subkey and the added signing subkey
+		assert.Len(t, hashes, 3)
+		assert.Equal(t, "self-signature of user ID test <test@example.com>", hashes[0].Signature)
+		for _, h := range hashes {
+			assert.Equal(t, "SHA256", h.Hash)
+			assert.False(t, h.SHA1)
+		}
+	})
+
+	t.Run("SHA1 binding signature", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+		key.GetEntity().Subkeys[0].Sig.Hash = stdcrypto.SHA1
+
+		hashes := SelfSignatureHashes(key)
+		assert.False(t, hashes[0].SHA1)
+		assert.Contains(t, hashes[1].Signature, "binding signature of subkey")
+		assert.Equal(t, "SHA1", hashes[1].Hash)
+		assert.True(t, hashes[1].SHA1)
+	})
+}
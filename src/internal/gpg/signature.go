@@ -0,0 +1,106 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// VerifyDetachedSignature checks that signature is a valid detached signature of message made by key, and returns the
+// time the signature was created. The signature may be either armored or binary.
+func VerifyDetachedSignature(key *crypto.Key, message []byte, signature []byte) (time.Time, error) {
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to build key ring: %w", err)
+	}
+
+	sig, err := parseSignature(signature)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	created, err := keyRing.GetVerifiedSignatureTimestamp(crypto.NewPlainMessage(message), sig, crypto.GetUnixTime())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("signature verification failed: %w", err)
+	}
+	return time.Unix(created, 0).UTC(), nil
+}
+
+// SignatureIssuers returns the upper case hex key IDs of the keys that made the signature, as declared in the
+// signature packets. The signature may be either armored or binary.
+func SignatureIssuers(signature []byte) ([]string, error) {
+	sig, err := parseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	keyIDs, ok := sig.GetHexSignatureKeyIDs()
+	if !ok {
+		return nil, fmt.Errorf("signature does not declare the key that made it")
+	}
+	for i := range keyIDs {
+		keyIDs[i] = strings.ToUpper(keyIDs[i])
+	}
+	return keyIDs, nil
+}
+
+// ParseSigningKey parses an armored private key to sign with, unlocking it with passphrase if it is locked.
+func ParseSigningKey(data []byte, passphrase []byte) (*crypto.Key, error) {
+	key, err := crypto.NewKeyFromArmored(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signing key: %w", err)
+	}
+	if !key.IsPrivate() {
+		return nil, fmt.Errorf("the signing key is a public key, a private key is required")
+	}
+	if KeySigningCapability(key) == SigningCapabilityNone {
+		return nil, fmt.Errorf("the signing key has no signing-capable (sub)key")
+	}
+
+	locked, err := key.IsLocked()
+	if err != nil {
+		return nil, fmt.Errorf("could not check whether the signing key is locked: %w", err)
+	}
+	if locked {
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("the signing key is locked and no passphrase was given")
+		}
+		key, err = key.Unlock(passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("could not unlock signing key: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// SignDetached makes an armored detached signature of message with the unlocked private key, which
+// VerifyDetachedSignature accepts.
+func SignDetached(key *crypto.Key, message []byte) ([]byte, error) {
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build key ring: %w", err)
+	}
+	sig, err := keyRing.SignDetached(crypto.NewPlainMessage(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	armored, err := sig.GetArmored()
+	if err != nil {
+		return nil, fmt.Errorf("failed to armor signature: %w", err)
+	}
+	return []byte(armored), nil
+}
+
+func parseSignature(signature []byte) (*crypto.PGPSignature, error) {
+	if bytes.Contains(signature, []byte("-----BEGIN PGP")) {
+		sig, err := crypto.NewPGPSignatureFromArmored(string(signature))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse armored signature: %w", err)
+		}
+		return sig, nil
+	}
+	return crypto.NewPGPSignature(signature), nil
+}
@@ -0,0 +1,167 @@
+package gpg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDetachedSignature(t *testing.T) {
+	privateKey, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := crypto.NewKeyRing(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armoredKey, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseKey(armoredKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("nonce-1234")
+	signature, err := signer.SignDetached(crypto.NewPlainMessage(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	armoredSignature, err := signature.GetArmored()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := crypto.GenerateKey("other", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		key       *crypto.Key
+		message   []byte
+		signature []byte
+		err       string
+	}{
+		{
+			name:      "armored signature",
+			key:       key,
+			message:   message,
+			signature: []byte(armoredSignature),
+		},
+		{
+			name:      "binary signature",
+			key:       key,
+			message:   message,
+			signature: signature.GetBinary(),
+		},
+		{
+			name:      "tampered message",
+			key:       key,
+			message:   []byte("nonce-5678"),
+			signature: []byte(armoredSignature),
+			err:       "signature verification failed",
+		},
+		{
+			name:      "signed by another key",
+			key:       otherKey,
+			message:   message,
+			signature: []byte(armoredSignature),
+			err:       "signature verification failed",
+		},
+		{
+			name:      "malformed armor",
+			key:       key,
+			message:   message,
+			signature: []byte("-----BEGIN PGP SIGNATURE-----\ngarbage"),
+			err:       "failed to parse armored signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := VerifyDetachedSignature(tt.key, tt.message, tt.signature)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), created, time.Minute)
+		})
+	}
+}
+
+func TestSignatureIssuers(t *testing.T) {
+	key, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signer.SignDetached(crypto.NewPlainMessage([]byte("nonce-1234")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	armoredSignature, err := signature.GetArmored()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][]byte{signature.GetBinary(), []byte(armoredSignature)} {
+		issuers, err := SignatureIssuers(data)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{strings.ToUpper(key.GetHexKeyID())}, issuers)
+	}
+
+	_, err = SignatureIssuers([]byte("not a signature"))
+	assert.Error(t, err)
+}
+
+func TestSignDetached(t *testing.T) {
+	privateKey, err := crypto.GenerateKey("Verifier", "verifier@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked, err := privateKey.Lock([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	armoredLocked, err := locked.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	armoredPublic, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ParseSigningKey([]byte(armoredPublic), nil)
+	assert.ErrorContains(t, err, "the signing key is a public key")
+	_, err = ParseSigningKey([]byte(armoredLocked), nil)
+	assert.ErrorContains(t, err, "the signing key is locked and no passphrase was given")
+	_, err = ParseSigningKey([]byte(armoredLocked), []byte("wrong"))
+	assert.ErrorContains(t, err, "could not unlock signing key")
+
+	signingKey, err := ParseSigningKey([]byte(armoredLocked), []byte("secret"))
+	assert.NoError(t, err)
+
+	message := []byte(`{"status": "pass"}`)
+	signature, err := SignDetached(signingKey, message)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(signature), "-----BEGIN PGP SIGNATURE-----"))
+
+	publicKey, err := ParseKey(armoredPublic)
+	assert.NoError(t, err)
+	_, err = VerifyDetachedSignature(publicKey, message, signature)
+	assert.NoError(t, err)
+	_, err = VerifyDetachedSignature(publicKey, []byte(`{"status": "fail"}`), signature)
+	assert.Error(t, err)
+}
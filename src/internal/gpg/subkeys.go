@@ -0,0 +1,130 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// SubkeyCrossCertification is the outcome of verifying a signing subkey's binding and embedded primary key binding
+// (back) signature.
+type SubkeyCrossCertification struct {
+	KeyID string // The hex key ID of the subkey
+	Err   error  // Non-nil if the binding or the back signature does not verify
+}
+
+// CheckSigningSubkeyCrossCertifications verifies that every subkey flagged for signing carries a valid embedded
+// primary key binding signature. Without it, anyone could attach somebody else's signing subkey to their own key.
+func CheckSigningSubkeyCrossCertifications(key *crypto.Key) []SubkeyCrossCertification {
+	entity := key.GetEntity()
+
+	var results []SubkeyCrossCertification
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig == nil || !subkey.Sig.FlagsValid || !subkey.Sig.FlagSign {
+			continue
+		}
+		results = append(results, SubkeyCrossCertification{
+			KeyID: strings.ToUpper(subkey.PublicKey.KeyIdString()),
+			// VerifyKeySignature also verifies the embedded back signature for signing subkeys
+			Err: entity.PrimaryKey.VerifyKeySignature(subkey.PublicKey, subkey.Sig),
+		})
+	}
+	return results
+}
+
+// SubkeyCrossCertificationError is returned by ParseKey and ParseKeyBytes for a key the OpenPGP library rejects
+// because one of its signing subkeys is not cross-certified, see CheckSigningSubkeyCrossCertifications.
+type SubkeyCrossCertificationError struct {
+	SubkeyCrossCertification
+}
+
+func (e *SubkeyCrossCertificationError) Error() string {
+	return fmt.Sprintf("subkey %s: %s", e.KeyID, e.Err)
+}
+
+func (e *SubkeyCrossCertificationError) Unwrap() error {
+	return e.Err
+}
+
+// checkSubkeyCrossCertifications returns a SubkeyCrossCertificationError if a binding signature of a signing subkey
+// of the first key in data does not verify. Like checkKeyVersion, it is used to replace the generic parse errors of the
+// OpenPGP library, keys that cannot be inspected are left to the library to report.
+func checkSubkeyCrossCertifications(data []byte) error {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		unarmored, err := armor.Unarmor(string(data))
+		if err != nil {
+			return nil
+		}
+		data = unarmored
+	}
+
+	_, _, rest, err := readPacket(data)
+	if err != nil {
+		return nil
+	}
+	p, err := packet.Read(bytes.NewReader(data[:len(data)-len(rest)]))
+	if err != nil {
+		return nil
+	}
+	var primary *packet.PublicKey
+	switch pk := p.(type) {
+	case *packet.PublicKey:
+		primary = pk
+	case *packet.PrivateKey:
+		primary = &pk.PublicKey
+	default:
+		return nil
+	}
+
+	var subkey *packet.PublicKey
+	for len(rest) > 0 {
+		tag, contents, next, err := readPacket(rest)
+		if err != nil {
+			return nil
+		}
+		raw := rest[:len(rest)-len(next)]
+		if tag == packetTagPublicKey || tag == packetTagSecretKey {
+			// The next key of a keyring starts here
+			return nil
+		}
+		rest = next
+
+		switch tag {
+		case packetTagUserID, packetTagUserAttribute:
+			subkey = nil
+		case packetTagPublicSubkey, packetTagSecretSubkey:
+			subkey = nil
+			if p, err := packet.Read(bytes.NewReader(raw)); err == nil {
+				switch pk := p.(type) {
+				case *packet.PublicKey:
+					subkey = pk
+				case *packet.PrivateKey:
+					subkey = &pk.PublicKey
+				}
+			}
+		case packetTagSignature:
+			if subkey == nil || signatureType(contents) != signatureTypeSubkeyBinding {
+				continue
+			}
+			p, err := packet.Read(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			sig, ok := p.(*packet.Signature)
+			if !ok || !sig.FlagsValid || !sig.FlagSign {
+				continue
+			}
+			if err := primary.VerifyKeySignature(subkey, sig); err != nil {
+				return &SubkeyCrossCertificationError{SubkeyCrossCertification{
+					KeyID: strings.ToUpper(subkey.KeyIdString()),
+					Err:   err,
+				}}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package gpg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeyWithSigningSubkey(t *testing.T) *crypto.Key {
+	t.Helper()
+
+	privateKey, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = privateKey.GetEntity().AddSigningSubkey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseKey(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestCheckSigningSubkeyCrossCertifications(t *testing.T) {
+	t.Run("no signing subkeys", func(t *testing.T) {
+		publicGPGKey, _ := generateGPGKey()
+		key, err := ParseKey(publicGPGKey)
+		assert.NoError(t, err)
+
+		assert.Empty(t, CheckSigningSubkeyCrossCertifications(key))
+	})
+
+	t.Run("cross-certified signing subkey", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+
+		results := CheckSigningSubkeyCrossCertifications(key)
+		assert.Len(t, results, 1)
+		assert.NoError(t, results[0].Err)
+		assert.Len(t, results[0].KeyID, 16)
+	})
+
+	t.Run("missing back signature", func(t *testing.T) {
+		key := generateKeyWithSigningSubkey(t)
+		for _, subkey := range key.GetEntity().Subkeys {
+			if subkey.Sig.FlagSign {
+				subkey.Sig.EmbeddedSignature = nil
+			}
+		}
+
+		results := CheckSigningSubkeyCrossCertifications(key)
+		assert.Len(t, results, 1)
+		assert.ErrorContains(t, results[0].Err, "missing cross-signature")
+	})
+}
+
+func TestParseKey_MissingCrossCertification(t *testing.T) {
+	privateKey, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entity := privateKey.GetEntity()
+	if err := entity.AddSigningSubkey(nil); err != nil {
+		t.Fatal(err)
+	}
+	// Bind the signing subkey again, without the back signature it makes
+	subkey := entity.Subkeys[len(entity.Subkeys)-1]
+	subkey.Sig.EmbeddedSignature = nil
+	if err := subkey.Sig.SignKey(subkey.PublicKey, entity.PrivateKey, nil); err != nil {
+		t.Fatal(err)
+	}
+	armored, err := privateKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ParseKey(armored)
+	var crossErr *SubkeyCrossCertificationError
+	if assert.ErrorAs(t, err, &crossErr) {
+		assert.Equal(t, strings.ToUpper(subkey.PublicKey.KeyIdString()), crossErr.KeyID)
+		assert.ErrorContains(t, crossErr, "missing cross-signature")
+	}
+
+	valid, err := generateKeyWithSigningSubkey(t).GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, checkSubkeyCrossCertifications([]byte(valid)))
+}
@@ -0,0 +1,80 @@
+package gpg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// tofuSigningAlgorithms lists the public key algorithms OpenTofu can verify provider signatures with.
+var tofuSigningAlgorithms = map[packet.PublicKeyAlgorithm]bool{
+	packet.PubKeyAlgoRSA:         true,
+	packet.PubKeyAlgoRSASignOnly: true,
+	packet.PubKeyAlgoDSA:         true,
+	packet.PubKeyAlgoECDSA:       true,
+	packet.PubKeyAlgoEdDSA:       true,
+}
+
+// tofuMinRSABits is the shortest RSA key newer versions of OpenTofu's OpenPGP library accept signatures from.
+const tofuMinRSABits = 2047
+
+// TofuSigningKey describes a primary key or subkey flagged for signing and why OpenTofu would not accept the detached
+// signatures over SHA256SUMS it makes.
+type TofuSigningKey struct {
+	KeyID     string   // The hex key ID of the (sub)key
+	Algorithm string   // Name of the public key algorithm
+	Primary   bool     // The primary key rather than a subkey
+	Problems  []string // Empty if OpenTofu accepts signatures made by the key
+}
+
+// Compatible returns true if OpenTofu accepts signatures made by the key.
+func (k TofuSigningKey) Compatible() bool {
+	return len(k.Problems) == 0
+}
+
+// TofuSigningKeys returns the primary key and the subkeys OpenTofu would select to verify a provider signature, those
+// flagged for signing, and checks whether it could verify signatures made by them. This only considers the format of
+// the key, validity (expiry, revocation) is checked separately.
+func TofuSigningKeys(key *crypto.Key) []TofuSigningKey {
+	entity := key.GetEntity()
+
+	var keys []TofuSigningKey
+	identity := entity.PrimaryIdentity()
+	if identity != nil && identity.SelfSignature != nil && identity.SelfSignature.FlagsValid && identity.SelfSignature.FlagSign {
+		keys = append(keys, tofuSigningKey(entity.PrimaryKey, true))
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig != nil && subkey.Sig.FlagsValid && subkey.Sig.FlagSign {
+			keys = append(keys, tofuSigningKey(subkey.PublicKey, false))
+		}
+	}
+	return keys
+}
+
+func tofuSigningKey(pk *packet.PublicKey, primary bool) TofuSigningKey {
+	name, ok := publicKeyAlgorithmNames[pk.PubKeyAlgo]
+	if !ok {
+		name = fmt.Sprintf("unknown (%d)", pk.PubKeyAlgo)
+	}
+	k := TofuSigningKey{
+		KeyID:     strings.ToUpper(pk.KeyIdString()),
+		Algorithm: name,
+		Primary:   primary,
+	}
+
+	if pk.Version != 4 {
+		// The draft version 5 format was dropped from the OpenPGP library in favour of version 6 keys
+		k.Problems = append(k.Problems, fmt.Sprintf("it is a version %d key, OpenTofu only verifies signatures of version 4 keys", pk.Version))
+	}
+	if !tofuSigningAlgorithms[pk.PubKeyAlgo] {
+		k.Problems = append(k.Problems, fmt.Sprintf("OpenTofu cannot verify signatures made with %s, use RSA, DSA, ECDSA or EdDSA", name))
+	}
+	if pk.PubKeyAlgo == packet.PubKeyAlgoRSA || pk.PubKeyAlgo == packet.PubKeyAlgoRSASignOnly {
+		if bits, err := pk.BitLength(); err == nil && bits < tofuMinRSABits {
+			k.Problems = append(k.Problems, fmt.Sprintf("its %d bit RSA key is too short, OpenTofu rejects signatures of RSA keys shorter than 2048 bits", bits))
+		}
+	}
+	return k
+}
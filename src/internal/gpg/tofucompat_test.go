@@ -0,0 +1,81 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTofuSigningKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(key *crypto.Key)
+		expected []bool // Compatible() of each signing key, the primary key first
+		problem  string
+	}{
+		{
+			name:     "short RSA primary key",
+			modify:   func(key *crypto.Key) {},
+			expected: []bool{false},
+			problem:  "its 1024 bit RSA key is too short",
+		},
+		{
+			name: "signing subkey with unsupported algorithm",
+			modify: func(key *crypto.Key) {
+				key.GetEntity().PrimaryIdentity().SelfSignature.FlagSign = false
+				key.GetEntity().Subkeys[0].Sig.FlagSign = true
+				key.GetEntity().Subkeys[0].PublicKey.PubKeyAlgo = packet.PubKeyAlgoElGamal
+			},
+			expected: []bool{false},
+			problem:  "OpenTofu cannot verify signatures made with ElGamal",
+		},
+		{
+			name: "version 5 key",
+			modify: func(key *crypto.Key) {
+				key.GetEntity().PrimaryKey.Version = 5
+			},
+			expected: []bool{false},
+			problem:  "it is a version 5 key",
+		},
+		{
+			name: "no signing key",
+			modify: func(key *crypto.Key) {
+				key.GetEntity().PrimaryIdentity().SelfSignature.FlagSign = false
+			},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			publicGPGKey, _ := generateGPGKey()
+			key, err := ParseKey(publicGPGKey)
+			assert.NoError(t, err)
+
+			test.modify(key)
+
+			keys := TofuSigningKeys(key)
+			var compatible []bool
+			for _, k := range keys {
+				compatible = append(compatible, k.Compatible())
+			}
+			assert.Equal(t, test.expected, compatible)
+			if test.problem != "" {
+				assert.Contains(t, keys[0].Problems[0], test.problem)
+			}
+		})
+	}
+}
+
+func TestTofuSigningKeys_Compatible(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	assert.NoError(t, err)
+
+	keys := TofuSigningKeys(key)
+	assert.Len(t, keys, 1)
+	assert.True(t, keys[0].Primary)
+	assert.Equal(t, "EdDSA", keys[0].Algorithm)
+	assert.True(t, keys[0].Compatible())
+}
@@ -0,0 +1,78 @@
+package gpg
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// KeyValidity is the end of the validity period of the primary key or one of its signing subkeys.
+type KeyValidity struct {
+	KeyID   string    // The hex key ID of the primary key or subkey
+	Subkey  bool      // Whether this is a subkey
+	Expires time.Time // Zero if the key never expires
+}
+
+// SigningKeyValidity returns when the primary key and each subkey flagged for signing expire. A subkey cannot outlive
+// its primary key, so a subkey without an expiration of its own expires together with the primary key.
+func SigningKeyValidity(key *crypto.Key) []KeyValidity {
+	entity := key.GetEntity()
+
+	primary := KeyValidity{KeyID: strings.ToUpper(entity.PrimaryKey.KeyIdString())}
+	identity := entity.PrimaryIdentity()
+	if identity != nil && identity.SelfSignature != nil {
+		primary.Expires = expiry(entity.PrimaryKey.CreationTime, identity.SelfSignature.KeyLifetimeSecs)
+	}
+
+	result := []KeyValidity{primary}
+	for _, subkey := range entity.Subkeys {
+		if subkey.Sig == nil || !subkey.Sig.FlagsValid || !subkey.Sig.FlagSign {
+			continue
+		}
+		validity := KeyValidity{
+			KeyID:   strings.ToUpper(subkey.PublicKey.KeyIdString()),
+			Subkey:  true,
+			Expires: expiry(subkey.PublicKey.CreationTime, subkey.Sig.KeyLifetimeSecs),
+		}
+		if !primary.Expires.IsZero() && (validity.Expires.IsZero() || validity.Expires.After(primary.Expires)) {
+			validity.Expires = primary.Expires
+		}
+		result = append(result, validity)
+	}
+	return result
+}
+
+// expiry returns the time a key created at created with the given lifetime expires, or the zero time if it does not.
+func expiry(created time.Time, lifetimeSecs *uint32) time.Time {
+	if lifetimeSecs == nil || *lifetimeSecs == 0 {
+		return time.Time{}
+	}
+	return created.Add(time.Duration(*lifetimeSecs) * time.Second).UTC()
+}
+
+// IsExpired reports whether the primary key or its primary user ID self-signature has expired at now. Unlike
+// crypto.Key.IsExpired, the time is not taken from the global clock of the OpenPGP library.
+func IsExpired(key *crypto.Key, now time.Time) bool {
+	entity := key.GetEntity()
+	identity := entity.PrimaryIdentity()
+	return entity.PrimaryKey.KeyExpired(identity.SelfSignature, now) || identity.SelfSignature.SigExpired(now)
+}
+
+// IsRevoked reports whether the key or its primary user ID has a revocation signature in effect at now.
+func IsRevoked(key *crypto.Key, now time.Time) bool {
+	return len(Revocations(key, now)) > 0
+}
+
+// LatestSelfSignature returns when the most recent user ID self-signature was made. It carries the expiry and the
+// preferences of the key, so an old one means they have not been reviewed in a long time. The zero time is returned if
+// no user ID has a valid self-signature.
+func LatestSelfSignature(key *crypto.Key) time.Time {
+	var latest time.Time
+	for _, identity := range key.GetEntity().Identities {
+		if identity.SelfSignature != nil && identity.SelfSignature.CreationTime.After(latest) {
+			latest = identity.SelfSignature.CreationTime
+		}
+	}
+	return latest.UTC()
+}
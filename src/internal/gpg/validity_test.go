@@ -0,0 +1,95 @@
+package gpg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// expiringKey was generated with gpg. The primary key expires after two years, its signing subkey was added without
+// an expiration.
+const expiringKey = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCEyRYJKwYBBAHaRw8BAQdAUi2VFzojIxMzTD+NQzT23/Jk/hAWmfCfT86I
+sTOtH420HFRlc3QgVXNlciA8dGVzdEBleGFtcGxlLmNvbT6IlgQTFggAPhYhBIvh
+qz8KzBMSWPUJQbP029mEBqyBBQJq0ITJAhsDBQkDwmcABQsJCAcCBhUKCQgLAgQW
+AgMBAh4BAheAAAoJELP029mEBqyBLOUBAIQeF4AAK7OSO3O62pXguL3GfN43o7Il
+cb9FeSd/bXOrAQCJDRZs8mTNwUEgXIol0azZOJa4qjhRCUvPYZX3EhxlCLgzBGrQ
+hMkWCSsGAQQB2kcPAQEHQNVXi+l49Kde4W9dY4YKakmIG8qoEgJIs6PnKNdQcZGe
+iO8EGBYIACAWIQSL4as/CswTElj1CUGz9NvZhAasgQUCatCEyQIbAgCBCRCz9NvZ
+hAasgXYgBBkWCAAdFiEEknD77qaPbzVIjMMZawQKXviyi/QFAmrQhMkACgkQawQK
+Xviyi/RTAQEAlwmqcr1WNKKGsoaJE7h3eJJE4EPm47XxJBJECUP40fkA/01jWxPR
+ileGvtHKG7b3lwZdSbuztyOAogNpheeht8wE4VQA/0d/LgogXD2IwM+lPsoEhG2d
+Uv1ty/j5OlAOu+4GhAglAP9qkEJEUdxpVsQFUidMhb2Nhnkr0Vqr/mth2OKyjJcj
+Aw==
+=/6Z/
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// revokedKey was generated with gpg and revoked with the revocation certificate gpg created for it.
+const revokedKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCFHRYJKwYBBAHaRw8BAQdANulv+9Zu6zpLpEMCe1/Vm+KoRAKfJu4kqyNn
+ECPPKaCIeAQgFggAIBYhBFAChfIiPtgiP4kyqVLeaZJwaIs2BQJq0IUdAh0AAAoJ
+EFLeaZJwaIs2Et4A/2+S8c9+SXvI7GzKklqMT0gBwHdASt3duE93FkCiJcbqAP9x
+RDTWd6rNovolKOrPBcLeTyZ+F7yl2PdFHFTHko5AD7QcVGVzdCBVc2VyIDx0ZXN0
+QGV4YW1wbGUuY29tPoiWBBMWCAA+FiEEUAKF8iI+2CI/iTKpUt5pknBoizYFAmrQ
+hR0CGwMFCQPCZwAFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQUt5pknBoizan
+bgEA5Eq+X0pxH0esl3pfyXMe+XPDLY8ByKFtyygIZdM+d6MBANvJn1/GIKuI+W/X
+Eo91LMzJeXgvV8T+v4suzDBetbsC
+=uBwQ
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestSigningKeyValidity(t *testing.T) {
+	key, err := ParseKey(expiringKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Unix(1855122377, 0).UTC()
+	assert.Equal(t, []KeyValidity{
+		{KeyID: "B3F4DBD98406AC81", Expires: expires},
+		{KeyID: "6B040A5EF8B28BF4", Subkey: true, Expires: expires},
+	}, SigningKeyValidity(key))
+
+	neverExpires, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The x25519 subkey is an encryption subkey and therefore not included
+	assert.Equal(t, []KeyValidity{
+		{KeyID: strings.ToUpper(neverExpires.GetHexKeyID())},
+	}, SigningKeyValidity(neverExpires))
+}
+
+func TestLatestSelfSignature(t *testing.T) {
+	key, err := ParseKey(expiringKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, time.Unix(1792050377, 0).UTC(), LatestSelfSignature(key))
+}
+
+func TestIsExpired(t *testing.T) {
+	key, err := ParseKey(expiringKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Unix(1855122377, 0).UTC()
+
+	assert.False(t, IsExpired(key, expires.Add(-time.Hour)))
+	assert.True(t, IsExpired(key, expires.Add(time.Hour)))
+	assert.False(t, IsRevoked(key, expires.Add(-time.Hour)))
+}
+
+func TestIsRevoked(t *testing.T) {
+	key, err := ParseKey(revokedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, IsRevoked(key, time.Unix(1792050461, 0)))
+}
@@ -0,0 +1,84 @@
+package gpg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/armor"
+)
+
+// OpenPGP packet tags of the primary key (RFC 4880, section 4.3).
+const (
+	packetTagSecretKey = 5
+	packetTagPublicKey = 6
+)
+
+// Lengths of fingerprints in hex digits. Version 4 keys use 20 byte fingerprints, version 5 and 6 keys use 32 byte
+// fingerprints instead (RFC 9580, section 5.5.4).
+const (
+	fingerprintLengthV4 = 40
+	fingerprintLengthV5 = 64
+)
+
+// supportedKeyVersions lists the key versions the underlying OpenPGP library can parse.
+var supportedKeyVersions = map[int]bool{4: true, 5: true}
+
+// KeyVersion returns the version of the primary key, given the key as ascii armor or in its binary form.
+func KeyVersion(data []byte) (int, error) {
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		unarmored, err := armor.Unarmor(string(data))
+		if err != nil {
+			return 0, fmt.Errorf("could not unarmor key: %w", err)
+		}
+		data = unarmored
+	}
+
+	tag, contents, _, err := readPacket(data)
+	if err != nil {
+		return 0, err
+	}
+	if tag != packetTagPublicKey && tag != packetTagSecretKey {
+		return 0, fmt.Errorf("first packet is not a key but has tag %d", tag)
+	}
+	if len(contents) == 0 {
+		return 0, fmt.Errorf("truncated key packet")
+	}
+	return int(contents[0]), nil
+}
+
+// checkKeyVersion returns an error if data holds a key of a version that cannot be parsed. It is used to replace the
+// generic parse errors of the OpenPGP library, keys that cannot be inspected at all are left to the library to report.
+func checkKeyVersion(data []byte) error {
+	version, err := KeyVersion(data)
+	if err != nil || supportedKeyVersions[version] {
+		return nil
+	}
+	return fmt.Errorf("unsupported key version %d, only version 4 keys are supported", version)
+}
+
+// KeyIDFromFingerprint returns the key ID for the given hex fingerprint. Version 4 key IDs are the last 16 hex digits
+// of the fingerprint, while later versions use the first 16.
+func KeyIDFromFingerprint(fingerprint string) string {
+	fingerprint = strings.ToUpper(fingerprint)
+	if len(fingerprint) < 16 {
+		return fingerprint
+	}
+	if len(fingerprint) == fingerprintLengthV4 {
+		return fingerprint[len(fingerprint)-16:]
+	}
+	return fingerprint[:16]
+}
+
+// ValidateFingerprint returns an error if s is not a hex fingerprint of a version 4, 5 or 6 key. It is meant for
+// fingerprints given by users, so that typos are reported before any work is done with them.
+func ValidateFingerprint(s string) error {
+	if len(s) != fingerprintLengthV4 && len(s) != fingerprintLengthV5 {
+		return fmt.Errorf("fingerprint %q has %d hex digits, expected %d for a version 4 key or %d for a version 5 or 6 key", s, len(s), fingerprintLengthV4, fingerprintLengthV5)
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return fmt.Errorf("fingerprint %q is not hexadecimal", s)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// v6Key is a version 6 Ed25519 public key (RFC 9580) with a user ID but without signatures. GnuPG 2.2 cannot create
+// version 6 keys, so the packets were assembled by hand.
+const v6Key = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+xioGZVPxABsAAAAg4b0SOtZf3z4gJCCaXABoPsviioDhT4pgJUZ0+nMcO0XNHFRl
+c3QgVXNlciA8dGVzdEBleGFtcGxlLmNvbT4=
+=s4Jx
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestKeyVersion(t *testing.T) {
+	v4Key, err := generateGPGKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		version int
+		err     string
+	}{
+		{
+			name:    "version 4 key",
+			data:    v4Key,
+			version: 4,
+		},
+		{
+			name:    "version 6 key",
+			data:    v6Key,
+			version: 6,
+		},
+		{
+			name: "not a key",
+			data: "\xc2\x01\x04",
+			err:  "first packet is not a key but has tag 2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			version, err := KeyVersion([]byte(test.data))
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.version, version)
+		})
+	}
+}
+
+func TestParseKey_UnsupportedVersion(t *testing.T) {
+	_, err := ParseKey(v6Key)
+	assert.EqualError(t, err, "unsupported key version 6, only version 4 keys are supported")
+
+	_, err = ParseKeyBytes([]byte(v6Key))
+	assert.EqualError(t, err, "unsupported key version 6, only version 4 keys are supported")
+}
+
+func TestKeyIDFromFingerprint(t *testing.T) {
+	tests := []struct {
+		name        string
+		fingerprint string
+		keyID       string
+	}{
+		{
+			name:        "version 4 fingerprint",
+			fingerprint: "a8b6d5e1f0c2b3a49d7e8f6c5b4a39281706f5e4",
+			keyID:       "5B4A39281706F5E4",
+		},
+		{
+			name:        "version 6 fingerprint",
+			fingerprint: "CB186C4F0609A697E4D52DFA6C722B0C1F1E27C18A56708F6525EC27BAD9ACC9",
+			keyID:       "CB186C4F0609A697",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.keyID, KeyIDFromFingerprint(test.fingerprint))
+		})
+	}
+}
+
+func TestValidateFingerprint(t *testing.T) {
+	tests := []struct {
+		name        string
+		fingerprint string
+		err         string
+	}{
+		{
+			name:        "version 4 fingerprint",
+			fingerprint: "a8b6d5e1f0c2b3a49d7e8f6c5b4a39281706f5e4",
+		},
+		{
+			name:        "version 6 fingerprint",
+			fingerprint: "CB186C4F0609A697E4D52DFA6C722B0C1F1E27C18A56708F6525EC27BAD9ACC9",
+		},
+		{
+			name:        "key ID",
+			fingerprint: "5B4A39281706F5E4",
+			err:         `fingerprint "5B4A39281706F5E4" has 16 hex digits, expected 40 for a version 4 key or 64 for a version 5 or 6 key`,
+		},
+		{
+			name:        "truncated",
+			fingerprint: "A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5E",
+			err:         `fingerprint "A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5E" has 39 hex digits, expected 40 for a version 4 key or 64 for a version 5 or 6 key`,
+		},
+		{
+			name:        "not hexadecimal",
+			fingerprint: "A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5EX",
+			err:         `fingerprint "A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5EX" is not hexadecimal`,
+		},
+		{
+			name: "empty",
+			err:  `fingerprint "" has 0 hex digits, expected 40 for a version 4 key or 64 for a version 5 or 6 key`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateFingerprint(test.fingerprint)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package verification
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderGithubAnnotations renders the failed and warning steps of the result as GitHub Actions workflow commands.
+func (r *Result) RenderGithubAnnotations(path string) string {
+	var sb strings.Builder
+	_ = r.WriteGithubAnnotationsTo(&sb, path) // Writing to a strings.Builder does not fail
+	return sb.String()
+}
+
+// WriteGithubAnnotationsTo writes an ::error:: or ::warning:: workflow command to w for each failed or warning step,
+// so that GitHub Actions shows them as annotations on path, or on the workflow run if path is empty. Sub steps are
+// annotated individually and top level steps only if they have no sub steps, the same steps a check run annotates.
+func (r *Result) WriteGithubAnnotationsTo(w io.Writer, path string) error {
+	for _, step := range r.Steps {
+		annotated := step.SubSteps
+		if len(annotated) == 0 {
+			annotated = []*Step{step}
+		}
+		for _, subStep := range annotated {
+			var command string
+			switch subStep.Status {
+			case StatusFailure, StatusError:
+				command = "error"
+			case StatusWarning:
+				command = "warning"
+			default:
+				continue
+			}
+
+			title := subStep.Name
+			if subStep != step {
+				title = fmt.Sprintf("%s: %s", step.Name, subStep.Name)
+			}
+			properties := "title=" + escapeAnnotationProperty(title)
+			if path != "" {
+				properties = "file=" + escapeAnnotationProperty(path) + "," + properties
+			}
+			message := strings.Join(subStep.Errors, "\n")
+			if message == "" {
+				message = string(subStep.Status)
+			}
+			if subStep.DocsURL != "" {
+				message += "\nFor more information, see " + subStep.DocsURL
+			}
+
+			if _, err := fmt.Fprintf(w, "::%s %s::%s\n", command, properties, escapeAnnotationData(message)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// escapeAnnotationData escapes the message of a workflow command, which ends at the end of the line.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property of a workflow command, which also ends at a ',' or the '::' before the
+// message.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
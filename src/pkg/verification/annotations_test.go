@@ -0,0 +1,40 @@
+package verification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderGithubAnnotations(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Validate key", StatusFailure)
+	s.AddStep("Key is not expired", StatusSuccess)
+	failed := s.AddStep("Key can be used for signing", StatusFailure, "key has no signing flag,\nno signing subkey and 100% no luck")
+	failed.DocsURL = "https://example.com/signing"
+	s.AddStep("Key has a separate subkey", StatusWarning, "key has no subkeys")
+	s.AddStep("Key file name matches the key fingerprint", StatusSkipped)
+	result.AddStep("Validate Github user", StatusError, "GitHub is unreachable")
+
+	assert.Equal(t, ""+
+		"::error file=keys/e/example/ABC.asc,title=Validate key%3A Key can be used for signing::key has no signing flag,%0Ano signing subkey and 100%25 no luck%0AFor more information, see https://example.com/signing\n"+
+		"::warning file=keys/e/example/ABC.asc,title=Validate key%3A Key has a separate subkey::key has no subkeys\n"+
+		"::error file=keys/e/example/ABC.asc,title=Validate Github user::GitHub is unreachable\n",
+		result.RenderGithubAnnotations("keys/e/example/ABC.asc"))
+}
+
+func TestRenderGithubAnnotations_NoPath(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Validate key", StatusWarning)
+	s.AddStep("Key has a separate subkey", StatusWarning, "key has no subkeys")
+
+	assert.Equal(t, "::warning title=Validate key%3A Key has a separate subkey::key has no subkeys\n", result.RenderGithubAnnotations(""))
+}
+
+func TestRenderGithubAnnotations_AllPassed(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Validate key", StatusSuccess)
+	s.AddStep("Key is not expired", StatusSuccess)
+
+	assert.Empty(t, result.RenderGithubAnnotations("key.asc"))
+}
@@ -0,0 +1,105 @@
+package verification
+
+import (
+	"bufio"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportedAPI returns the exported identifiers declared by the non-test files of the package. Methods and struct
+// fields are qualified by the name of their type.
+func exportedAPI(t *testing.T) map[string]bool {
+	t.Helper()
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range parsed.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if !decl.Name.IsExported() {
+					continue
+				}
+				if decl.Recv == nil {
+					symbols[decl.Name.Name] = true
+					continue
+				}
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok && ident.IsExported() {
+					symbols[ident.Name+"."+decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							if name.IsExported() {
+								symbols[name.Name] = true
+							}
+						}
+					case *ast.TypeSpec:
+						if !spec.Name.IsExported() {
+							continue
+						}
+						symbols[spec.Name.Name] = true
+						if structType, ok := spec.Type.(*ast.StructType); ok {
+							for _, field := range structType.Fields.List {
+								for _, name := range field.Names {
+									if name.IsExported() {
+										symbols[spec.Name.Name+"."+name.Name] = true
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// TestExportedAPI fails if a symbol of the golden list in testdata/api.txt is no longer exported. Removing one breaks
+// library consumers and needs a new major Version, see the compatibility policy in doc.go. New symbols are added to the
+// list once they are released.
+func TestExportedAPI(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "api.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	exported := exportedAPI(t)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		symbol := strings.TrimSpace(scanner.Text())
+		if symbol == "" || strings.HasPrefix(symbol, "#") {
+			continue
+		}
+		assert.True(t, exported[symbol], "%s was removed from the exported API", symbol)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+package verification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Identifiers of the steps that cannot be filtered. Like the step identifiers they are used to look up translations
+// and are reported in the JSON output.
+const (
+	messageIDValidateKey       = "validate-key"
+	messageIDParse             = "parse"
+	messageIDValidateGithub    = "validate-github"
+	messageIDValidateProviders = "validate-providers"
+	messageIDValidateRelease   = "validate-release"
+	messageIDValidateRotation  = "validate-rotation"
+	messageIDRotation          = "rotation"
+)
+
+// Catalog holds translated step names, keyed by step identifier. Steps without a translation keep their English name.
+type Catalog map[string]string
+
+// catalogs holds the available translations. English is the default and uses the names in the code.
+var catalogs = map[string]Catalog{
+	"en": nil,
+	"de": {
+		messageIDValidateKey:       "GPG-Schlüssel prüfen",
+		messageIDParse:             "Schlüssel ist ein gültiger PGP-Schlüssel",
+		StepIDTrailingData:         "Schlüsseldaten enthalten nur den Schlüssel",
+		StepIDExpiry:               "Schlüssel ist nicht abgelaufen",
+		StepIDValidity:             "Schlüssel läuft innerhalb von %d Jahren ab",
+		StepIDSelfSigAge:           "Selbstsignatur wurde innerhalb von %d Jahren erneuert",
+		StepIDRevocation:           "Schlüssel ist nicht widerrufen",
+		StepIDRevocationSigs:       "Widerrufssignaturen sind gültig",
+		StepIDDenylist:             "Schlüssel ist nicht als kompromittiert bekannt",
+		StepIDRevokers:             "Designierte Widerrufsschlüssel sind bekannt",
+		StepIDSigning:              "Schlüssel kann zum Signieren verwendet werden",
+		StepIDCrossCert:            "Signatur-Unterschlüssel sind kreuzzertifiziert",
+		StepIDTofuCompat:           "OpenTofu kann Signaturen des Schlüssels prüfen",
+		StepIDSubkeys:              "Schlüssel hat einen separaten Unterschlüssel",
+		StepIDEncryption:           "Schlüssel kann zum Verschlüsseln verwendet werden, falls er es angibt",
+		StepIDPreferences:          "Schlüssel gibt akzeptable Hash-Präferenzen an",
+		StepIDSigHashes:            "Eigensignaturen des Schlüssels verwenden kein SHA1",
+		StepIDIdentity:             "Schlüssel hat eine gültige Identität und E-Mail-Adresse. (E-Mail-Adresse ist empfohlen, aber optional)",
+		StepIDSingleIdentity:       "Benutzer-IDs des Schlüssels gehören zu einer einzigen Identität",
+		StepIDPrimaryUID:           "Schlüssel legt genau eine primäre Benutzer-ID fest",
+		StepIDFilename:             "Dateiname des Schlüssels entspricht seinem Fingerabdruck",
+		StepIDSignature:            "Die übermittelte Signatur wurde mit dem Schlüssel erstellt",
+		messageIDValidateGithub:    "GitHub-Benutzer prüfen",
+		StepIDOrgMembership:        "Benutzer ist Mitglied der Organisation %s",
+		StepIDOrgDomain:            "E-Mail-Adresse des Schlüssels gehört zu einer verifizierten Domain der Organisation %s",
+		StepIDGithubAccount:        "GitHub-Konto %s ist ein aktives Benutzerkonto",
+		StepIDGithubEmail:          "E-Mail-Adresse des Schlüssels entspricht der öffentlichen E-Mail-Adresse des GitHub-Kontos %s",
+		StepIDGithubKey:            "Schlüssel ist im GitHub-Konto von %s hinterlegt",
+		messageIDValidateProviders: "Provider-Signaturen prüfen",
+		StepIDProviders:            "Schlüssel hat einen Provider in %s signiert",
+		messageIDValidateRelease:   "Release-Signatur prüfen",
+		StepIDReleaseSignature:     "Schlüssel hat die Prüfsummen des Releases %s signiert",
+		StepIDReleaseChecksums:     "Signierte Prüfsummen stimmen mit den Artefakten des Releases %s überein",
+		messageIDValidateRotation:  "Schlüsselrotation prüfen",
+		messageIDRotation:          "Neuer Schlüssel ersetzt den alten Schlüssel",
+	},
+}
+
+// NewCatalog returns the catalog for the given language. An empty language selects English.
+func NewCatalog(lang string) (Catalog, error) {
+	if lang == "" {
+		return nil, nil
+	}
+	catalog, ok := catalogs[strings.ToLower(lang)]
+	if !ok {
+		languages := make([]string, 0, len(catalogs))
+		for l := range catalogs {
+			languages = append(languages, l)
+		}
+		sort.Strings(languages)
+		return nil, fmt.Errorf("unsupported language %q, expected one of %s", lang, strings.Join(languages, ", "))
+	}
+	return catalog, nil
+}
+
+// StepName returns the translated name of the step with the given identifier, or name if there is no translation.
+func (c Catalog) StepName(id string, name string) string {
+	if translated, ok := c[id]; ok {
+		return translated
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package verification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCatalog(t *testing.T) {
+	english, err := NewCatalog("")
+	assert.NoError(t, err)
+	assert.Equal(t, "Key is not expired", english.StepName(StepIDExpiry, "Key is not expired"))
+
+	german, err := NewCatalog("DE")
+	assert.NoError(t, err)
+	assert.Equal(t, "Schlüssel ist nicht abgelaufen", german.StepName(StepIDExpiry, "Key is not expired"))
+
+	_, err = NewCatalog("xx")
+	assert.ErrorContains(t, err, `unsupported language "xx", expected one of de, en`)
+}
+
+func TestCatalogs_Complete(t *testing.T) {
+	for lang, catalog := range catalogs {
+		if catalog == nil {
+			continue
+		}
+		for _, id := range append(stepIDs, messageIDValidateKey, messageIDParse, messageIDValidateGithub, messageIDValidateProviders, messageIDValidateRelease, messageIDValidateRotation, messageIDRotation) {
+			assert.Contains(t, catalog, id, "%s catalog has no translation for %s", lang, id)
+		}
+	}
+}
+
+func TestVerify_Catalog(t *testing.T) {
+	catalog, err := NewCatalog("de")
+	assert.NoError(t, err)
+
+	result, err := Verify(context.Background(), VerifyKeyOptions{
+		KeyFile: writeTestKey(t),
+		Org:     "example",
+		Catalog: catalog,
+		Github:  fakeGithubClient{member: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "GPG-Schlüssel prüfen", result.Steps[0].Name)
+	assert.Equal(t, "Benutzer ist Mitglied der Organisation example", result.Steps[1].SubSteps[0].Name)
+}
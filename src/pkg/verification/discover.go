@@ -0,0 +1,57 @@
+package verification
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// OrgMatch summarizes how many of the providers of a candidate organization have releases signed by a key.
+type OrgMatch struct {
+	Org             string           `json:"org"`
+	Providers       int              `json:"providers"`        // Providers of the organization in the registry
+	SignedProviders []SignedProvider `json:"signed_providers"` // Providers with at least one signed release
+	SignedReleases  int              `json:"signed_releases"`
+	CheckedReleases int              `json:"checked_releases"` // Releases that could be checked, signed or not
+}
+
+// DiscoverOrgs scans the providers of each candidate organization in providerDataDir for releases signed by the key,
+// for contributors who do not know which organization their provider is published under. The organizations are
+// ranked by the number of providers and then releases the key signed, candidates the key signed nothing in are
+// listed last, in the order given. If latestOnly is set, only the latest release of each provider is checked.
+// Releases that cannot be checked are skipped and returned as errors, the returned error is only set if the providers
+// could not be listed or ctx is done.
+func DiscoverOrgs(ctx context.Context, client GithubClient, key *crypto.Key, providerDataDir string, candidates []string, latestOnly bool) ([]OrgMatch, []error, error) {
+	var matches []OrgMatch
+	var unreachable []error
+	for _, org := range candidates {
+		providers, err := listOrgProviders(providerDataDir, org)
+		if err != nil {
+			return nil, unreachable, err
+		}
+		coverage, orgUnreachable, err := scanProviders(ctx, client, key, providers, latestOnly)
+		unreachable = append(unreachable, orgUnreachable...)
+		if err != nil {
+			return nil, unreachable, err
+		}
+
+		match := OrgMatch{Org: org, Providers: len(providers)}
+		for _, c := range coverage {
+			if len(c.Signed) > 0 {
+				match.SignedProviders = append(match.SignedProviders, SignedProvider{Namespace: c.Namespace, Provider: c.Provider, Versions: c.Signed})
+			}
+			match.SignedReleases += len(c.Signed)
+			match.CheckedReleases += len(c.Signed) + len(c.Unsigned)
+		}
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if len(matches[i].SignedProviders) != len(matches[j].SignedProviders) {
+			return len(matches[i].SignedProviders) > len(matches[j].SignedProviders)
+		}
+		return matches[i].SignedReleases > matches[j].SignedReleases
+	})
+	return matches, unreachable, nil
+}
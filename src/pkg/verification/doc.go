@@ -0,0 +1,12 @@
+// Package verification checks that a GPG key may be added to the registry and renders the result.
+//
+// The exported API follows semantic versioning, tracked by Version. Within a major version, exported functions, types,
+// methods, struct fields, constants and variables are not removed or renamed, and their signatures do not change. New
+// options default to their zero value, so that existing callers keep the same behavior. Step IDs are part of the API,
+// while the wording of step names, remarks and errors is not. The exported symbols are listed in testdata/api.txt and
+// a test fails if one of them disappears.
+package verification
+
+// Version is the version of the exported API of the package. The minor version is raised when symbols or options are
+// added and the major version when they are removed or their behavior changes incompatibly.
+const Version = "1.0.0"
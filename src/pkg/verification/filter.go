@@ -0,0 +1,76 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StepFilter selects which verification steps are run.
+// Parsing the key is a prerequisite for every other step and is therefore always run.
+type StepFilter struct {
+	only map[string]bool
+	skip map[string]bool
+}
+
+// NewStepFilter builds a StepFilter from comma separated lists of step identifiers.
+// An empty "only" list selects all steps.
+func NewStepFilter(only string, skip string) (StepFilter, error) {
+	onlyIDs, err := parseStepIDs(only)
+	if err != nil {
+		return StepFilter{}, fmt.Errorf("invalid list of steps to run: %w", err)
+	}
+	skipIDs, err := parseStepIDs(skip)
+	if err != nil {
+		return StepFilter{}, fmt.Errorf("invalid list of steps to skip: %w", err)
+	}
+	return StepFilter{only: onlyIDs, skip: skipIDs}, nil
+}
+
+func parseStepIDs(list string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	for _, id := range strings.Split(list, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if !isKnownStepID(id) {
+			return nil, fmt.Errorf("unknown step %q, expected one of %s", id, strings.Join(stepIDs, ", "))
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+func isKnownStepID(id string) bool {
+	for _, known := range stepIDs {
+		if known == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled returns true if the step with the given identifier should be run.
+func (f StepFilter) Enabled(id string) bool {
+	if f.skip[id] {
+		return false
+	}
+	return len(f.only) == 0 || f.only[id]
+}
+
+// RunStep runs the step as a sub step of parent, or marks it as skipped if it has been filtered out.
+func (f StepFilter) RunStep(parent *Step, id string, name string, fn func() error) *Step {
+	if !f.Enabled(id) {
+		return parent.SkipStep(name, "filtered").withID(id)
+	}
+	return parent.RunStep(name, fn).withID(id)
+}
+
+// RunStepContext is the context aware variant of RunStep.
+func (f StepFilter) RunStepContext(ctx context.Context, parent *Step, id string, name string, fn func(ctx context.Context) error) *Step {
+	if !f.Enabled(id) {
+		return parent.SkipStep(name, "filtered").withID(id)
+	}
+	return parent.RunStepContext(ctx, name, fn).withID(id)
+}
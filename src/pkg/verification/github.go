@@ -0,0 +1,314 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+// OrganizationClient is the subset of the GitHub API needed to check organization membership.
+type OrganizationClient interface {
+	IsUserInOrganization(username string, org string) (bool, error)
+}
+
+// OrgDomainsClient is implemented by GitHub clients that can list the verified domains of an organization. It is kept
+// apart from GithubClient so that existing implementations keep compiling, the check is skipped for clients without it.
+type OrgDomainsClient interface {
+	GetOrgVerifiedDomains(org string) ([]string, error)
+}
+
+// AssetContextClient is implemented by GitHub clients whose asset downloads can be cancelled. It is kept apart from
+// GithubClient so that existing implementations keep compiling, their downloads are only checked for cancellation
+// before they start.
+type AssetContextClient interface {
+	DownloadAssetContentsContext(ctx context.Context, downloadURL string) ([]byte, error)
+}
+
+// GithubClient is the subset of the GitHub API that is used during verification.
+type GithubClient interface {
+	OrganizationClient
+	GetUserGPGKeys(username string) ([]github.GPGKey, error)
+	GetUser(username string) (github.User, error)
+	DownloadAssetContents(downloadURL string) ([]byte, error)
+	GetReleaseByTag(repository string, tag string) (github.Release, error)
+}
+
+// VerifyGithubUser checks that the GitHub user in opts is a member of the organization, unless opts.SkipOrgCheck or
+// opts.UserNamespace is set, that their account is an active user account and, if opts.CompareGithubKey is set, that
+// the key is registered on their account. If opts.CheckOrgDomains is set, it also checks that the key uses an email on
+// a verified domain of the organization.
+func VerifyGithubUser(ctx context.Context, opts VerifyKeyOptions, key *crypto.Key) *Step {
+	verifyStep := &Step{
+		ID:       messageIDValidateGithub,
+		Name:     opts.Catalog.StepName(messageIDValidateGithub, "Validate Github user"),
+		progress: opts.Progress,
+	}
+	defer verifyStep.reportProgress()
+
+	if opts.SkipOrgCheck || opts.UserNamespace {
+		name := opts.Catalog.stepName(StepIDOrgMembership, opts.namespace())
+		reason := "the organization membership check is disabled"
+		if opts.userNamespace() {
+			reason = "the key is for a personal namespace, organization membership does not apply"
+		}
+		verifyStep.SkipStep(name, reason).withID(StepIDOrgMembership)
+		if opts.CheckOrgDomains {
+			verifyStep.SkipStep(opts.Catalog.stepName(StepIDOrgDomain, opts.namespace()), reason).withID(StepIDOrgDomain)
+		}
+	} else {
+		verifyOrgMembership(ctx, verifyStep, opts, opts.Github, opts.Username, opts.Org)
+		if opts.CheckOrgDomains {
+			verifyOrgDomains(ctx, verifyStep, opts, key)
+		}
+	}
+
+	name := opts.Catalog.stepName(StepIDGithubAccount, opts.Username)
+	accountEvidence := make(map[string]any)
+	accountStep := opts.runStepContext(ctx, verifyStep, StepIDGithubAccount, name, opts.RejectInactiveAccount, func(_ context.Context) error {
+		accountType, err := verifyGithubAccount(opts.Github, opts.Username)
+		if accountType != "" {
+			accountEvidence["account_type"] = accountType
+		}
+		return err
+	})
+	accountStep.addEvidence(accountEvidence)
+
+	if opts.CompareGithubEmail {
+		verifyGithubEmail(ctx, verifyStep, opts, key)
+	}
+
+	if opts.CompareGithubKey {
+		name := opts.Catalog.stepName(StepIDGithubKey, opts.Username)
+		if key == nil {
+			verifyStep.SkipStep(name, "the key could not be parsed").withID(StepIDGithubKey)
+			return verifyStep
+		}
+
+		var keyRemarks []string
+		keyEvidence := make(map[string]any)
+		keyStep := opts.runStepContext(ctx, verifyStep, StepIDGithubKey, name, false, func(_ context.Context) error {
+			githubKeys, err := opts.Github.GetUserGPGKeys(opts.Username)
+			if err != nil {
+				return checkReachable(fmt.Errorf("failed to list the GPG keys of the user: %w", err))
+			}
+			keyID, err := compareGithubKeys(key, githubKeys, opts.CompareGithubKeyExact)
+			if err != nil {
+				return err
+			}
+			keyRemarks = append(keyRemarks, fmt.Sprintf("Matched GitHub key %s", keyID))
+			keyEvidence["github_key_id"] = keyID
+			return nil
+		})
+		keyStep.Remarks = append(keyStep.Remarks, keyRemarks...)
+		keyStep.addEvidence(keyEvidence)
+	}
+
+	return verifyStep
+}
+
+// VerifyOrgMembership checks that the GitHub user is a member of the organization, without verifying a key. It is the
+// same check VerifyGithubUser runs, for tools that only need to know whether a user may act for an organization. The
+// returned step has the identifier StepIDOrgMembership and records the organization as evidence.
+func VerifyOrgMembership(ctx context.Context, client OrganizationClient, username string, org string) *Step {
+	parent := &Step{}
+	return verifyOrgMembership(ctx, parent, VerifyKeyOptions{}, client, username, org)
+}
+
+// verifyOrgMembership runs the organization membership check as a sub step of parent, using the filter and catalog of
+// opts. The default token of GitHub Actions cannot read organization membership, so a permission error within Actions
+// explains how to provide a token that can.
+func verifyOrgMembership(ctx context.Context, parent *Step, opts VerifyKeyOptions, client OrganizationClient, username string, org string) *Step {
+	name := opts.Catalog.stepName(StepIDOrgMembership, org)
+	var remarks []string
+	s := opts.runStepContext(ctx, parent, StepIDOrgMembership, name, false, func(_ context.Context) error {
+		member, err := client.IsUserInOrganization(username, org)
+		if errors.Is(err, github.ErrPermissionDenied) && github.InActions() {
+			remarks = append(remarks, "The GITHUB_TOKEN of GitHub Actions cannot read organization membership. Set GH_TOKEN to a personal access token or a GitHub App token with the read:org scope instead")
+		}
+		if err != nil {
+			return checkReachable(fmt.Errorf("failed to get user: %w", err))
+		}
+		if member {
+			return nil
+		} else {
+			return fmt.Errorf("user is not a member of the organization")
+		}
+	})
+	if s.Status != StatusSkipped {
+		s.AddEvidence("org", org)
+		s.Remarks = append(s.Remarks, remarks...)
+	}
+	if s.Status == StatusFailure {
+		s.AddRemediation(Remediation{
+			Title:       "Make your organization membership public",
+			Description: "Only public members of the organization can be verified. If you are a member, make your membership public and run the verification again.",
+			URL:         "https://docs.github.com/en/account-and-profile/setting-up-and-managing-your-personal-account-on-github/managing-your-membership-in-organizations/publicizing-or-hiding-organization-membership",
+		})
+	}
+	return s
+}
+
+// verifyOrgDomains checks that an email of the key's user IDs is on a verified domain of the organization, or one of
+// its subdomains. The step is skipped if the organization has no verified domains.
+func verifyOrgDomains(ctx context.Context, parent *Step, opts VerifyKeyOptions, key *crypto.Key) {
+	name := opts.Catalog.stepName(StepIDOrgDomain, opts.Org)
+	client, ok := opts.Github.(OrgDomainsClient)
+	switch {
+	case key == nil:
+		parent.SkipStep(name, "the key could not be parsed").withID(StepIDOrgDomain)
+		return
+	case !ok:
+		parent.SkipStep(name, "the GitHub client cannot list verified domains").withID(StepIDOrgDomain)
+		return
+	}
+
+	var noDomains bool
+	var domainRemarks []string
+	domainEvidence := make(map[string]any)
+	domainStep := opts.runStepContext(ctx, parent, StepIDOrgDomain, name, false, func(_ context.Context) error {
+		domains, err := client.GetOrgVerifiedDomains(opts.Org)
+		if err != nil {
+			return checkReachable(fmt.Errorf("failed to list the verified domains of the organization: %w", err))
+		}
+		if len(domains) == 0 {
+			noDomains = true
+			return nil
+		}
+		domainEvidence["verified_domains"] = domains
+
+		var keyDomains []string
+		for _, id := range keyUserIDs(key) {
+			at := strings.LastIndex(id.Email, "@")
+			if at < 0 {
+				continue
+			}
+			domain := strings.ToLower(id.Email[at+1:])
+			for _, verified := range domains {
+				verified = strings.ToLower(verified)
+				if domain == verified || strings.HasSuffix(domain, "."+verified) {
+					domainRemarks = append(domainRemarks, fmt.Sprintf("The user ID %q uses the verified domain %s", id.ID, verified))
+					return nil
+				}
+			}
+			keyDomains = append(keyDomains, domain)
+		}
+		if len(keyDomains) == 0 {
+			return fmt.Errorf("the key has no email to compare with the verified domains of the organization")
+		}
+		sort.Strings(keyDomains)
+		return fmt.Errorf("none of the key's email domains (%s) is a verified domain of the organization", strings.Join(keyDomains, ", "))
+	})
+	if noDomains {
+		domainStep.Status = StatusSkipped
+		domainRemarks = append(domainRemarks, "the organization has no verified domains")
+	}
+	domainStep.Remarks = append(domainStep.Remarks, domainRemarks...)
+	domainStep.addEvidence(domainEvidence)
+}
+
+// verifyGithubAccount checks that the account of the user is neither a bot nor suspended and returns its type, if the
+// account could be found.
+func verifyGithubAccount(client GithubClient, username string) (string, error) {
+	user, err := client.GetUser(username)
+	if errors.Is(err, github.ErrUserNotFound) {
+		return "", fmt.Errorf("the account was not found, it may have been suspended or deleted")
+	}
+	if err != nil {
+		return "", checkReachable(fmt.Errorf("failed to get user: %w", err))
+	}
+	if user.SuspendedAt != nil {
+		return user.Type, fmt.Errorf("the account was suspended at %s", user.SuspendedAt.UTC().Format(time.RFC3339))
+	}
+	if user.Type != "User" {
+		return user.Type, fmt.Errorf("the account is of type %s, not a user account", user.Type)
+	}
+	return user.Type, nil
+}
+
+// verifyGithubEmail checks that an email of the key's user IDs is the public email of the GitHub account. The API only
+// reveals the email the user chose to show on their profile, which GitHub requires to be verified. The step is skipped
+// if the user keeps their email private.
+func verifyGithubEmail(ctx context.Context, parent *Step, opts VerifyKeyOptions, key *crypto.Key) {
+	name := opts.Catalog.stepName(StepIDGithubEmail, opts.Username)
+	if key == nil {
+		parent.SkipStep(name, "the key could not be parsed").withID(StepIDGithubEmail)
+		return
+	}
+
+	var private bool
+	var emailRemarks []string
+	emailStep := opts.runStepContext(ctx, parent, StepIDGithubEmail, name, false, func(_ context.Context) error {
+		user, err := opts.Github.GetUser(opts.Username)
+		if err != nil {
+			return checkReachable(fmt.Errorf("failed to get user: %w", err))
+		}
+		if user.Email == "" {
+			private = true
+			return nil
+		}
+
+		var keyEmails []string
+		for _, id := range keyUserIDs(key) {
+			if id.Email == "" {
+				continue
+			}
+			if strings.EqualFold(id.Email, user.Email) {
+				emailRemarks = append(emailRemarks, fmt.Sprintf("The user ID %q matches the public email of the GitHub account", id.ID))
+				return nil
+			}
+			keyEmails = append(keyEmails, id.Email)
+		}
+		if len(keyEmails) == 0 {
+			return fmt.Errorf("the key has no email to compare with the public email of the GitHub account")
+		}
+		sort.Strings(keyEmails)
+		return fmt.Errorf("none of the key's emails (%s) is the public email of the GitHub account", strings.Join(keyEmails, ", "))
+	})
+	if private {
+		emailStep.Status = StatusSkipped
+		emailRemarks = append(emailRemarks, "the GitHub account does not show a public email to compare with")
+	}
+	emailStep.Remarks = append(emailStep.Remarks, emailRemarks...)
+}
+
+// checkReachable marks err as inconclusive if GitHub could not be reached, so that a network failure is reported as an
+// error of the step instead of a verdict such as the user not being a member.
+func checkReachable(err error) error {
+	if github.IsUnreachable(err) {
+		return inconclusive(fmt.Errorf("GitHub is unreachable: %w", err))
+	}
+	return err
+}
+
+// compareGithubKeys returns the key ID of the GitHub key with the same fingerprint as key. If exact is set, both keys
+// must also serialize to the same packets, so that no user IDs, subkeys or signatures differ. GitHub keys that cannot be
+// parsed are ignored.
+func compareGithubKeys(key *crypto.Key, githubKeys []github.GPGKey, exact bool) (string, error) {
+	for _, githubKey := range githubKeys {
+		parsed, err := gpg.ParseKey(githubKey.RawKey)
+		if err != nil || !strings.EqualFold(parsed.GetFingerprint(), key.GetFingerprint()) {
+			continue
+		}
+		if !exact {
+			return githubKey.KeyID, nil
+		}
+
+		same, err := gpg.SamePublicKey(key, parsed)
+		if err != nil {
+			return "", fmt.Errorf("failed to compare the key with GitHub key %s: %w", githubKey.KeyID, err)
+		}
+		if !same {
+			return "", fmt.Errorf("the key matches GitHub key %s by fingerprint, but its contents differ", githubKey.KeyID)
+		}
+		return githubKey.KeyID, nil
+	}
+	return "", fmt.Errorf("the key with fingerprint %s is not registered on the GitHub account", strings.ToUpper(key.GetFingerprint()))
+}
@@ -0,0 +1,418 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+// githubKey is an Ed25519 key with a single user ID, githubKeyTwoUIDs is the same key after adding a second user ID.
+// Both were generated with gpg.
+const githubKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCD0BYJKwYBBAHaRw8BAQdAvfuLO0VHOeER3QSIW4LAiOM8DtJuPbyzuexx
+tziyeJe0HFRlc3QgVXNlciA8dGVzdEBleGFtcGxlLmNvbT6IkAQTFggAOBYhBEgo
+obiaissgVOjZcM68I+7feg3OBQJq0IPQAhsDBQsJCAcCBhUKCQgLAgQWAgMBAh4B
+AheAAAoJEM68I+7feg3O6QUBAKKfJMU+mynwPmj/vQqPOPHDZnvRGX6TQ7576260
+47rdAP0cH+mJdlvxNuDs8HcoyTr0wPKrXFxzNjpbXeXjOoWKDQ==
+=/CLg
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const githubKeyTwoUIDs = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCD0BYJKwYBBAHaRw8BAQdAvfuLO0VHOeER3QSIW4LAiOM8DtJuPbyzuexx
+tziyeJe0HFRlc3QgVXNlciA8dGVzdEBleGFtcGxlLmNvbT6IkAQTFggAOBYhBEgo
+obiaissgVOjZcM68I+7feg3OBQJq0IPQAhsDBQsJCAcCBhUKCQgLAgQWAgMBAh4B
+AheAAAoJEM68I+7feg3O6QUBAKKfJMU+mynwPmj/vQqPOPHDZnvRGX6TQ7576260
+47rdAP0cH+mJdlvxNuDs8HcoyTr0wPKrXFxzNjpbXeXjOoWKDbQcVGVzdCBVc2Vy
+IDx0ZXN0QGV4YW1wbGUub3JnPoiQBBMWCAA4FiEESCihuJqKyyBU6Nlwzrwj7t96
+Dc4FAmrQg9ACGwMFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQzrwj7t96Dc4I
+pQD9EGXM4IsGaL5qMRHOvUCdXAVbNmgWtGhw+ubjlBtQwpYBALIlIdvum2b1xyYb
+UV3yY80D2tLmJLW4DDIJzKzvi2sP
+=zQ2k
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestVerifyGithubUser_CompareGithubKey(t *testing.T) {
+	key, err := gpg.ParseKey(githubKeyTwoUIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := other.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    []github.GPGKey
+		exact   bool
+		status  Status
+		remarks []string
+		err     string
+	}{
+		{
+			name:    "registered key",
+			keys:    []github.GPGKey{{KeyID: "OTHER", RawKey: otherKey}, {KeyID: "CEBC23EEDF7A0DCE", RawKey: githubKey}},
+			status:  StatusSuccess,
+			remarks: []string{"Matched GitHub key CEBC23EEDF7A0DCE"},
+		},
+		{
+			name:   "registered key with different contents",
+			keys:   []github.GPGKey{{KeyID: "CEBC23EEDF7A0DCE", RawKey: githubKey}},
+			exact:  true,
+			status: StatusFailure,
+			err:    "the key matches GitHub key CEBC23EEDF7A0DCE by fingerprint, but its contents differ",
+		},
+		{
+			name:    "identical registered key",
+			keys:    []github.GPGKey{{KeyID: "CEBC23EEDF7A0DCE", RawKey: githubKeyTwoUIDs}},
+			exact:   true,
+			status:  StatusSuccess,
+			remarks: []string{"Matched GitHub key CEBC23EEDF7A0DCE"},
+		},
+		{
+			name:   "unregistered key",
+			keys:   []github.GPGKey{{KeyID: "OTHER", RawKey: otherKey}, {KeyID: "BROKEN", RawKey: "not a key"}},
+			status: StatusFailure,
+			err:    "the key with fingerprint 4828A1B89A8ACB2054E8D970CEBC23EEDF7A0DCE is not registered on the GitHub account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{
+				Username:              "octocat",
+				Org:                   "octocat",
+				CompareGithubKey:      true,
+				CompareGithubKeyExact: tt.exact,
+				Github:                fakeGithubClient{member: true, gpgKeys: tt.keys},
+			}
+			step := VerifyGithubUser(context.Background(), opts, key)
+			assert.Len(t, step.SubSteps, 3)
+			keyStep := step.SubSteps[2]
+			assert.Equal(t, tt.status, keyStep.Status)
+			if tt.remarks != nil {
+				assert.Equal(t, tt.remarks, keyStep.Remarks)
+			}
+			if tt.status == StatusSuccess {
+				assert.Equal(t, map[string]any{"github_key_id": "CEBC23EEDF7A0DCE"}, keyStep.Evidence)
+			}
+			if tt.err != "" {
+				assert.Contains(t, keyStep.Errors, tt.err)
+			}
+		})
+	}
+}
+
+func TestVerifyGithubUser_Account(t *testing.T) {
+	suspendedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		user   *github.User
+		err    error
+		reject bool
+		status Status
+		errors []string
+	}{
+		{
+			name:   "active user",
+			status: StatusSuccess,
+		},
+		{
+			name:   "bot",
+			user:   &github.User{Login: "release-bot[bot]", Type: "Bot"},
+			status: StatusWarning,
+			errors: []string{"the account is of type Bot, not a user account"},
+		},
+		{
+			name:   "bot rejected",
+			user:   &github.User{Login: "release-bot[bot]", Type: "Bot"},
+			reject: true,
+			status: StatusFailure,
+			errors: []string{"the account is of type Bot, not a user account"},
+		},
+		{
+			name:   "suspended",
+			user:   &github.User{Login: "octocat", Type: "User", SuspendedAt: &suspendedAt},
+			reject: true,
+			status: StatusFailure,
+			errors: []string{"the account was suspended at 2024-03-01T12:00:00Z"},
+		},
+		{
+			name:   "not found",
+			err:    fmt.Errorf("%w: %q", github.ErrUserNotFound, "octocat"),
+			reject: true,
+			status: StatusFailure,
+			errors: []string{"the account was not found, it may have been suspended or deleted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{
+				Username:              "octocat",
+				Org:                   "octocat",
+				RejectInactiveAccount: tt.reject,
+				Github:                fakeGithubClient{member: true, user: tt.user, err: tt.err},
+			}
+			step := VerifyGithubUser(context.Background(), opts, nil)
+			accountStep := step.SubSteps[1]
+			assert.Equal(t, StepIDGithubAccount, accountStep.ID)
+			assert.Equal(t, tt.status, accountStep.Status)
+			assert.Equal(t, tt.errors, accountStep.Errors)
+		})
+	}
+}
+
+func TestVerifyGithubUser_CompareGithubEmail(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		email   string
+		status  Status
+		errors  []string
+		remarks []string
+	}{
+		{
+			name:    "matching email",
+			email:   "Test@Example.com",
+			status:  StatusSuccess,
+			remarks: []string{`The user ID "Test User <test@example.com>" matches the public email of the GitHub account`},
+		},
+		{
+			name:   "different email",
+			email:  "octocat@github.com",
+			status: StatusWarning,
+			errors: []string{"none of the key's emails (test@example.com) is the public email of the GitHub account"},
+		},
+		{
+			name:    "private email",
+			status:  StatusSkipped,
+			remarks: []string{"the GitHub account does not show a public email to compare with"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{
+				Username:           "octocat",
+				Org:                "octocat",
+				CompareGithubEmail: true,
+				Github:             fakeGithubClient{member: true, user: &github.User{Login: "octocat", Type: "User", Email: tt.email}},
+			}
+			step := VerifyGithubUser(context.Background(), opts, key)
+			emailStep := step.SubSteps[2]
+			assert.Equal(t, StepIDGithubEmail, emailStep.ID)
+			assert.Equal(t, tt.status, emailStep.Status)
+			assert.Equal(t, tt.errors, emailStep.Errors)
+			assert.Equal(t, tt.remarks, emailStep.Remarks)
+		})
+	}
+}
+
+func TestVerifyGithubUser_CheckOrgDomains(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@mail.example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		client   GithubClient
+		status   Status
+		errors   []string
+		remarks  []string
+		evidence map[string]any
+	}{
+		{
+			name:     "subdomain of a verified domain",
+			client:   fakeGithubClient{member: true, domains: []string{"example.org", "Example.com"}},
+			status:   StatusSuccess,
+			remarks:  []string{`The user ID "Test User <test@mail.example.com>" uses the verified domain example.com`},
+			evidence: map[string]any{"verified_domains": []string{"example.org", "Example.com"}},
+		},
+		{
+			name:     "unverified domain",
+			client:   fakeGithubClient{member: true, domains: []string{"ample.com"}},
+			status:   StatusWarning,
+			errors:   []string{"none of the key's email domains (mail.example.com) is a verified domain of the organization"},
+			evidence: map[string]any{"verified_domains": []string{"ample.com"}},
+		},
+		{
+			name:    "no verified domains",
+			client:  fakeGithubClient{member: true},
+			status:  StatusSkipped,
+			remarks: []string{"the organization has no verified domains"},
+		},
+		{
+			name:    "client cannot list domains",
+			client:  struct{ GithubClient }{fakeGithubClient{member: true, domains: []string{"example.com"}}},
+			status:  StatusSkipped,
+			remarks: []string{"the GitHub client cannot list verified domains"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{Username: "octocat", Org: "example", CheckOrgDomains: true, Github: tt.client}
+			step := VerifyGithubUser(context.Background(), opts, key)
+			domainStep := step.SubSteps[1]
+			assert.Equal(t, StepIDOrgDomain, domainStep.ID)
+			assert.Equal(t, "Key email uses a verified domain of the organization example", domainStep.Name)
+			assert.Equal(t, tt.status, domainStep.Status)
+			assert.Equal(t, tt.errors, domainStep.Errors)
+			assert.Equal(t, tt.remarks, domainStep.Remarks)
+			assert.Equal(t, tt.evidence, domainStep.Evidence)
+		})
+	}
+}
+
+func TestVerifyOrgMembership(t *testing.T) {
+	tests := []struct {
+		name   string
+		client fakeGithubClient
+		status Status
+		errors []string
+	}{
+		{
+			name:   "member",
+			client: fakeGithubClient{member: true},
+			status: StatusSuccess,
+		},
+		{
+			name:   "not a member",
+			client: fakeGithubClient{member: false},
+			status: StatusFailure,
+			errors: []string{"user is not a member of the organization"},
+		},
+		{
+			name:   "api error",
+			client: fakeGithubClient{err: fmt.Errorf("rate limited")},
+			status: StatusFailure,
+			errors: []string{"failed to get user: rate limited"},
+		},
+		{
+			name:   "github unreachable",
+			client: fakeGithubClient{err: &url.Error{Op: "Get", URL: "https://api.github.com/orgs/example/public_members/octocat", Err: errors.New("connection refused")}},
+			status: StatusError,
+			errors: []string{`GitHub is unreachable: failed to get user: Get "https://api.github.com/orgs/example/public_members/octocat": connection refused`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := VerifyOrgMembership(context.Background(), tt.client, "octocat", "example")
+			assert.Equal(t, StepIDOrgMembership, step.ID)
+			assert.Equal(t, "User is a member of the organization example", step.Name)
+			assert.Equal(t, tt.status, step.Status)
+			assert.Equal(t, tt.errors, step.Errors)
+			assert.Equal(t, map[string]any{"org": "example"}, step.Evidence)
+			if tt.status == StatusFailure {
+				assert.Len(t, step.Remediations, 1)
+				assert.Equal(t, "Make your organization membership public", step.Remediations[0].Title)
+			} else {
+				assert.Empty(t, step.Remediations)
+			}
+		})
+	}
+}
+
+func TestVerifyOrgMembership_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	step := VerifyOrgMembership(ctx, fakeGithubClient{member: true}, "octocat", "example")
+	assert.Equal(t, StatusSkipped, step.Status)
+	assert.Equal(t, []string{"cancelled"}, step.Remarks)
+}
+
+func TestVerifyOrgMembership_ActionsToken(t *testing.T) {
+	client := fakeGithubClient{err: fmt.Errorf("%w: status code 403", github.ErrPermissionDenied)}
+	hint := "The GITHUB_TOKEN of GitHub Actions cannot read organization membership. Set GH_TOKEN to a personal access token or a GitHub App token with the read:org scope instead"
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	step := VerifyOrgMembership(context.Background(), client, "octocat", "example")
+	assert.Equal(t, StatusFailure, step.Status)
+	assert.Equal(t, hint, step.Remarks[0])
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	step = VerifyOrgMembership(context.Background(), client, "octocat", "example")
+	assert.NotContains(t, step.Remarks, hint)
+}
+
+func TestVerifyGithubUser_SkipOrgCheck(t *testing.T) {
+	opts := VerifyKeyOptions{
+		Username:     "octocat",
+		SkipOrgCheck: true,
+		Github:       fakeGithubClient{member: false},
+	}
+	step := VerifyGithubUser(context.Background(), opts, nil)
+	assert.False(t, step.DidFail())
+
+	orgStep := step.SubSteps[0]
+	assert.Equal(t, StepIDOrgMembership, orgStep.ID)
+	assert.Equal(t, StatusSkipped, orgStep.Status)
+	assert.Equal(t, "User is a member of the organization octocat", orgStep.Name)
+	assert.Equal(t, []string{"the key is for a personal namespace, organization membership does not apply"}, orgStep.Remarks)
+
+	// The account is still checked
+	assert.Equal(t, StepIDGithubAccount, step.SubSteps[1].ID)
+	assert.Equal(t, StatusSuccess, step.SubSteps[1].Status)
+}
+
+func TestVerifyGithubUser_SkipOrgCheckWithOrg(t *testing.T) {
+	opts := VerifyKeyOptions{
+		Username:     "octocat",
+		Org:          "example",
+		SkipOrgCheck: true,
+		Github:       fakeGithubClient{member: false},
+	}
+	step := VerifyGithubUser(context.Background(), opts, nil)
+	orgStep := step.SubSteps[0]
+	assert.Equal(t, StatusSkipped, orgStep.Status)
+	assert.Equal(t, "User is a member of the organization example", orgStep.Name)
+	assert.Equal(t, []string{"the organization membership check is disabled"}, orgStep.Remarks)
+}
+
+func TestVerifyGithubUser_Unreachable(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := VerifyKeyOptions{
+		Username:         "octocat",
+		Org:              "example",
+		CompareGithubKey: true,
+		Github:           fakeGithubClient{err: &url.Error{Op: "Get", URL: "https://api.github.com", Err: errors.New("no such host")}},
+	}
+
+	step := VerifyGithubUser(context.Background(), opts, key)
+	assert.True(t, step.DidFail())
+	assert.Len(t, step.SubSteps, 3)
+	for _, subStep := range step.SubSteps {
+		// Neither "not a member" nor an inactive account, nor an unregistered key
+		assert.Equal(t, StatusError, subStep.Status, subStep.ID)
+		assert.Len(t, subStep.Errors, 1)
+		assert.Contains(t, subStep.Errors[0], "GitHub is unreachable: ")
+	}
+}
@@ -0,0 +1,114 @@
+package verification
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// genericNameParts are words that commonly appear in the user IDs of signing keys without telling anything about who
+// owns the key, so they do not make two user IDs related.
+var genericNameParts = map[string]bool{
+	"automation": true,
+	"bot":        true,
+	"gpg":        true,
+	"key":        true,
+	"pgp":        true,
+	"release":    true,
+	"releases":   true,
+	"signing":    true,
+	"team":       true,
+	"the":        true,
+}
+
+// userID is the name and email of a user ID, as parsed by the OpenPGP library.
+type userID struct {
+	ID    string // The full user ID
+	Name  string
+	Email string
+}
+
+// keyUserIDs returns the user IDs of the key.
+func keyUserIDs(key *crypto.Key) []userID {
+	var ids []userID
+	for id, identity := range key.GetEntity().Identities {
+		ids = append(ids, userID{ID: id, Name: identity.UserId.Name, Email: identity.UserId.Email})
+	}
+	return ids
+}
+
+// distinctIdentities groups the user IDs that appear to belong to the same person. This is a heuristic: user IDs are
+// considered related if their emails share a domain or their names share a word other than the generic ones. Groups
+// and the user IDs in them are sorted.
+func distinctIdentities(ids []userID) [][]string {
+	// Union-find over the user IDs, there are only a handful per key
+	parent := make([]int, len(ids))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			if relatedUserIDs(ids[i], ids[j]) {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := make(map[int][]string)
+	for i, id := range ids {
+		root := find(i)
+		groups[root] = append(groups[root], id.ID)
+	}
+	result := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group)
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// relatedUserIDs reports whether two user IDs appear to belong to the same person.
+func relatedUserIDs(a userID, b userID) bool {
+	if domainA, domainB := emailDomain(a.Email), emailDomain(b.Email); domainA != "" && domainA == domainB {
+		return true
+	}
+	partsA := nameParts(a.Name)
+	for part := range nameParts(b.Name) {
+		if partsA[part] {
+			return true
+		}
+	}
+	return false
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+func nameParts(name string) map[string]bool {
+	parts := make(map[string]bool)
+	for _, part := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '.' || r == ',' || r == '(' || r == ')'
+	}) {
+		if len([]rune(part)) > 1 && !genericNameParts[part] {
+			parts[part] = true
+		}
+	}
+	return parts
+}
@@ -0,0 +1,129 @@
+package verification
+
+import (
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistinctIdentities(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []userID
+		expected [][]string
+	}{
+		{
+			name:     "single user ID",
+			ids:      []userID{{ID: "Jane Doe <jane@example.com>", Name: "Jane Doe", Email: "jane@example.com"}},
+			expected: [][]string{{"Jane Doe <jane@example.com>"}},
+		},
+		{
+			name: "same person with work and private email",
+			ids: []userID{
+				{ID: "Jane Doe <jane@example.com>", Name: "Jane Doe", Email: "jane@example.com"},
+				{ID: "Jane Doe <jdoe@work.example>", Name: "Jane Doe", Email: "jdoe@work.example"},
+			},
+			expected: [][]string{{"Jane Doe <jane@example.com>", "Jane Doe <jdoe@work.example>"}},
+		},
+		{
+			name: "same organization",
+			ids: []userID{
+				{ID: "Example Releases <releases@example.com>", Name: "Example Releases", Email: "releases@example.com"},
+				{ID: "Jane Doe <jane@EXAMPLE.com>", Name: "Jane Doe", Email: "jane@EXAMPLE.com"},
+			},
+			expected: [][]string{{"Example Releases <releases@example.com>", "Jane Doe <jane@EXAMPLE.com>"}},
+		},
+		{
+			name: "related through a third user ID",
+			ids: []userID{
+				{ID: "Jane Doe <jane@example.com>", Name: "Jane Doe", Email: "jane@example.com"},
+				{ID: "J. Doe <doe@other.example>", Name: "J. Doe", Email: "doe@other.example"},
+				{ID: "Signing Key <signing@other.example>", Name: "Signing Key", Email: "signing@other.example"},
+			},
+			expected: [][]string{{"J. Doe <doe@other.example>", "Jane Doe <jane@example.com>", "Signing Key <signing@other.example>"}},
+		},
+		{
+			name: "unrelated people",
+			ids: []userID{
+				{ID: "Jane Doe <jane@example.com>", Name: "Jane Doe", Email: "jane@example.com"},
+				{ID: "John Smith Signing Key <john@other.example>", Name: "John Smith Signing Key", Email: "john@other.example"},
+				{ID: "Release Signing Key", Name: "Release Signing Key"},
+			},
+			expected: [][]string{
+				{"Jane Doe <jane@example.com>"},
+				{"John Smith Signing Key <john@other.example>"},
+				{"Release Signing Key"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, distinctIdentities(tt.ids))
+		})
+	}
+}
+
+// twoPeopleKey has the user IDs "Jane Doe <jane@example.com>" and "John Smith <john@other.example>".
+const twoPeopleKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCGPxYJKwYBBAHaRw8BAQdA52Y8JO9Y18gTdVkvyO5CmgXyeYrkGn3ElRnU
+QDbH2C20G0phbmUgRG9lIDxqYW5lQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEXQNT
+gw2UEmjM0f8yLn8LmDH5EPEFAmrQhj8CGwMFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQLn8LmDH5EPHu/QD/dNXP0tz9esVX5bEEoNON3RGiRkqSz45ZRLW8CQ/a
+4OYBALm7LZd8PE5rS9MxU9fERROiyg4Lpz4mm7HNFdzF48wLtB9Kb2huIFNtaXRo
+IDxqb2huQG90aGVyLmV4YW1wbGU+iJAEExYIADgWIQRdA1ODDZQSaMzR/zIufwuY
+MfkQ8QUCatCGPwIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRAufwuYMfkQ
+8bEPAP9KuEg2CjNn6OAxXd/IskZHqCxOnO3Z4BtCnwZ9Ws+IBwEArfQgyDpVqKYf
+hAeir43WR33hJw2XmX/iKzEtrZZO6AM=
+=muBm
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestVerifyKey_SingleIdentity(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		status  Status
+		remarks []string
+	}{
+		{
+			name:   "single identity",
+			key:    publicKey,
+			status: StatusSuccess,
+		},
+		{
+			name:   "unrelated user IDs",
+			key:    twoPeopleKey,
+			status: StatusWarning,
+			remarks: []string{
+				"Identity 1: Jane Doe <jane@example.com>",
+				"Identity 2: John Smith <john@other.example>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _ := VerifyKey(VerifyKeyOptions{KeyData: []byte(tt.key)})
+			status := StatusNotRun
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDSingleIdentity {
+					status = s.Status
+					assert.Equal(t, tt.remarks, s.Remarks)
+				}
+			}
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package verification
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// RenderJUnit renders the result as a JUnit XML report, for CI systems that show test results in their dashboards.
+func (r *Result) RenderJUnit() string {
+	var sb strings.Builder
+	_ = r.WriteJUnitTo(&sb) // Writing to a strings.Builder does not fail
+	return sb.String()
+}
+
+// WriteJUnitTo writes the result to w as a JUnit XML report. Each top level step becomes a test suite and each of its
+// sub steps a test case, or the step itself if it has none. Failures and errors are reported as such, skipped and not
+// run steps as skipped, and warnings as passing test cases whose output holds the warning, as JUnit has no warnings.
+func (r *Result) WriteJUnitTo(w io.Writer) error {
+	report := junitTestSuites{Name: "verify-gpg-key"}
+	for _, step := range r.Steps {
+		suite := junitTestSuite{Name: step.Name}
+		cases := step.SubSteps
+		if len(cases) == 0 {
+			cases = []*Step{step}
+		}
+		for _, testStep := range cases {
+			testCase := newJUnitTestCase(step, testStep)
+			suite.Tests++
+			switch {
+			case testCase.Failure != nil:
+				suite.Failures++
+			case testCase.Error != nil:
+				suite.Errors++
+			case testCase.Skipped != nil:
+				suite.Skipped++
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// newJUnitTestCase converts a step into a test case of the suite of its parent.
+func newJUnitTestCase(parent *Step, step *Step) junitTestCase {
+	className := parent.ID
+	if className == "" {
+		className = parent.Name
+	}
+	testCase := junitTestCase{Name: step.Name, ClassName: className}
+
+	details := strings.Join(step.Errors, "\n")
+	var message string
+	if len(step.Errors) > 0 {
+		message = step.Errors[0]
+	}
+	output := step.Remarks
+	switch step.Status {
+	case StatusFailure:
+		testCase.Failure = &junitMessage{Message: message, Text: details}
+	case StatusError:
+		testCase.Error = &junitMessage{Message: message, Text: details}
+	case StatusSkipped, StatusNotRun:
+		testCase.Skipped = &junitMessage{Message: strings.Join(step.Remarks, "; ")}
+		output = nil
+	case StatusWarning:
+		for _, err := range step.Errors {
+			output = append(output, "warning: "+err)
+		}
+	}
+	if step.DocsURL != "" && (step.Status == StatusFailure || step.Status == StatusWarning) {
+		output = append(output, "For more information, see "+step.DocsURL)
+	}
+	testCase.SystemOut = strings.Join(output, "\n")
+	return testCase
+}
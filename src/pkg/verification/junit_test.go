@@ -0,0 +1,49 @@
+package verification
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderJUnit(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Validate <key>", StatusSuccess)
+	s.ID = messageIDValidateKey
+	s.AddStep("Key is not expired", StatusSuccess)
+	failed := s.AddStep("Key can be used for signing", StatusFailure, `key has no "signing" flag & no subkey`)
+	failed.DocsURL = "https://example.com/signing"
+	s.AddStep("Key has a separate subkey", StatusWarning, "key has no subkeys")
+	skipped := s.AddStep("Key file name matches the key fingerprint", StatusSkipped)
+	skipped.Remarks = append(skipped.Remarks, "filtered")
+	result.AddStep("Validate Github user", StatusError, "GitHub is unreachable")
+
+	rendered := result.RenderJUnit()
+	assert.Equal(t, xml.Header+`<testsuites name="verify-gpg-key" tests="5" failures="1" errors="1" skipped="1">
+  <testsuite name="Validate &lt;key&gt;" tests="4" failures="1" errors="0" skipped="1">
+    <testcase name="Key is not expired" classname="validate-key"></testcase>
+    <testcase name="Key can be used for signing" classname="validate-key">
+      <failure message="key has no &#34;signing&#34; flag &amp; no subkey">key has no &#34;signing&#34; flag &amp; no subkey</failure>
+      <system-out>For more information, see https://example.com/signing</system-out>
+    </testcase>
+    <testcase name="Key has a separate subkey" classname="validate-key">
+      <system-out>warning: key has no subkeys</system-out>
+    </testcase>
+    <testcase name="Key file name matches the key fingerprint" classname="validate-key">
+      <skipped message="filtered"></skipped>
+    </testcase>
+  </testsuite>
+  <testsuite name="Validate Github user" tests="1" failures="0" errors="1" skipped="0">
+    <testcase name="Validate Github user" classname="Validate Github user">
+      <error message="GitHub is unreachable">GitHub is unreachable</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, rendered)
+
+	// The report is well-formed
+	var parsed junitTestSuites
+	assert.NoError(t, xml.Unmarshal([]byte(rendered), &parsed))
+	assert.Equal(t, "Validate <key>", parsed.Suites[0].Name)
+}
@@ -0,0 +1,708 @@
+package verification
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"golang.org/x/net/idna"
+
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+// defaultMaxValidityYears is the validity period beyond which keys are reported if VerifyKeyOptions.MaxValidityYears
+// is not set.
+const defaultMaxValidityYears = 10
+
+// maxClockSkew is how far in the future a key may have been created, to allow for the clock of the system that
+// generated it being slightly ahead.
+const maxClockSkew = time.Hour
+
+// parseKey parses the key read by VerifyKey. Every other step works on the parsed key, it is a variable so tests can
+// make sure the key is only parsed once.
+var parseKey = gpg.ParseKeyBytes
+
+// readKey reads the key data either from the given environment variable (base64 encoded) or from the filesystem.
+func readKey(location string, envName string) ([]byte, error) {
+	if envName != "" {
+		encoded, ok := os.LookupEnv(envName)
+		if !ok || encoded == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", envName)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s does not contain valid base64: %w", envName, err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return data, nil
+}
+
+// VerifyKey loads and parses the GPG key and runs all key related checks on it.
+// The parsed key is returned alongside the step, or nil if it could not be loaded.
+func VerifyKey(opts VerifyKeyOptions) (*Step, *crypto.Key) {
+	verifyStep := &Step{
+		ID:       messageIDValidateKey,
+		Name:     opts.Catalog.StepName(messageIDValidateKey, "Validate GPG key"),
+		progress: opts.Progress,
+	}
+	defer verifyStep.reportProgress()
+
+	data := opts.KeyData
+	if data == nil {
+		var err error
+		data, err = readKey(opts.KeyFile, opts.KeyEnv)
+		if err != nil {
+			verifyStep.AddError(err)
+			verifyStep.Status = StatusFailure
+			return verifyStep, nil
+		}
+	}
+
+	var key *crypto.Key
+	parseStep := verifyStep.RunStep(opts.Catalog.StepName(messageIDParse, "Key is a valid PGP key"), func() error {
+		k, err := parseKey(data)
+		if err != nil {
+			var crossErr *gpg.SubkeyCrossCertificationError
+			if errors.As(err, &crossErr) {
+				return fmt.Errorf("could not parse key, a signing subkey has no valid primary key binding signature: %w", err)
+			}
+			return fmt.Errorf("could not parse key: %w", err)
+		}
+		key = k
+		return nil
+	}).withID(messageIDParse)
+
+	if key == nil {
+		// The previous step failed.
+		return verifyStep, nil
+	}
+	parseStep.AddEvidence("fingerprint", strings.ToUpper(key.GetFingerprint()))
+	parseStep.AddEvidence("algorithm", gpg.KeyAlgorithm(key))
+	parseStep.AddEvidence("created", key.GetEntity().PrimaryKey.CreationTime.UTC().Format(time.RFC3339))
+	if _, issues, err := gpg.NormalizePacketOrder(data); err == nil && len(issues) > 0 {
+		parseStep.Remarks = append(parseStep.Remarks, fmt.Sprintf("The key's packets are in an unusual order and were put back in order: %s", strings.Join(issues, ", ")))
+		parseStep.AddEvidence("packet_order_issues", issues)
+	}
+
+	opts.runStep(verifyStep, StepIDTrailingData, opts.Catalog.stepName(StepIDTrailingData), opts.RejectTrailingData, func() error {
+		trailing := gpg.TrailingData(data)
+		if trailing == nil {
+			return nil
+		}
+		return fmt.Errorf("found %s after the public key block, it was ignored", describeTrailingData(trailing))
+	})
+
+	now := opts.now()
+	opts.runStep(verifyStep, StepIDExpiry, opts.Catalog.stepName(StepIDExpiry), false, func() error {
+		created := key.GetEntity().PrimaryKey.CreationTime
+		if created.After(now.Add(maxClockSkew)) {
+			return fmt.Errorf("key was created in the future, at %s, check the clock of the system that generated it", created.UTC().Format(time.RFC3339))
+		}
+		// The OpenPGP library considers keys that are not valid yet as expired
+		at := now
+		if created.After(at) {
+			at = created
+		}
+		if gpg.IsExpired(key, at) {
+			return fmt.Errorf("key is expired")
+		}
+		return nil
+	})
+
+	maxValidityYears := opts.MaxValidityYears
+	if maxValidityYears == 0 {
+		maxValidityYears = defaultMaxValidityYears
+	}
+	var validityRemarks []string
+	// Long lived keys are a security smell, not a reason to reject them
+	validityStep := opts.runStep(verifyStep, StepIDValidity, opts.Catalog.stepName(StepIDValidity, maxValidityYears), false, func() error {
+		remarks, err := checkValidity(gpg.SigningKeyValidity(key), maxValidityYears, now)
+		validityRemarks = remarks
+		return err
+	})
+	validityStep.Remarks = append(validityStep.Remarks, validityRemarks...)
+
+	if opts.MaxSelfSigAgeYears > 0 {
+		var ageRemarks []string
+		ageEvidence := make(map[string]any)
+		ageStep := opts.runStep(verifyStep, StepIDSelfSigAge, opts.Catalog.stepName(StepIDSelfSigAge, opts.MaxSelfSigAgeYears), false, func() error {
+			latest := gpg.LatestSelfSignature(key)
+			if latest.IsZero() {
+				// Reported by the identity check
+				return nil
+			}
+			ageEvidence["self_signature_date"] = latest.Format(time.RFC3339)
+			ageRemarks = append(ageRemarks, fmt.Sprintf("The latest self-signature was made at %s", latest.Format(time.RFC3339)))
+			if latest.AddDate(opts.MaxSelfSigAgeYears, 0, 0).Before(now) {
+				return fmt.Errorf("the latest self-signature is older than %d years, please renew it, for example by updating the expiry with \"gpg --quick-set-expire\", and submit the key again", opts.MaxSelfSigAgeYears)
+			}
+			return nil
+		})
+		ageStep.Remarks = append(ageStep.Remarks, ageRemarks...)
+		ageStep.addEvidence(ageEvidence)
+	}
+
+	designatedRevocations, designatedInvalid, designatedErr := checkDesignatedRevocations(opts, data, now)
+	superseded := false
+	revocationStep := opts.runStep(verifyStep, StepIDRevocation, opts.Catalog.stepName(StepIDRevocation), false, func() error {
+		if designatedErr != nil {
+			return designatedErr
+		}
+		revocations := gpg.Revocations(key, now)
+		if len(revocations) == 0 && len(designatedRevocations) == 0 {
+			return nil
+		}
+		superseded = true
+		reasons := make([]string, 0, len(revocations)+len(designatedRevocations))
+		for _, r := range revocations {
+			reasons = append(reasons, r.String())
+			superseded = superseded && r.Superseded()
+		}
+		for _, r := range designatedRevocations {
+			reasons = append(reasons, r.reason)
+			superseded = superseded && r.superseded
+		}
+		return fmt.Errorf("key is revoked: %s", strings.Join(reasons, "; "))
+	})
+	if superseded && opts.AllowSuperseded {
+		// Key rotation, the contributor only needs to submit the new key
+		revocationStep.FailureToWarning()
+		revocationStep.Remarks = append(revocationStep.Remarks, "The key was superseded by a new key, please submit the superseding key instead")
+	}
+
+	var invalidRevocations []string
+	invalidRevocationsStep := opts.runStep(verifyStep, StepIDRevocationSigs, opts.Catalog.stepName(StepIDRevocationSigs), false, func() error {
+		invalid, err := gpg.InvalidRevocations(data)
+		if err != nil {
+			return fmt.Errorf("could not read revocation signatures: %w", err)
+		}
+		invalid = append(invalid, designatedInvalid...)
+		for _, r := range invalid {
+			invalidRevocations = append(invalidRevocations, r.String())
+		}
+		if len(invalid) > 0 {
+			return fmt.Errorf("invalid revocation signature present, it was ignored: %s", strings.Join(invalidRevocations, "; "))
+		}
+		return nil
+	})
+	if len(invalidRevocations) > 0 {
+		invalidRevocationsStep.AddEvidence("invalid_revocations", invalidRevocations)
+	}
+
+	if opts.Denylist != nil {
+		opts.runStep(verifyStep, StepIDDenylist, opts.Catalog.stepName(StepIDDenylist), false, func() error {
+			return checkDenylist(key, opts.Denylist)
+		})
+	}
+
+	var revokerRemarks []string
+	revokersStep := opts.runStep(verifyStep, StepIDRevokers, opts.Catalog.stepName(StepIDRevokers), false, func() error {
+		revokers, err := gpg.DesignatedRevokers(data)
+		if err != nil {
+			return fmt.Errorf("could not read designated revokers: %w", err)
+		}
+		if len(revokers) == 0 {
+			revokerRemarks = append(revokerRemarks, "The key has no designated revokers")
+			return nil
+		}
+
+		var errs []error
+		for _, r := range revokers {
+			remark := fmt.Sprintf("Revocation of this key is delegated to the key %s", r.Fingerprint)
+			if r.Sensitive {
+				remark += " (marked sensitive)"
+			}
+			revokerRemarks = append(revokerRemarks, remark)
+
+			if namespace := opts.namespace(); opts.KeyDataDir != "" && namespace != "" {
+				located, err := locateRegistryKey(opts.KeyDataDir, namespace, r.Fingerprint)
+				if err != nil {
+					errs = append(errs, err)
+				} else if !located {
+					errs = append(errs, fmt.Errorf("designated revoker %s could not be located among the registry keys of %s", r.Fingerprint, namespace))
+				}
+			}
+		}
+		return errors.Join(errs...)
+	})
+	revokersStep.Remarks = append(revokersStep.Remarks, revokerRemarks...)
+
+	var signingRemarks []string
+	usageEvidence := make(map[string][]string)
+	signingStep := opts.runStep(verifyStep, StepIDSigning, opts.Catalog.stepName(StepIDSigning), false, func() error {
+		// Only the sign flag counts, keys on OpenPGP cards often have an authentication subkey of a signing algorithm
+		for _, usage := range gpg.KeyUsages(key) {
+			signingRemarks = append(signingRemarks, fmt.Sprintf("Usage of the %s", usage))
+			usageEvidence[usage.KeyID] = usage.Flags
+		}
+		switch gpg.KeySigningCapability(key) {
+		case gpg.SigningCapabilityNone:
+			return fmt.Errorf("no signing-capable (sub)key found; this key can only certify/encrypt")
+		case gpg.SigningCapabilityPrimary:
+			signingRemarks = append(signingRemarks, "The primary key is signing-capable")
+		case gpg.SigningCapabilitySubkey:
+			signingRemarks = append(signingRemarks, "A subkey is signing-capable")
+		}
+		if !key.CanVerify() {
+			return fmt.Errorf("key cannot be used for signing")
+		}
+		return nil
+	})
+	signingStep.Remarks = append(signingStep.Remarks, signingRemarks...)
+	if len(usageEvidence) > 0 {
+		signingStep.AddEvidence("key_usage", usageEvidence)
+	}
+
+	var crossCertRemarks []string
+	crossCertStep := opts.runStep(verifyStep, StepIDCrossCert, opts.Catalog.stepName(StepIDCrossCert), false, func() error {
+		certifications := gpg.CheckSigningSubkeyCrossCertifications(key)
+		if len(certifications) == 0 {
+			crossCertRemarks = append(crossCertRemarks, "The key has no signing subkeys")
+		}
+
+		var errs []error
+		for _, c := range certifications {
+			if c.Err != nil {
+				errs = append(errs, fmt.Errorf("signing subkey %s has no valid primary key binding signature: %w", c.KeyID, c.Err))
+				continue
+			}
+			crossCertRemarks = append(crossCertRemarks, fmt.Sprintf("Signing subkey %s is cross-certified", c.KeyID))
+		}
+		return errors.Join(errs...)
+	})
+	crossCertStep.Remarks = append(crossCertStep.Remarks, crossCertRemarks...)
+
+	if opts.CheckTofuCompat {
+		var tofuRemarks []string
+		var tofuKeys []map[string]any
+		tofuStep := opts.runStep(verifyStep, StepIDTofuCompat, opts.Catalog.stepName(StepIDTofuCompat), false, func() error {
+			signingKeys := gpg.TofuSigningKeys(key)
+			if len(signingKeys) == 0 {
+				return fmt.Errorf("no (sub)key is flagged for signing, OpenTofu only verifies provider signatures with keys flagged for signing")
+			}
+
+			var errs []error
+			for _, k := range signingKeys {
+				tofuKeys = append(tofuKeys, map[string]any{"key_id": k.KeyID, "algorithm": k.Algorithm, "compatible": k.Compatible()})
+				if k.Compatible() {
+					tofuRemarks = append(tofuRemarks, fmt.Sprintf("OpenTofu can verify signatures made by %s signing key %s", k.Algorithm, k.KeyID))
+					continue
+				}
+				errs = append(errs, fmt.Errorf("OpenTofu cannot verify signatures made by signing key %s: %s", k.KeyID, strings.Join(k.Problems, "; ")))
+			}
+			if len(errs) < len(signingKeys) {
+				// The signature names the (sub)key that made it, an incompatible key can be left unused
+				for _, err := range errs {
+					tofuRemarks = append(tofuRemarks, err.Error())
+				}
+				return nil
+			}
+			return errors.Join(errs...)
+		})
+		tofuStep.Remarks = append(tofuStep.Remarks, tofuRemarks...)
+		if tofuKeys != nil {
+			tofuStep.AddEvidence("signing_keys", tofuKeys)
+		}
+	}
+
+	// A certify-only primary key with separate subkeys limits the damage of a compromised signing key, but keys
+	// without subkeys work and must not be rejected
+	subkeys := len(key.GetEntity().Subkeys)
+	subkeysStep := opts.runStep(verifyStep, StepIDSubkeys, opts.Catalog.stepName(StepIDSubkeys), false, func() error {
+		if subkeys == 0 {
+			return fmt.Errorf("the key has no subkeys, its primary key is used for everything. Consider a certify-only primary key with a separate signing subkey")
+		}
+		return nil
+	})
+	if subkeysStep.Status != StatusSkipped {
+		subkeysStep.AddEvidence("subkeys", subkeys)
+	}
+
+	if opts.CheckEncryption {
+		var encryptionRemarks []string
+		encryptionEvidence := make(map[string]any)
+		encryptionStep := opts.runStep(verifyStep, StepIDEncryption, opts.Catalog.stepName(StepIDEncryption), false, func() error {
+			encryptionKeys := gpg.EncryptionKeys(key, now)
+			if len(encryptionKeys) == 0 {
+				encryptionRemarks = append(encryptionRemarks, "The key does not declare encryption capability, which is not needed to sign providers")
+				return nil
+			}
+			statuses := make(map[string]string, len(encryptionKeys))
+			for _, k := range encryptionKeys {
+				encryptionRemarks = append(encryptionRemarks, "Encryption "+k.String())
+				statuses[k.KeyID] = k.Status()
+			}
+			encryptionEvidence["encryption_keys"] = statuses
+
+			keyID, err := gpg.CheckEncryption(key, now)
+			if err != nil {
+				return fmt.Errorf("the key declares encryption capability, but a message cannot be encrypted to it: %w", err)
+			}
+			encryptionRemarks = append(encryptionRemarks, fmt.Sprintf("A test message was encrypted to %s", keyID))
+			return nil
+		})
+		encryptionStep.Remarks = append(encryptionStep.Remarks, encryptionRemarks...)
+		encryptionStep.addEvidence(encryptionEvidence)
+	}
+
+	// The preferences are informational unless SHA-1 is rejected or the severity of the step is overridden
+	_, overridden := opts.Severity[StepIDPreferences]
+	enforcePrefs := opts.RejectSHA1Prefs || opts.Strict || overridden
+	var prefsRemarks []string
+	prefsStep := opts.runStep(verifyStep, StepIDPreferences, opts.Catalog.stepName(StepIDPreferences), enforcePrefs, func() error {
+		prefs := gpg.KeyPreferences(key)
+		prefsRemarks = []string{
+			fmt.Sprintf("Preferred hash algorithms: %s", formatAlgorithms(prefs.HashNames())),
+			fmt.Sprintf("Preferred symmetric algorithms: %s", formatAlgorithms(prefs.SymmetricNames())),
+		}
+		if !prefs.PrefersSHA1() {
+			return nil
+		}
+		if !enforcePrefs {
+			prefsRemarks = append(prefsRemarks, "The key prefers SHA1 as its hash algorithm")
+			return nil
+		}
+		return fmt.Errorf("key prefers SHA1 as its hash algorithm")
+	})
+	prefsStep.Remarks = append(prefsStep.Remarks, prefsRemarks...)
+
+	var hashRemarks []string
+	hashStep := opts.runStep(verifyStep, StepIDSigHashes, opts.Catalog.stepName(StepIDSigHashes), opts.Strict, func() error {
+		var errs []error
+		for _, h := range gpg.SelfSignatureHashes(key) {
+			hashRemarks = append(hashRemarks, fmt.Sprintf("The %s uses %s", h.Signature, h.Hash))
+			if h.SHA1 {
+				errs = append(errs, fmt.Errorf("the %s uses SHA1, which is vulnerable to collision attacks", h.Signature))
+			}
+		}
+		return errors.Join(errs...)
+	})
+	hashStep.Remarks = append(hashStep.Remarks, hashRemarks...)
+
+	emailStep := opts.runStep(verifyStep, StepIDIdentity, opts.Catalog.stepName(StepIDIdentity), false, func() error {
+		if key.GetFingerprint() == "" {
+			return fmt.Errorf("key has no fingerprint")
+		}
+
+		entity := key.GetEntity()
+		if entity == nil {
+			return fmt.Errorf("key has no entity")
+		}
+
+		identities := entity.Identities
+		if len(identities) == 0 {
+			return fmt.Errorf("key has no identities")
+		}
+
+		for idName, identity := range identities {
+			err := validateIdentity(identity.Name)
+			if err != nil {
+				return fmt.Errorf("key identity %s %w", idName, err)
+			}
+		}
+
+		return nil
+	})
+
+	if photos, err := gpg.PhotoUserIDs(data); err == nil && photos > 0 && emailStep.Status != StatusSkipped {
+		// Photo user IDs carry no name or email, they are neither validated nor counted as identities
+		emailStep.Remarks = append(emailStep.Remarks, fmt.Sprintf("The key has %d photo user ID(s), only the text user IDs are checked", photos))
+	}
+
+	if opts.ShowNotations && emailStep.Status != StatusSkipped {
+		// Informational, to help reviewers find where the key owner proves their identity
+		for _, n := range gpg.Notations(key) {
+			emailStep.Remarks = append(emailStep.Remarks, fmt.Sprintf("Notation on user ID %s: %s", n.UserID, n))
+		}
+		for _, proof := range gpg.IdentityProofs(key) {
+			emailStep.Remarks = append(emailStep.Remarks, fmt.Sprintf("Identity proof: %s", proof))
+		}
+	}
+
+	var identityRemarks []string
+	identityEvidence := make(map[string]any)
+	identityStep := opts.runStep(verifyStep, StepIDSingleIdentity, opts.Catalog.stepName(StepIDSingleIdentity), false, func() error {
+		groups := distinctIdentities(keyUserIDs(key))
+		identityEvidence["identities"] = groups
+		if len(groups) <= 1 {
+			return nil
+		}
+		for i, group := range groups {
+			identityRemarks = append(identityRemarks, fmt.Sprintf("Identity %d: %s", i+1, strings.Join(group, ", ")))
+		}
+		return fmt.Errorf("the user IDs appear to belong to %d different identities, with unrelated names and email domains", len(groups))
+	})
+	identityStep.Remarks = append(identityStep.Remarks, identityRemarks...)
+	identityStep.addEvidence(identityEvidence)
+
+	var primaryRemarks []string
+	primaryEvidence := make(map[string]any)
+	primaryStep := opts.runStep(verifyStep, StepIDPrimaryUID, opts.Catalog.stepName(StepIDPrimaryUID), false, func() error {
+		primary := gpg.PrimaryUserIDs(key)
+		switch len(primary) {
+		case 0:
+			return fmt.Errorf("no user ID is marked as primary")
+		case 1:
+			primaryRemarks = append(primaryRemarks, fmt.Sprintf("Primary user ID: %s", primary[0]))
+			primaryEvidence["primary_user_id"] = primary[0]
+			return nil
+		default:
+			return fmt.Errorf("multiple user IDs are marked as primary: %s", strings.Join(primary, ", "))
+		}
+	})
+	primaryStep.Remarks = append(primaryStep.Remarks, primaryRemarks...)
+	primaryStep.addEvidence(primaryEvidence)
+
+	if opts.CheckFilename {
+		if opts.KeyFile == "" || opts.KeyEnv != "" {
+			verifyStep.SkipStep(opts.Catalog.stepName(StepIDFilename), "the key was not read from a file").withID(StepIDFilename)
+		} else {
+			opts.runStep(verifyStep, StepIDFilename, opts.Catalog.stepName(StepIDFilename), false, func() error {
+				return verifyFilename(opts.KeyFile, key)
+			})
+		}
+	}
+
+	if opts.SignatureFile != "" {
+		var signatureRemarks []string
+		signatureEvidence := make(map[string]any)
+		signatureStep := opts.runStep(verifyStep, StepIDSignature, opts.Catalog.stepName(StepIDSignature), false, func() error {
+			created, issuers, err := verifyDetachedSignature(opts.SignatureFile, opts.MessageFile, key)
+			if len(issuers) > 0 {
+				signatureRemarks = append(signatureRemarks, fmt.Sprintf("Signature was made by key ID %s", strings.Join(issuers, ", ")))
+				signatureEvidence["issuers"] = issuers
+			}
+			if err != nil {
+				return err
+			}
+			signatureRemarks = append(signatureRemarks, fmt.Sprintf("Signature created at %s", created.Format(time.RFC3339)))
+			signatureEvidence["created"] = created.UTC().Format(time.RFC3339)
+			return nil
+		})
+		signatureStep.Remarks = append(signatureStep.Remarks, signatureRemarks...)
+		signatureStep.addEvidence(signatureEvidence)
+	}
+
+	return verifyStep, key
+}
+
+// describeTrailingData describes what was found after the public key block, for contributors to recognize it.
+func describeTrailingData(trailing []byte) string {
+	firstLine, _, _ := strings.Cut(string(trailing), "\n")
+	firstLine = strings.TrimSpace(firstLine)
+	if header, ok := strings.CutPrefix(firstLine, "-----BEGIN PGP "); ok {
+		return fmt.Sprintf("a PGP %s", strings.ToLower(strings.TrimSuffix(header, "-----")))
+	}
+	if runes := []rune(firstLine); len(runes) > 60 {
+		firstLine = string(runes[:60]) + "…"
+	}
+	return fmt.Sprintf("%d bytes of text starting with %q", len(trailing), firstLine)
+}
+
+// validateIdentity checks that a user ID has a name and a valid email address. Names may contain any UTF-8 and
+// internationalized domains may be given in either their Unicode or punycode form. The returned error is phrased to
+// follow the identity.
+func validateIdentity(name string) error {
+	if name == "" {
+		return fmt.Errorf("has no name")
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("is not valid UTF-8")
+	}
+
+	_, email, err := gpg.ParseUID(name)
+	if err != nil {
+		return fmt.Errorf("has an invalid email: %w", err)
+	}
+	if email == "" {
+		return fmt.Errorf("has no email")
+	}
+
+	// mail.ParseAddress does not validate punycode or the IDNA rules for domain labels
+	domain := email[strings.LastIndex(email, "@")+1:]
+	_, err = idna.Lookup.ToUnicode(domain)
+	if err != nil {
+		return fmt.Errorf("has an invalid email domain %q: %w", domain, err)
+	}
+
+	return nil
+}
+
+func formatAlgorithms(names []string) string {
+	if len(names) == 0 {
+		return "none declared"
+	}
+	return strings.Join(names, ", ")
+}
+
+// verifyDetachedSignature checks that the signature file is a detached signature of the message file made by the key.
+// The key IDs the signature declares as its issuers are returned even if the verification fails.
+func verifyDetachedSignature(signatureFile string, messageFile string, key *crypto.Key) (time.Time, []string, error) {
+	signature, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to read signature file: %w", err)
+	}
+	message, err := os.ReadFile(messageFile)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to read message file: %w", err)
+	}
+	// A signature that does not declare its issuer is still verified, the key ring tries all keys
+	issuers, _ := gpg.SignatureIssuers(signature)
+	created, err := gpg.VerifyDetachedSignature(key, message, signature)
+	return created, issuers, err
+}
+
+// checkValidity reports when the primary key and its signing subkeys expire, and fails for keys that never expire or
+// that expire more than maxYears after now.
+func checkValidity(validities []gpg.KeyValidity, maxYears int, now time.Time) ([]string, error) {
+	limit := now.AddDate(maxYears, 0, 0)
+
+	var remarks []string
+	var errs []error
+	for _, v := range validities {
+		kind := "Key"
+		if v.Subkey {
+			kind = "Signing subkey"
+		}
+		switch {
+		case v.Expires.IsZero():
+			remarks = append(remarks, fmt.Sprintf("%s %s does not expire", kind, v.KeyID))
+			errs = append(errs, fmt.Errorf("%s %s does not expire, consider setting an expiration and rotating the key", strings.ToLower(kind), v.KeyID))
+		default:
+			remarks = append(remarks, fmt.Sprintf("%s %s expires on %s", kind, v.KeyID, v.Expires.Format(time.DateOnly)))
+			if v.Expires.After(limit) {
+				errs = append(errs, fmt.Errorf("%s %s expires more than %d years from now, consider a shorter expiration and rotating the key", strings.ToLower(kind), v.KeyID, maxYears))
+			}
+		}
+	}
+	return remarks, errors.Join(errs...)
+}
+
+// checkDenylist fails if the fingerprint of the primary key or of any of its subkeys is on the denylist.
+func checkDenylist(key *crypto.Key, denylist map[string]bool) error {
+	fingerprint := strings.ToUpper(key.GetFingerprint())
+	if denylist[fingerprint] {
+		return fmt.Errorf("key %s is on the denylist of compromised keys", fingerprint)
+	}
+	for _, subkey := range key.GetEntity().Subkeys {
+		subkeyFingerprint := strings.ToUpper(hex.EncodeToString(subkey.PublicKey.Fingerprint))
+		if denylist[subkeyFingerprint] {
+			return fmt.Errorf("subkey %s is on the denylist of compromised keys", subkeyFingerprint)
+		}
+	}
+	return nil
+}
+
+// locateRegistryKey reports whether the registry holds a key with the given fingerprint for the organization.
+func locateRegistryKey(keyDataDir string, org string, fingerprint string) (bool, error) {
+	keys, err := gpg.KeyCollection{Namespace: org, Directory: keyDataDir}.ListKeys()
+	if err != nil {
+		return false, fmt.Errorf("could not list registry keys of %s: %w", org, err)
+	}
+	keyID := gpg.KeyIDFromFingerprint(fingerprint)
+	for _, k := range keys {
+		if strings.EqualFold(keyID, k.KeyID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// designatedRevocation is a revocation of the key by one of its designated revokers that is in effect.
+type designatedRevocation struct {
+	reason     string
+	superseded bool // The revocation was verified and only states that the key was superseded
+}
+
+// checkDesignatedRevocations verifies the revocations of the key made by its designated revokers with the keys of the
+// revokers among the registry keys of the namespace. A revocation whose revoker's key is not available cannot be
+// verified, it is returned as in effect so that the key is still rejected. A revocation the revoker's key does not
+// verify is returned as invalid.
+func checkDesignatedRevocations(opts VerifyKeyOptions, data []byte, now time.Time) ([]designatedRevocation, []gpg.InvalidRevocation, error) {
+	revocations, err := gpg.DesignatedRevocations(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read designated revocations: %w", err)
+	}
+
+	var revoked []designatedRevocation
+	var invalid []gpg.InvalidRevocation
+	namespace := opts.namespace()
+	for _, r := range revocations {
+		if !r.InEffect(now) {
+			continue
+		}
+		if opts.KeyDataDir == "" || namespace == "" {
+			revoked = append(revoked, designatedRevocation{
+				reason: fmt.Sprintf("%s by the designated revoker %s, which could not be verified without the registry keys", r.Revocation, r.Revoker),
+			})
+			continue
+		}
+
+		revoker, err := registryKey(opts.KeyDataDir, namespace, r.Revoker)
+		if err != nil {
+			return nil, nil, err
+		}
+		if revoker == nil {
+			revoked = append(revoked, designatedRevocation{
+				reason: fmt.Sprintf("%s by the designated revoker %s, which could not be verified as its key is not among the registry keys of %s", r.Revocation, r.Revoker, namespace),
+			})
+			continue
+		}
+		if err := r.Verify(revoker); err != nil {
+			invalid = append(invalid, gpg.InvalidRevocation{Target: "the key", Reason: fmt.Sprintf("made by the designated revoker %s, but %s", r.Revoker, err)})
+			continue
+		}
+		revoked = append(revoked, designatedRevocation{
+			reason:     fmt.Sprintf("%s by the designated revoker %s", r.Revocation, r.Revoker),
+			superseded: r.Revocation.Superseded(),
+		})
+	}
+	return revoked, invalid, nil
+}
+
+// registryKey returns the registry key of the organization with the given fingerprint, or nil if there is none.
+func registryKey(keyDataDir string, org string, fingerprint string) (*crypto.Key, error) {
+	keys, err := gpg.KeyCollection{Namespace: org, Directory: keyDataDir}.ListKeys()
+	if err != nil {
+		return nil, fmt.Errorf("could not list registry keys of %s: %w", org, err)
+	}
+	keyID := gpg.KeyIDFromFingerprint(fingerprint)
+	for _, k := range keys {
+		if !strings.EqualFold(keyID, k.KeyID) {
+			continue
+		}
+		key, err := gpg.ParseKey(k.ASCIIArmor)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse registry key %s of %s: %w", k.KeyID, org, err)
+		}
+		if strings.EqualFold(key.GetFingerprint(), fingerprint) {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+// verifyFilename checks that the name of the key file, without its extension, is the key fingerprint.
+func verifyFilename(location string, key *crypto.Key) error {
+	name := filepath.Base(location)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	fingerprint := key.GetFingerprint()
+	if !strings.EqualFold(name, fingerprint) {
+		return fmt.Errorf("key file name does not match the key fingerprint: expected %q, got %q", strings.ToUpper(fingerprint), name)
+	}
+	return nil
+}
@@ -0,0 +1,1179 @@
+package verification
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+func TestValidateIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		err      string
+	}{
+		{name: "ascii", identity: "Test User <test@example.com>"},
+		{name: "comment", identity: "Test User (work) <test@example.com>"},
+		{name: "utf-8 name", identity: "Jörg Müller <joerg@example.com>"},
+		{name: "non-latin name", identity: "李小龙 <li@example.com>"},
+		{name: "utf-8 local part", identity: "Jörg <jörg@example.com>"},
+		{name: "unicode idn domain", identity: "Jörg <joerg@bücher.de>"},
+		{name: "non-latin idn domain", identity: "李 <li@例子.中国>"},
+		{name: "punycode idn domain", identity: "Jörg <joerg@xn--bcher-kva.de>"},
+		{name: "empty", identity: "", err: "has no name"},
+		{name: "invalid utf-8", identity: "J\xf6rg <joerg@example.com>", err: "is not valid UTF-8"},
+		{name: "no email", identity: "Test User", err: "has no email"},
+		{name: "invalid email", identity: "Test User <not an email>", err: "has an invalid email"},
+		{name: "invalid punycode", identity: "Test User <test@xn--zz.de>", err: "has an invalid email domain"},
+		{name: "invalid label", identity: "Test User <test@-example.com>", err: "has an invalid email domain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIdentity(tt.identity)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVerifyKey_InternationalIdentity(t *testing.T) {
+	key, err := crypto.GenerateKey("Jörg Müller", "jörg@bücher.de", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.asc")
+	err = os.WriteFile(keyFile, []byte(publicKey), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Generated keys never expire, which is reported by the validity step
+	step, _ := VerifyKey(VerifyKeyOptions{KeyFile: keyFile, Filter: StepFilter{skip: map[string]bool{StepIDValidity: true}}})
+	assert.False(t, step.DidFail())
+	assert.False(t, step.DidWarn())
+}
+
+func TestVerifyKey_Evidence(t *testing.T) {
+	step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(expiringKey), Now: func() time.Time { return time.Unix(1800000000, 0) }})
+	if key == nil {
+		t.Fatal("key could not be parsed")
+	}
+	parseStep := step.SubSteps[0]
+	assert.Equal(t, messageIDParse, parseStep.ID)
+	assert.Equal(t, strings.ToUpper(key.GetFingerprint()), parseStep.Evidence["fingerprint"])
+	assert.Equal(t, "EdDSA", parseStep.Evidence["algorithm"])
+	assert.Equal(t, "2026-10-15T07:46:17Z", parseStep.Evidence["created"])
+}
+
+func TestVerifyKey_ParsesOnce(t *testing.T) {
+	parses := 0
+	original := parseKey
+	parseKey = func(data []byte) (*crypto.Key, error) {
+		parses++
+		return original(data)
+	}
+	t.Cleanup(func() { parseKey = original })
+
+	keyFile := writeTestKey(t)
+	_, key := VerifyKey(VerifyKeyOptions{KeyFile: keyFile, CheckFilename: true, EchoKey: true})
+	assert.NotNil(t, key)
+	assert.Equal(t, 1, parses)
+}
+
+func BenchmarkVerifyKey(b *testing.B) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	keyFile := filepath.Join(b.TempDir(), "key.asc")
+	err = os.WriteFile(keyFile, []byte(publicKey), 0600)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	parses := 0
+	original := parseKey
+	parseKey = func(data []byte) (*crypto.Key, error) {
+		parses++
+		return original(data)
+	}
+	b.Cleanup(func() { parseKey = original })
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyKey(VerifyKeyOptions{KeyFile: keyFile})
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(parses)/float64(b.N), "parses/op")
+	if parses != b.N {
+		b.Fatalf("expected the key to be parsed once per verification, got %d parses for %d verifications", parses, b.N)
+	}
+}
+
+// revokedByKey designates revokerKey as its revoker.
+const revokedByKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatB9EhYJKwYBBAHaRw8BAQdA+ciKo+riK3O+WLiHCZuKkQBfI1ArFCaoG02W
+pdmYDdWIkAQfFggAOBYhBG3rTU+tJdwr4Os/Gw0PBAEiLmUgBQJq0H0SFwyAFsFq
+oK0OkWIHotsdMiEXYBDNLyXJAgcAAAoJEA0PBAEiLmUgtm0BAM2VS1PY0HVkAL+1
+vZCM+mnpUkVIm0MU6JvHPzayqnvvAPsHc6/gZzmXaRaVM+yX5ucFM1I25uS4Kfd2
+pE8r9Q3HCLQcVGVzdCBVc2VyIDx0ZXN0QGV4YW1wbGUuY29tPoiQBBMWCAA4FiEE
+betNT60l3Cvg6z8bDQ8EASIuZSAFAmrQfRICGwMFCwkIBwIGFQoJCAsCBBYCAwEC
+HgECF4AACgkQDQ8EASIuZSDKVQEA2UJ6llfBufe20mdbpaJXDn5UXMOt3yf+HSnT
+wzCWC7kA/0oI76PH8eBs4DukhhWmvoN5slh5pEbD4yU4mycMSdIA
+=2fCr
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const revokerKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatB9EhYJKwYBBAHaRw8BAQdAEIQuU96hASS5myiQVHHDBxbFHkngG04AqRi0
+U2vHRZi0HVJldm9rZXIgPHJldm9rZXJAZXhhbXBsZS5jb20+iJAEExYIADgWIQTB
+aqCtDpFiB6LbHTIhF2AQzS8lyQUCatB9EgIbAwULCQgHAgYVCgkICwIEFgIDAQIe
+AQIXgAAKCRAhF2AQzS8lycaEAPwPdSm94s4f6tO1kV7GDJg8D5hVrgeIvdhe6HEc
+bZeL/AEAx83QZjQqHKUO6Q3DPXr55X+3Xe9HLIK55+iSieuXGQw=
+=FWdV
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestVerifyKey_DesignatedRevokers(t *testing.T) {
+	withRevoker := t.TempDir()
+	_, _, err := gpg.KeyCollection{Namespace: "example", Directory: withRevoker}.AddKey(revokerKey, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    VerifyKeyOptions
+		status  Status
+		remarks []string
+	}{
+		{
+			name:    "no designated revokers",
+			opts:    VerifyKeyOptions{KeyData: []byte(revokerKey)},
+			status:  StatusSuccess,
+			remarks: []string{"The key has no designated revokers"},
+		},
+		{
+			name:    "designated revoker",
+			opts:    VerifyKeyOptions{KeyData: []byte(revokedByKey)},
+			status:  StatusSuccess,
+			remarks: []string{"Revocation of this key is delegated to the key C16AA0AD0E916207A2DB1D3221176010CD2F25C9"},
+		},
+		{
+			name:   "designated revoker in the registry",
+			opts:   VerifyKeyOptions{KeyData: []byte(revokedByKey), Org: "example", KeyDataDir: withRevoker},
+			status: StatusSuccess,
+		},
+		{
+			name:   "designated revoker not in the registry",
+			opts:   VerifyKeyOptions{KeyData: []byte(revokedByKey), Org: "example", KeyDataDir: t.TempDir()},
+			status: StatusWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _ := VerifyKey(tt.opts)
+			var revokers *Step
+			for _, s := range step.SubSteps {
+				if s.Name == "Key's designated revokers are known" {
+					revokers = s
+				}
+			}
+			if assert.NotNil(t, revokers) {
+				assert.Equal(t, tt.status, revokers.Status)
+				if tt.remarks != nil {
+					assert.Equal(t, tt.remarks, revokers.Remarks)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyKey_Denylist(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := strings.ToUpper(key.GetFingerprint())
+	subkeyFingerprint := strings.ToUpper(hex.EncodeToString(key.GetEntity().Subkeys[0].PublicKey.Fingerprint))
+
+	tests := []struct {
+		name     string
+		denylist map[string]bool
+		status   Status
+		err      string
+	}{
+		{
+			name:     "not on the denylist",
+			denylist: map[string]bool{"A8B6D5E1F0C2B3A49D7E8F6C5B4A39281706F5E4": true},
+			status:   StatusSuccess,
+		},
+		{
+			name:     "key on the denylist",
+			denylist: map[string]bool{fingerprint: true},
+			status:   StatusFailure,
+			err:      "key " + fingerprint + " is on the denylist of compromised keys",
+		},
+		{
+			name:     "subkey on the denylist",
+			denylist: map[string]bool{subkeyFingerprint: true},
+			status:   StatusFailure,
+			err:      "subkey " + subkeyFingerprint + " is on the denylist of compromised keys",
+		},
+		{
+			name:   "no denylist",
+			status: StatusNotRun,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _ := VerifyKey(VerifyKeyOptions{KeyData: []byte(publicKey), Denylist: tt.denylist})
+			status := StatusNotRun
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDDenylist {
+					status = s.Status
+					if tt.err != "" {
+						assert.Equal(t, []string{tt.err}, s.Errors)
+					}
+				}
+			}
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
+
+func TestCheckValidity(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		validities []gpg.KeyValidity
+		remarks    []string
+		err        string
+	}{
+		{
+			name:       "expires within the limit",
+			validities: []gpg.KeyValidity{{KeyID: "B3F4DBD98406AC81", Expires: now.AddDate(2, 0, 0)}},
+			remarks:    []string{"Key B3F4DBD98406AC81 expires on 2026-01-01"},
+		},
+		{
+			name:       "never expires",
+			validities: []gpg.KeyValidity{{KeyID: "B3F4DBD98406AC81"}},
+			remarks:    []string{"Key B3F4DBD98406AC81 does not expire"},
+			err:        "key B3F4DBD98406AC81 does not expire, consider setting an expiration and rotating the key",
+		},
+		{
+			name: "signing subkey expires too late",
+			validities: []gpg.KeyValidity{
+				{KeyID: "B3F4DBD98406AC81", Expires: now.AddDate(2, 0, 0)},
+				{KeyID: "6B040A5EF8B28BF4", Subkey: true, Expires: now.AddDate(100, 0, 0)},
+			},
+			remarks: []string{"Key B3F4DBD98406AC81 expires on 2026-01-01", "Signing subkey 6B040A5EF8B28BF4 expires on 2124-01-01"},
+			err:     "signing subkey 6B040A5EF8B28BF4 expires more than 10 years from now, consider a shorter expiration and rotating the key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remarks, err := checkValidity(tt.validities, 10, now)
+			assert.Equal(t, tt.remarks, remarks)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// expiringKey was generated with gpg on 2026-10-15 and expires after two years, on 2028-10-14. Its signing subkey was
+// added without an expiration.
+const expiringKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCEyRYJKwYBBAHaRw8BAQdAUi2VFzojIxMzTD+NQzT23/Jk/hAWmfCfT86I
+sTOtH420HFRlc3QgVXNlciA8dGVzdEBleGFtcGxlLmNvbT6IlgQTFggAPhYhBIvh
+qz8KzBMSWPUJQbP029mEBqyBBQJq0ITJAhsDBQkDwmcABQsJCAcCBhUKCQgLAgQW
+AgMBAh4BAheAAAoJELP029mEBqyBLOUBAIQeF4AAK7OSO3O62pXguL3GfN43o7Il
+cb9FeSd/bXOrAQCJDRZs8mTNwUEgXIol0azZOJa4qjhRCUvPYZX3EhxlCLgzBGrQ
+hMkWCSsGAQQB2kcPAQEHQNVXi+l49Kde4W9dY4YKakmIG8qoEgJIs6PnKNdQcZGe
+iO8EGBYIACAWIQSL4as/CswTElj1CUGz9NvZhAasgQUCatCEyQIbAgCBCRCz9NvZ
+hAasgXYgBBkWCAAdFiEEknD77qaPbzVIjMMZawQKXviyi/QFAmrQhMkACgkQawQK
+Xviyi/RTAQEAlwmqcr1WNKKGsoaJE7h3eJJE4EPm47XxJBJECUP40fkA/01jWxPR
+ileGvtHKG7b3lwZdSbuztyOAogNpheeht8wE4VQA/0d/LgogXD2IwM+lPsoEhG2d
+Uv1ty/j5OlAOu+4GhAglAP9qkEJEUdxpVsQFUidMhb2Nhnkr0Vqr/mth2OKyjJcj
+Aw==
+=/6Z/
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestVerifyKey_Clock(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		expiry   Status
+		validity Status
+		err      string
+	}{
+		{
+			name:     "valid",
+			now:      time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			expiry:   StatusSuccess,
+			validity: StatusSuccess,
+		},
+		{
+			name:     "expired",
+			now:      time.Date(2028, 10, 15, 0, 0, 0, 0, time.UTC),
+			expiry:   StatusFailure,
+			validity: StatusSuccess,
+			err:      "key is expired",
+		},
+		{
+			name:     "created within the allowed clock skew",
+			now:      time.Unix(1792050377, 0).Add(-30 * time.Minute),
+			expiry:   StatusSuccess,
+			validity: StatusSuccess,
+		},
+		{
+			name:     "created in the future",
+			now:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			expiry:   StatusFailure,
+			validity: StatusSuccess,
+			err:      "key was created in the future, at 2026-10-15T07:46:17Z, check the clock of the system that generated it",
+		},
+		{
+			name:     "expires more than the maximum validity ahead",
+			now:      time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+			expiry:   StatusFailure,
+			validity: StatusWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _ := VerifyKey(VerifyKeyOptions{
+				KeyData: []byte(expiringKey),
+				Now:     func() time.Time { return tt.now },
+			})
+			statuses := map[string]Status{}
+			for _, s := range step.SubSteps {
+				statuses[s.ID] = s.Status
+				if s.ID == StepIDExpiry && tt.err != "" {
+					assert.Equal(t, []string{tt.err}, s.Errors)
+				}
+			}
+			assert.Equal(t, tt.expiry, statuses[StepIDExpiry])
+			assert.Equal(t, tt.validity, statuses[StepIDValidity])
+			assert.Equal(t, StatusSuccess, statuses[StepIDRevocation])
+		})
+	}
+}
+
+func TestVerifyKey_SelfSignatureAge(t *testing.T) {
+	tests := []struct {
+		name   string
+		maxAge int
+		now    time.Time
+		status Status
+		errors []string
+	}{
+		{
+			name:   "recent self-signature",
+			maxAge: 1,
+			now:    time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			status: StatusSuccess,
+		},
+		{
+			name:   "old self-signature",
+			maxAge: 1,
+			now:    time.Date(2027, 12, 1, 0, 0, 0, 0, time.UTC),
+			status: StatusWarning,
+			errors: []string{`the latest self-signature is older than 1 years, please renew it, for example by updating the expiry with "gpg --quick-set-expire", and submit the key again`},
+		},
+		{
+			name:   "disabled",
+			now:    time.Date(2027, 12, 1, 0, 0, 0, 0, time.UTC),
+			status: StatusNotRun,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _ := VerifyKey(VerifyKeyOptions{
+				KeyData:            []byte(expiringKey),
+				Now:                func() time.Time { return tt.now },
+				MaxSelfSigAgeYears: tt.maxAge,
+			})
+			var ageStep *Step
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDSelfSigAge {
+					ageStep = s
+				}
+			}
+			if tt.status == StatusNotRun {
+				assert.Nil(t, ageStep)
+				return
+			}
+			assert.Equal(t, "Self-signature was renewed within 1 years", ageStep.Name)
+			assert.Equal(t, tt.status, ageStep.Status)
+			assert.Equal(t, tt.errors, ageStep.Errors)
+			assert.Equal(t, []string{"The latest self-signature was made at 2026-10-15T07:46:17Z"}, ageStep.Remarks)
+			assert.Equal(t, map[string]any{"self_signature_date": "2026-10-15T07:46:17Z"}, ageStep.Evidence)
+		})
+	}
+}
+
+func TestVerifyKey_RevocationReason(t *testing.T) {
+	tests := []struct {
+		name            string
+		reason          packet.ReasonForRevocation
+		text            string
+		allowSuperseded bool
+		status          Status
+		err             string
+		remarks         []string
+	}{
+		{
+			name:   "superseded",
+			reason: packet.KeySuperseded,
+			text:   "replaced by a new key",
+			status: StatusFailure,
+			err:    `key is revoked: key superseded ("replaced by a new key")`,
+		},
+		{
+			name:            "superseded allowed",
+			reason:          packet.KeySuperseded,
+			allowSuperseded: true,
+			status:          StatusWarning,
+			err:             "key is revoked: key superseded",
+			remarks:         []string{"The key was superseded by a new key, please submit the superseding key instead"},
+		},
+		{
+			name:            "compromised",
+			reason:          packet.KeyCompromised,
+			allowSuperseded: true,
+			status:          StatusFailure,
+			err:             "key is revoked: key compromised",
+		},
+		{
+			name:            "no reason",
+			reason:          packet.NoReason,
+			allowSuperseded: true,
+			status:          StatusFailure,
+			err:             "key is revoked: no reason specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := key.GetEntity().RevokeKey(tt.reason, tt.text, nil); err != nil {
+				t.Fatal(err)
+			}
+			publicKey, err := key.GetArmoredPublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := VerifyKeyOptions{
+				KeyData:         []byte(publicKey),
+				AllowSuperseded: tt.allowSuperseded,
+				Now:             func() time.Time { return time.Now().Add(time.Hour) },
+			}
+			step, _ := VerifyKey(opts)
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDRevocation {
+					assert.Equal(t, tt.status, s.Status)
+					assert.Equal(t, []string{tt.err}, s.Errors)
+					assert.Equal(t, tt.remarks, s.Remarks)
+					return
+				}
+			}
+			t.Fatal("revocation step not found")
+		})
+	}
+}
+
+// keyWithProse is a key followed by the kind of explanation contributors sometimes paste after it.
+const keyWithProse = expiringKey + `
+This is the release signing key of our provider, please add it to the registry.
+Contact security@example.com with any questions.
+`
+
+func TestVerifyKey_TrailingData(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		reject bool
+		status Status
+		errors []string
+	}{
+		{
+			name:   "key only",
+			data:   expiringKey,
+			status: StatusSuccess,
+		},
+		{
+			name:   "prose",
+			data:   keyWithProse,
+			status: StatusWarning,
+			errors: []string{`found 128 bytes of text starting with "This is the release signing key of our provider, please add …" after the public key block, it was ignored`},
+		},
+		{
+			name:   "signature rejected",
+			data:   expiringKey + "\n-----BEGIN PGP SIGNATURE-----\n\nabc\n-----END PGP SIGNATURE-----\n",
+			reject: true,
+			status: StatusFailure,
+			errors: []string{"found a PGP signature after the public key block, it was ignored"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(tt.data), RejectTrailingData: tt.reject})
+			assert.NotNil(t, key)
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDTrailingData {
+					assert.Equal(t, tt.status, s.Status)
+					assert.Equal(t, tt.errors, s.Errors)
+					return
+				}
+			}
+			t.Fatal("trailing data step not found")
+		})
+	}
+}
+
+func TestVerifyKey_Subkeys(t *testing.T) {
+	generated, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withSubkey, err := generated.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		status  Status
+		subkeys int
+	}{
+		{
+			name:    "primary key only",
+			data:    githubKey,
+			status:  StatusWarning,
+			subkeys: 0,
+		},
+		{
+			name:    "separate subkey",
+			data:    withSubkey,
+			status:  StatusSuccess,
+			subkeys: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(tt.data)})
+			assert.NotNil(t, key)
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDSubkeys {
+					assert.Equal(t, tt.status, s.Status)
+					assert.Equal(t, map[string]any{"subkeys": tt.subkeys}, s.Evidence)
+					return
+				}
+			}
+			t.Fatal("subkeys step not found")
+		})
+	}
+}
+
+func TestVerifyKey_MissingCrossCertification(t *testing.T) {
+	generated, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entity := generated.GetEntity()
+	if err := entity.AddSigningSubkey(nil); err != nil {
+		t.Fatal(err)
+	}
+	// Bind the signing subkey again, without the back signature it makes
+	subkey := entity.Subkeys[len(entity.Subkeys)-1]
+	subkey.Sig.EmbeddedSignature = nil
+	if err := subkey.Sig.SignKey(subkey.PublicKey, entity.PrivateKey, nil); err != nil {
+		t.Fatal(err)
+	}
+	armored, err := generated.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(armored)})
+	assert.Nil(t, key)
+	parseStep := step.SubSteps[0]
+	assert.Equal(t, messageIDParse, parseStep.ID)
+	assert.Equal(t, StatusFailure, parseStep.Status)
+	assert.Equal(t, []string{fmt.Sprintf("could not parse key, a signing subkey has no valid primary key binding signature: subkey %s: openpgp: invalid data: signing subkey is missing cross-signature", strings.ToUpper(subkey.PublicKey.KeyIdString()))}, parseStep.Errors)
+}
+
+// keybaseKey was generated with gpg. Its second user ID "keybase.io/janedoe <janedoe@keybase.io>" was added with
+// --cert-notation proof@ariadne.id=https://keybase.io/janedoe.
+const keybaseKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCLERYJKwYBBAHaRw8BAQdA7pAB3BqZ6CmMAJSBqLrp9DYkXGumXGs4t6uC
+tkcI7SC0G0phbmUgRG9lIDxqYW5lQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEbTEL
+mvL4brqaSZSQGof6GxbUnewFAmrQixECGwMFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQGof6GxbUnewkHQEA/KKg8E4XB5WzEBe6XT6aEHV3ElHsEgPLADcV4WWW
+UUQBAMAOtc1MzQiOSQsD5HTkSH2M6XFqGtYWoex2snZbWIQMtCdrZXliYXNlLmlv
+L2phbmVkb2UgPGphbmVkb2VAa2V5YmFzZS5pbz6IxAQTFggAbBYhBG0xC5ry+G66
+mkmUkBqH+hsW1J3sBQJq0IsRMxSAAAAAABAAGnByb29mQGFyaWFkbmUuaWRodHRw
+czovL2tleWJhc2UuaW8vamFuZWRvZQIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRAah/obFtSd7NXUAP9+9/kIEUbd/ZPXblk2eZhMlSKR4+AVgBoYaBh444gr
+TQD9HVWUByaorXy6HDsPmp+Ed4iiXMCejOtPtqS7rryp+Qg=
+=8UUM
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestVerifyKey_ShowNotations(t *testing.T) {
+	tests := []struct {
+		name    string
+		show    bool
+		remarks []string
+	}{
+		{
+			name: "hidden by default",
+		},
+		{
+			name: "shown",
+			show: true,
+			remarks: []string{
+				"Notation on user ID keybase.io/janedoe <janedoe@keybase.io>: proof@ariadne.id=https://keybase.io/janedoe",
+				"Identity proof: https://keybase.io/janedoe",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(keybaseKey), ShowNotations: tt.show})
+			assert.NotNil(t, key)
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDIdentity {
+					assert.Equal(t, tt.remarks, s.Remarks)
+					return
+				}
+			}
+			t.Fatal("identity step not found")
+		})
+	}
+}
+
+func TestVerifyKey_TofuCompat(t *testing.T) {
+	armored := func(keyType string, bits int) string {
+		key, err := crypto.GenerateKey("Test User", "test@example.com", keyType, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicKey, err := key.GetArmoredPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return publicKey
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		check  bool
+		status Status
+		err    string
+	}{
+		{
+			name:   "not requested",
+			data:   armored("x25519", 0),
+			status: "",
+		},
+		{
+			name:   "eddsa key",
+			data:   armored("x25519", 0),
+			check:  true,
+			status: StatusSuccess,
+		},
+		{
+			name:   "short rsa key",
+			data:   armored("rsa", 1024),
+			check:  true,
+			status: StatusFailure,
+			err:    "its 1024 bit RSA key is too short",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(tt.data), CheckTofuCompat: tt.check})
+			assert.NotNil(t, key)
+			var tofuStep *Step
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDTofuCompat {
+					tofuStep = s
+				}
+			}
+			if tt.status == "" {
+				assert.Nil(t, tofuStep)
+				return
+			}
+			if !assert.NotNil(t, tofuStep) {
+				return
+			}
+			assert.Equal(t, tt.status, tofuStep.Status)
+			assert.Contains(t, tofuStep.Evidence, "signing_keys")
+			if tt.err != "" {
+				assert.Len(t, tofuStep.Errors, 1)
+				assert.Contains(t, tofuStep.Errors[0], tt.err)
+			}
+		})
+	}
+}
+
+// photoKey was generated with gpg, its second user ID is a 1x1 pixel JPEG added with addphoto.
+const photoKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCQZRYJKwYBBAHaRw8BAQdANsiQ4UuaEiZcBAqMlhGHQmJA4MFoEKg6JmIW
+GWZEux+0G0phbmUgRG9lIDxqYW5lQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEKMTx
+IQPPQ4LBIkR9Y6LrjZVHWr8FAmrQkGUCGwMFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQY6LrjZVHWr+UyQEAkNJLN+rLzYwOBOfEitkmewwHGIkDsNEKjX7ABZzf
+oIUA/1tkVAr6Iq+QXbk9x7LHAR6TwuUR7Ne0tmqg8aG5Hg8D0ZiXARAAAQEAAAAA
+AAAAAAAAAAD/2P/gABBKRklGAAEBAQBIAEgAAP/bAEMA////////////////////
+////////////////////////////////////////////////////////////////
+///CAAsIAAEAAQEBEQD/xAAUEAEAAAAAAAAAAAAAAAAAAAAA/9oACAEBAAE/EIiQ
+BBMWCAA4FiEEKMTxIQPPQ4LBIkR9Y6LrjZVHWr8FAmrQkGUCGwMFCwkIBwIGFQoJ
+CAsCBBYCAwECHgECF4AACgkQY6LrjZVHWr+NLQEA9mj3t4TRxRr+rkxmhalML0PF
+kXtZBD0EIONRGVQBAW0A/039JeCtrNYFIA8uWLCQznFk+jUgEfdraxEQgCN8zGIF
+=39Yy
+-----END PGP PUBLIC KEY BLOCK-----`
+
+// cardKey has the layout of a key kept on an OpenPGP card such as a YubiKey: a certify-only Ed25519 primary key with
+// separate signing, encryption and authentication subkeys. Exported with "gpg --export".
+const cardKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCTVhYJKwYBBAHaRw8BAQdA4+kbvqDJNT/GFOtlqITLLbJwG+kguI9WvJYc
+7fuZr2O0HENhcmQgVXNlciA8Y2FyZEBleGFtcGxlLmNvbT6IkAQTFggAOBYhBOgp
+OW9bT9AC2ju84kczqAa0HAdFBQJq0JNWAhsBBQsJCAcCBhUKCQgLAgQWAgMBAh4B
+AheAAAoJEEczqAa0HAdFoVIBAPb5XLHkowO00ad5tupFeY8XE6uwPcoTI/mznfKp
+vzg4AQDBg1ZHu5byZG0iOIBgVFL7a33DnyPO5Ot5glnJ4XC3DLgzBGrQk1YWCSsG
+AQQB2kcPAQEHQLPgIpF88evRwCkCXLR0pERLnVROyEdelNk6tjN7Pt0jiO8EGBYI
+ACAWIQToKTlvW0/QAto7vOJHM6gGtBwHRQUCatCTVgIbAgCBCRBHM6gGtBwHRXYg
+BBkWCAAdFiEEK/BR1rhvZoK9nEYAGR507RGWoUcFAmrQk1YACgkQGR507RGWoUe1
+4gD/eK7L3IiBvbCXAB6l3fTF7en0L6YTJzxExK0YITyx1CwBAK0Yd9137CmEZcxD
+l6bPyTaubwgkDJ+7TL3PTrByVMMGRRQA/jy5F62puKN6XHKQs+cgW8Th+RwX8wGa
+czjZzUrCbOo7AQCZqC5upOCWjWm5DKDXM22awYWK8GqaXsOZuiVYHFYuC7g4BGrQ
+k1YSCisGAQQBl1UBBQEBB0Dfp90iOM7SigGQZvwFyQawF2Vu6mwpIA1+ocSQTNsW
+NAMBCAeIdwQYFggAIBYhBOgpOW9bT9AC2ju84kczqAa0HAdFBQJq0JNWAhsMAAoJ
+EEczqAa0HAdF5lQA91syx+fnf8jM7zSFh+OCKf81h3pZrbNllBDvyzhTLiYBAPHI
+5cbN9Di7u04UNN2PzJBVXLVW1PIzWBtDCHAH7UEAuDMEatCTVhYJKwYBBAHaRw8B
+AQdAvlSQ3d6bXvxaBpJTOt86A5vQLC27FYv3ZCbm7wNvKfeIeAQYFggAIBYhBOgp
+OW9bT9AC2ju84kczqAa0HAdFBQJq0JNWAhsgAAoJEEczqAa0HAdF67sBAOLdZCcf
+gVmphikMnrZk58BVf99UZJZokmAyKMtA17hMAQCSGc4ZC27w6l+zVw5mtghZC57v
+jffx58k+EID6wvocDA==
+=6ow+
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestVerifyKey_CardKey(t *testing.T) {
+	step, _ := VerifyKey(VerifyKeyOptions{KeyData: []byte(cardKey)})
+	statuses := map[string]Status{}
+	for _, s := range step.SubSteps {
+		statuses[s.ID] = s.Status
+		if s.ID == StepIDSigning {
+			assert.Equal(t, []string{
+				"Usage of the primary key 4733A806B41C0745: certify",
+				"Usage of the subkey 191E74ED1196A147: sign",
+				"Usage of the subkey 8EEC93F284FF7FC1: encrypt",
+				"Usage of the subkey E681754592A76EDD: authenticate",
+				"A subkey is signing-capable",
+			}, s.Remarks)
+			assert.Equal(t, map[string]any{"key_usage": map[string][]string{
+				"4733A806B41C0745": {"certify"},
+				"191E74ED1196A147": {"sign"},
+				"8EEC93F284FF7FC1": {"encrypt"},
+				"E681754592A76EDD": {"authenticate"},
+			}}, s.Evidence)
+		}
+		if s.ID == StepIDCrossCert {
+			assert.Equal(t, []string{"Signing subkey 191E74ED1196A147 is cross-certified"}, s.Remarks)
+		}
+	}
+	assert.Equal(t, StatusSuccess, statuses[StepIDSigning])
+	assert.Equal(t, StatusSuccess, statuses[StepIDCrossCert])
+	assert.Equal(t, StatusSuccess, statuses[StepIDSubkeys])
+}
+
+func TestVerifyKey_PhotoUserID(t *testing.T) {
+	step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(photoKey)})
+	assert.NotNil(t, key)
+	for _, s := range step.SubSteps {
+		if s.ID == StepIDIdentity {
+			assert.Equal(t, StatusSuccess, s.Status)
+			assert.Equal(t, []string{"The key has 1 photo user ID(s), only the text user IDs are checked"}, s.Remarks)
+			return
+		}
+	}
+	t.Fatal("identity step not found")
+}
+
+// legacyLayoutKey has the packet layout of keys exported by older tools: the user ID self-signature precedes the user
+// ID, a keyring trust packet follows it and the subkey binding signature precedes the subkey.
+const legacyLayoutKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCU2hYJKwYBBAHaRw8BAQdAjrsFaftHeiZrDs3ioqZRgtTYpcHq3IXk4Emg
+a0qzykKIkAQTFggAOBYhBGTt1ddrSFRraYsTNDrdRmBNWjH1BQJq0JTaAhsDBQsJ
+CAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEDrdRmBNWjH124wA/igwuRpPPjW+7Lgi
+XKMWcz2tbyEePHOvwBkplBsj+Bp+AP0eiIGQS0tVf6SwwqGM9KqV+/tdjBRtqzpa
+RrqQ5CILBrQiTGVnYWN5IExheW91dCA8bGVnYWN5QGV4YW1wbGUuY29tPrACAACI
+eAQYFggAIBYhBGTt1ddrSFRraYsTNDrdRmBNWjH1BQJq0JTaAhsMAAoJEDrdRmBN
+WjH1Zu4BAP0k4jh1/WVbBm+7GBueEgCzjGNoEcFWCiXPpeJzLPGCAP47BKnxFF+b
+khmzNu57vFOm+QciNNqeFc2qJR2t05X3Brg4BGrQlNoSCisGAQQBl1UBBQEBB0Bj
+AxenNtUFhwEs4M8HNuOUs8zqyUMcEhmfiTDziaLtSQMBCAc=
+=w41x
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestVerifyKey_UnusualPacketOrder(t *testing.T) {
+	step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(legacyLayoutKey)})
+	if key == nil {
+		t.Fatal("key could not be parsed")
+	}
+	parseStep := step.SubSteps[0]
+	assert.Equal(t, messageIDParse, parseStep.ID)
+	assert.Equal(t, StatusSuccess, parseStep.Status)
+	assert.Equal(t, []string{"The key's packets are in an unusual order and were put back in order: a user ID certification precedes its user ID, the key contains keyring trust packets, a subkey binding signature precedes its subkey"}, parseStep.Remarks)
+	assert.Equal(t, []string{
+		"a user ID certification precedes its user ID",
+		"the key contains keyring trust packets",
+		"a subkey binding signature precedes its subkey",
+	}, parseStep.Evidence["packet_order_issues"])
+}
+
+// encryptionKey was generated with gpg on 2026-10-15. Its encryption subkey expires after a year, on 2027-10-15.
+const encryptionKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCVXxYJKwYBBAHaRw8BAQdAt6gT6RXZ7q5Xb1tTIusr0UH3fwk/eqpbYKZS
+8Qk23GO0KEVuY3J5cHRpb24gVXNlciA8ZW5jcnlwdGlvbkBleGFtcGxlLmNvbT6I
+jwQTFggAOBYhBHzxK78yLTt0p/5oUvHZIL3X7oz+BQJq0JVfAhsDBQsJCAcCBhUK
+CQgLAgQWAgMBAh4BAheAAAoJEPHZIL3X7oz+TNYBAP3ZV7KY4JrsDNLZpLj1MuVS
+0yuH9Ly2rx369hzHZUBDAPjC2wRijv/bIyVaJbfkcp21fGAVhAch+Nc8cxGLQL0M
+uDgEatCVXxIKKwYBBAGXVQEFAQEHQMzkpPLozEWT0OGleXBzUcHnfqAQpqrpHNWM
+VJ+2TIwbAwEIB4h+BBgWCAAmFiEEfPErvzItO3Sn/mhS8dkgvdfujP4FAmrQlV8C
+GwwFCQHhM4AACgkQ8dkgvdfujP63YgEAwMYELPXDtrYm9bobNcXRXjYbHn0gJ7ki
+HPSp3wDhHdMBAI+PIObacIIU+LyJdCqxiWW1Vd1VZqFGf35WGyaK4ckG
+=aRB6
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestVerifyKey_Encryption(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		disabled bool
+		now      time.Time
+		status   Status
+		errors   []string
+		remarks  []string
+		evidence map[string]any
+	}{
+		{
+			name:   "valid encryption subkey",
+			key:    encryptionKey,
+			now:    time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			status: StatusSuccess,
+			remarks: []string{
+				"Encryption subkey 35F0227A3296ADA3: valid until 2027-10-15",
+				"A test message was encrypted to 35F0227A3296ADA3",
+			},
+			evidence: map[string]any{"encryption_keys": map[string]string{"35F0227A3296ADA3": "valid"}},
+		},
+		{
+			name:     "expired encryption subkey",
+			key:      encryptionKey,
+			now:      time.Date(2027, 12, 1, 0, 0, 0, 0, time.UTC),
+			status:   StatusWarning,
+			errors:   []string{"the key declares encryption capability, but a message cannot be encrypted to it: no encryption key is valid"},
+			remarks:  []string{"Encryption subkey 35F0227A3296ADA3: expired on 2027-10-15"},
+			evidence: map[string]any{"encryption_keys": map[string]string{"35F0227A3296ADA3": "expired"}},
+		},
+		{
+			name:    "no encryption capability",
+			key:     expiringKey,
+			now:     time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			status:  StatusSuccess,
+			remarks: []string{"The key does not declare encryption capability, which is not needed to sign providers"},
+		},
+		{
+			name:     "disabled",
+			key:      encryptionKey,
+			disabled: true,
+			now:      time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			status:   StatusNotRun,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, _ := VerifyKey(VerifyKeyOptions{
+				KeyData:         []byte(tt.key),
+				Now:             func() time.Time { return tt.now },
+				CheckEncryption: !tt.disabled,
+			})
+			var encryptionStep *Step
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDEncryption {
+					encryptionStep = s
+				}
+			}
+			if tt.status == StatusNotRun {
+				assert.Nil(t, encryptionStep)
+				return
+			}
+			assert.Equal(t, tt.status, encryptionStep.Status)
+			assert.Equal(t, tt.errors, encryptionStep.Errors)
+			assert.Equal(t, tt.remarks, encryptionStep.Remarks)
+			assert.Equal(t, tt.evidence, encryptionStep.Evidence)
+		})
+	}
+}
+
+// invalidRevocationKey was generated by GnuPG and carries a revocation signature whose last byte was altered, so that it
+// no longer verifies.
+const invalidRevocationKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdAB9+G6mNJEjmZ//2dwPH673+QvUKGQXBM7EEX
+YSDnv3CIjQQgFggANRYhBEgXsP9lhFRL4p/95W8wP1W8oC+hBQJq0JhjFx0DUmVw
+bGFjZWQgYnkgYSBuZXcga2V5AAoJEG8wP1W8oC+hpUABAMX5vvx2Jnpnv5GaAOIG
+tlUcGiaka2GUrXogbZcqEL7DAQCRicWJWQHkt4PR0XQXMEk+B/e7dHfHd6D6oFyI
+4IN/CbQhUmV2b2tlZCBPd25lciA8b3duZXJAZXhhbXBsZS5jb20+iJAEExYIADgW
+IQRIF7D/ZYRUS+Kf/eVvMD9VvKAvoQUCatCYTgIbAwULCQgHAgYVCgkICwIEFgID
+AQIeAQIXgAAKCRBvMD9VvKAvoeXuAQCdDHwMl6YWnRgdsLwl4PcC2v/YrcGnUO4T
+PNNhmAGuVwEAy3S70Ja88O0AupZ3kQ5EYYKjLw5X8j4+XbMo7yBbugo=
+=/7Rz
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestVerifyKey_InvalidRevocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		status   Status
+		errs     []string
+		evidence any
+	}{
+		{
+			name:   "invalid revocation",
+			key:    invalidRevocationKey,
+			status: StatusWarning,
+			errs:   []string{"invalid revocation signature present, it was ignored: revocation of the key: the signature does not verify: openpgp: invalid signature: EdDSA verification failure"},
+			evidence: []string{
+				"revocation of the key: the signature does not verify: openpgp: invalid signature: EdDSA verification failure",
+			},
+		},
+		{
+			name:   "no revocation",
+			key:    encryptionKey,
+			status: StatusSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, key := VerifyKey(VerifyKeyOptions{KeyData: []byte(tt.key)})
+			if key == nil {
+				t.Fatal("key could not be parsed")
+			}
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDRevocation {
+					// The invalid revocation is ignored rather than revoking the key
+					assert.Equal(t, StatusSuccess, s.Status)
+				}
+				if s.ID == StepIDRevocationSigs {
+					assert.Equal(t, tt.status, s.Status)
+					assert.Equal(t, tt.errs, s.Errors)
+					assert.Equal(t, tt.evidence, s.Evidence["invalid_revocations"])
+					return
+				}
+			}
+			t.Fatal("revocation signatures step not found")
+		})
+	}
+}
+
+// sha1PrefsKey was generated with gpg on 2026-10-15 with SHA-1 as its preferred hash algorithm.
+const sha1PrefsKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCa1BYJKwYBBAHaRw8BAQdAG5wtDn5FjNyKB9TTcUMWNMl9SrJQnJJXG2Ib
+mHLFEUy0HVNIQTEgUHJlZnMgPHNoYTFAZXhhbXBsZS5jb20+iIgEExYIADAWIQQO
+U22/0Sb1j71hijMYNmZbkFxx1QUCatCa1AIbAwILCQMVAggCFgICHgECF4AACgkQ
+GDZmW5BccdUefwD/SmF8K5+gBR8D4H4QUm/dpqPKzRg1qNtw20cmWb2HNlEBANnY
+JMMAqsArewbozxgFC34zeDx7pwjh0teSLDdyTtQM
+=UePY
+-----END PGP PUBLIC KEY BLOCK-----`
+
+func TestVerifyKey_SHA1Preferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    VerifyKeyOptions
+		status  Status
+		errs    []string
+		remarks []string
+	}{
+		{
+			name:    "informational by default",
+			status:  StatusSuccess,
+			remarks: []string{"Preferred hash algorithms: SHA1, SHA256", "Preferred symmetric algorithms: AES256", "The key prefers SHA1 as its hash algorithm"},
+		},
+		{
+			name:    "rejected",
+			opts:    VerifyKeyOptions{RejectSHA1Prefs: true},
+			status:  StatusFailure,
+			errs:    []string{"key prefers SHA1 as its hash algorithm"},
+			remarks: []string{"Preferred hash algorithms: SHA1, SHA256", "Preferred symmetric algorithms: AES256"},
+		},
+		{
+			name:    "severity override",
+			opts:    VerifyKeyOptions{Severity: SeverityOverrides{StepIDPreferences: StatusWarning}},
+			status:  StatusWarning,
+			errs:    []string{"key prefers SHA1 as its hash algorithm"},
+			remarks: []string{"Preferred hash algorithms: SHA1, SHA256", "Preferred symmetric algorithms: AES256"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.KeyData = []byte(sha1PrefsKey)
+			step, _ := VerifyKey(opts)
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDPreferences {
+					assert.Equal(t, tt.status, s.Status)
+					assert.Equal(t, tt.errs, s.Errors)
+					assert.Equal(t, tt.remarks, s.Remarks)
+					return
+				}
+			}
+			t.Fatal("preferences step not found")
+		})
+	}
+
+	// Explaining and listing the steps must not present the informational step as failing by default
+	assert.Equal(t, StatusWarning, stepDefinition(StepIDPreferences).Severity)
+}
+
+// desigRevokedKey was generated by GnuPG and revoked by its designated revoker, desigRevokerKey, with
+// "gpg --desig-revoke".
+const desigRevokedKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdAB9+G6mNJEjmZ//2dwPH673+QvUKGQXBM7EEX
+YSDnv3CIkAQfFggAOBYhBEgXsP9lhFRL4p/95W8wP1W8oC+hBQJq0JhWFwyAFpLV
+io9JI9kfdMMtukcQKDC+wKmvAgcAAAoJEG8wP1W8oC+hRhcBAMVMsadSGq+DkUaw
+rXpSTZ8BXDLO8+9w3X86gE7YAhSxAQDA5ek0CmdtQAYIKxsRTDuxflYLrYq71doW
+eRqvuOPcAoh4BCAWCAAgFiEEktWKj0kj2R90wy26RxAoML7Aqa8FAmrQmGcCHQAA
+CgkQRxAoML7Aqa8HsAEAmweMxiDN7LLeJpUCTZEixO6cD5DDOXOgYXcTSNj18CIA
+/R7Qyifv3kEMaLYL3r1EwaI2XyhhM3F2pPobof2r1fIBtCFSZXZva2VkIE93bmVy
+IDxvd25lckBleGFtcGxlLmNvbT6IkAQTFggAOBYhBEgXsP9lhFRL4p/95W8wP1W8
+oC+hBQJq0JhOAhsDBQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEG8wP1W8oC+h
+5e4BAJ0MfAyXphadGB2wvCXg9wLa/9itwadQ7hM802GYAa5XAQDLdLvQlrzw7QC6
+lneRDkRhgqMvDlfyPj5dsyjvIFu6Cg==
+=K9I2
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// desigRevokerKey is the designated revoker of desigRevokedKey.
+const desigRevokerKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatCYThYJKwYBBAHaRw8BAQdArP/pBQ3tyfWD/3idb0kVNSAZZDKQw2xehuOo
+oISKMmW0KERlc2lnbmF0ZWQgUmV2b2tlciA8cmV2b2tlckBleGFtcGxlLmNvbT6I
+kAQTFggAOBYhBJLVio9JI9kfdMMtukcQKDC+wKmvBQJq0JhOAhsDBQsJCAcCBhUK
+CQgLAgQWAgMBAh4BAheAAAoJEEcQKDC+wKmv/LUBAOCiKvtSdfHgJHRgPMMG9OCl
+da/SebnCBWv5lR8i9vdGAQCLGZL5TCBXhc7lxooZyqJaS+w0dkZt9QB9K0GkR/WT
+CQ==
+=ESEh
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestVerifyKey_DesignatedRevocation(t *testing.T) {
+	withRevoker := t.TempDir()
+	_, _, err := gpg.KeyCollection{Namespace: "example", Directory: withRevoker}.AddKey(desigRevokerKey, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts VerifyKeyOptions
+		err  string
+	}{
+		{
+			name: "verified with the revoker's key",
+			opts: VerifyKeyOptions{Org: "example", KeyDataDir: withRevoker},
+			err:  "key is revoked: no reason specified by the designated revoker 92D58A8F4923D91F74C32DBA47102830BEC0A9AF",
+		},
+		{
+			name: "revoker not in the registry",
+			opts: VerifyKeyOptions{Org: "example", KeyDataDir: t.TempDir()},
+			err:  "key is revoked: no reason specified by the designated revoker 92D58A8F4923D91F74C32DBA47102830BEC0A9AF, which could not be verified as its key is not among the registry keys of example",
+		},
+		{
+			name: "no registry keys",
+			err:  "key is revoked: no reason specified by the designated revoker 92D58A8F4923D91F74C32DBA47102830BEC0A9AF, which could not be verified without the registry keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.KeyData = []byte(desigRevokedKey)
+			step, _ := VerifyKey(opts)
+			for _, s := range step.SubSteps {
+				if s.ID == StepIDRevocation {
+					// The designated revoker's revocation keeps rejecting the key
+					assert.Equal(t, StatusFailure, s.Status)
+					assert.Equal(t, []string{tt.err}, s.Errors)
+				}
+				if s.ID == StepIDRevocationSigs {
+					assert.Equal(t, StatusSuccess, s.Status)
+					return
+				}
+			}
+			t.Fatal("revocation signatures step not found")
+		})
+	}
+}
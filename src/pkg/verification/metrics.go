@@ -0,0 +1,41 @@
+package verification
+
+import (
+	"fmt"
+	"io"
+)
+
+// WritePrometheusMetrics writes counters describing the outcome of the given results in the Prometheus text format.
+// Each result counts as one verified key, classified as "fail" if any step failed, "warn" if any step produced a
+// warning and "pass" otherwise. The GitHub request counter is only written if any result carries metadata.
+func WritePrometheusMetrics(w io.Writer, results []*Result) error {
+	counts := map[Outcome]int{OutcomePass: 0, OutcomeFail: 0, OutcomeWarn: 0}
+	var apiRequests int64
+	var hasMetadata bool
+	for _, r := range results {
+		counts[r.Outcome()]++
+		if r.Metadata != nil {
+			hasMetadata = true
+			apiRequests += r.Metadata.APIRequests
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "# HELP verify_keys_total Number of GPG keys verified, by outcome.\n# TYPE verify_keys_total counter\n")
+	if err != nil {
+		return err
+	}
+	for _, status := range []Outcome{OutcomePass, OutcomeFail, OutcomeWarn} {
+		_, err = fmt.Fprintf(w, "verify_keys_total{status=%q} %d\n", status, counts[status])
+		if err != nil {
+			return err
+		}
+	}
+
+	if hasMetadata {
+		_, err = fmt.Fprintf(w, "# HELP verify_github_api_requests_total Number of requests sent to GitHub.\n# TYPE verify_github_api_requests_total counter\nverify_github_api_requests_total %d\n", apiRequests)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
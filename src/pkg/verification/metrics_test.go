@@ -0,0 +1,43 @@
+package verification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	pass := &Result{}
+	pass.AddStep("Step 1", StatusSuccess)
+
+	fail := &Result{}
+	fail.AddStep("Step 1", StatusFailure)
+
+	warn := &Result{}
+	s := warn.AddStep("Step 1", StatusSuccess)
+	s.AddStep("Sub Step 1", StatusWarning)
+
+	var buf strings.Builder
+	err := WritePrometheusMetrics(&buf, []*Result{pass, fail, warn, pass})
+	assert.NoError(t, err)
+	assert.Equal(t, `# HELP verify_keys_total Number of GPG keys verified, by outcome.
+# TYPE verify_keys_total counter
+verify_keys_total{status="pass"} 2
+verify_keys_total{status="fail"} 1
+verify_keys_total{status="warn"} 1
+`, buf.String())
+}
+
+func TestWritePrometheusMetrics_APIRequests(t *testing.T) {
+	first := &Result{Metadata: &Metadata{APIRequests: 3}}
+	second := &Result{Metadata: &Metadata{APIRequests: 4}}
+
+	var buf strings.Builder
+	err := WritePrometheusMetrics(&buf, []*Result{first, second, {}})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `# HELP verify_github_api_requests_total Number of requests sent to GitHub.
+# TYPE verify_github_api_requests_total counter
+verify_github_api_requests_total 7
+`)
+}
@@ -0,0 +1,395 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/internal/gpg"
+	"github.com/opentofu/registry-stable/internal/provider"
+)
+
+// providerMatch records a provider release whose checksums were signed by the verified key.
+type providerMatch struct {
+	Org      string
+	Provider string
+	Version  string
+}
+
+// ProviderRegistry is a source of provider data for the providers scan, such as the public registry or an
+// organization's private mirror of it.
+type ProviderRegistry struct {
+	Name            string // Identifies the registry in remarks and evidence
+	ProviderDataDir string // Directory containing the provider data of the registry
+}
+
+// defaultRegistryName is the name of the registry in opts.ProviderDataDir.
+const defaultRegistryName = "opentofu"
+
+// RegistryScan is the outcome of the providers scan in one registry.
+type RegistryScan struct {
+	Registry        string   `json:"registry"`
+	Matched         bool     `json:"matched"`
+	MatchedReleases []string `json:"matched_releases,omitempty"`
+}
+
+// registries returns the registries the providers scan checks: the one in opts.ProviderDataDir, if set, followed by
+// opts.Registries.
+func (opts VerifyKeyOptions) registries() []ProviderRegistry {
+	var registries []ProviderRegistry
+	if opts.ProviderDataDir != "" {
+		registries = append(registries, ProviderRegistry{Name: defaultRegistryName, ProviderDataDir: opts.ProviderDataDir})
+	}
+	return append(registries, opts.Registries...)
+}
+
+// VerifyKeyInProviders checks that the key has signed the latest release of at least one provider of the namespace of
+// the key, scanning the provider data of every configured registry. Keys can be rotated before their first release, so
+// a key that signed none of the providers only produces a warning by default. The type of the namespace and the
+// providers the key signed are recorded as evidence.
+func VerifyKeyInProviders(ctx context.Context, opts VerifyKeyOptions, key *crypto.Key) *Step {
+	verifyStep := &Step{
+		ID:       messageIDValidateProviders,
+		Name:     opts.Catalog.StepName(messageIDValidateProviders, "Validate provider signatures"),
+		progress: opts.Progress,
+	}
+	defer verifyStep.reportProgress()
+
+	orgs := opts.ProviderOrgs
+	namespaceType := "organization"
+	if len(orgs) == 0 {
+		orgs = []string{opts.namespace()}
+		if opts.userNamespace() {
+			namespaceType = "user"
+		}
+	}
+	name := opts.Catalog.stepName(StepIDProviders, strings.Join(orgs, ", "))
+	registries := opts.registries()
+
+	switch {
+	case key == nil:
+		verifyStep.SkipStep(name, "the key could not be parsed").withID(StepIDProviders)
+		return verifyStep
+	case opts.NewKey:
+		verifyStep.SkipStep(name, "no provider published yet").withID(StepIDProviders)
+		return verifyStep
+	case len(registries) == 0:
+		verifyStep.SkipStep(name, "no provider data directory configured").withID(StepIDProviders)
+		return verifyStep
+	}
+
+	var remarks []string
+	var matchedOrgs, matchedReleases, associated []string
+	var coverage []ProviderCoverage
+	var scans []RegistryScan
+	var noProviders bool
+	s := opts.runStepContext(ctx, verifyStep, StepIDProviders, name, opts.RequireSignedProvider, func(ctx context.Context) error {
+		matched := false
+		found := false
+		var offline []error
+		for _, registry := range registries {
+			// Remarks only name the registry if there is more than one
+			var prefix string
+			if len(registries) > 1 {
+				prefix = fmt.Sprintf("Registry %s: ", registry.Name)
+			}
+
+			orgProviders := make(map[string]provider.List)
+			for _, org := range orgs {
+				providers, err := listOrgProviders(registry.ProviderDataDir, org)
+				if err != nil {
+					return err
+				}
+				if len(providers) > 0 {
+					orgProviders[org] = providers
+				}
+			}
+			scan := RegistryScan{Registry: registry.Name}
+			if len(orgProviders) == 0 {
+				if len(registries) > 1 {
+					remarks = append(remarks, fmt.Sprintf("%sno providers found for %s", prefix, strings.Join(orgs, ", ")))
+				}
+				scans = append(scans, scan)
+				continue
+			}
+			found = true
+
+			for _, org := range orgs {
+				var unreachable []error
+				if opts.FullCoverage {
+					var orgCoverage []ProviderCoverage
+					var err error
+					orgCoverage, unreachable, err = scanProviders(ctx, opts.Github, key, orgProviders[org], false)
+					if err != nil {
+						return err
+					}
+					coverage = append(coverage, orgCoverage...)
+					for _, c := range orgCoverage {
+						associated = append(associated, c.Namespace+"/"+c.Provider)
+					}
+
+					signed, checked := 0, 0
+					for _, c := range orgCoverage {
+						for _, version := range c.Signed {
+							scan.MatchedReleases = append(scan.MatchedReleases, fmt.Sprintf("%s/%s %s", org, c.Provider, version))
+						}
+						signed += len(c.Signed)
+						checked += len(c.Signed) + len(c.Unsigned)
+					}
+					if signed > 0 {
+						scan.Matched = true
+						matchedOrgs = appendUnique(matchedOrgs, org)
+					}
+					remarks = append(remarks, fmt.Sprintf("%sOrganization %s: the key signed %d of %d checked release(s) of %d provider(s)", prefix, org, signed, checked, len(orgCoverage)))
+				} else {
+					var match *providerMatch
+					var err error
+					match, unreachable, err = findSignedProvider(ctx, opts.Github, key, org, orgProviders[org])
+					if err != nil {
+						return err
+					}
+					if match != nil {
+						scan.Matched = true
+						remarks = append(remarks, fmt.Sprintf("%sMatched organization %s: the key signed %s/%s %s", prefix, match.Org, match.Org, match.Provider, match.Version))
+						matchedOrgs = appendUnique(matchedOrgs, match.Org)
+						associated = append(associated, match.Org+"/"+match.Provider)
+						scan.MatchedReleases = append(scan.MatchedReleases, fmt.Sprintf("%s/%s %s", match.Org, match.Provider, match.Version))
+					}
+				}
+				for _, err := range unreachable {
+					remarks = append(remarks, fmt.Sprintf("%sProvider unreachable, skipped: %s", prefix, err))
+					if github.IsUnreachable(err) {
+						offline = append(offline, err)
+					}
+				}
+			}
+			matched = matched || scan.Matched
+			matchedReleases = append(matchedReleases, scan.MatchedReleases...)
+			scans = append(scans, scan)
+		}
+		if !found {
+			noProviders = true
+			return nil
+		}
+		if matched {
+			return nil
+		}
+		if len(offline) > 0 {
+			// The skipped providers may well be the ones the key signed
+			return checkReachable(fmt.Errorf("could not check %d provider(s): %w", len(offline), offline[0]))
+		}
+		if len(registries) > 1 {
+			return fmt.Errorf("the key has not signed the latest release of any reachable provider in %s in any of the %d registries", strings.Join(orgs, ", "), len(registries))
+		}
+		return fmt.Errorf("the key has not signed the latest release of any reachable provider in %s", strings.Join(orgs, ", "))
+	})
+	if s.Status != StatusSkipped {
+		s.AddEvidence("namespace_type", namespaceType)
+	}
+	if noProviders {
+		s.Status = StatusSkipped
+		remarks = append(remarks, fmt.Sprintf("no providers found in the registry for %s", strings.Join(orgs, ", ")))
+	}
+	s.Remarks = append(s.Remarks, remarks...)
+	if len(matchedOrgs) > 0 {
+		s.AddEvidence("matched_orgs", matchedOrgs)
+		s.AddEvidence("matched_releases", matchedReleases)
+	}
+	if coverage != nil {
+		s.AddEvidence("coverage", coverage)
+	}
+	if len(registries) > 1 && scans != nil {
+		s.AddEvidence("registries", scans)
+	}
+	if len(associated) > 0 {
+		s.AddProviders(associated...)
+	}
+
+	return verifyStep
+}
+
+// appendUnique appends item to items unless it is already in it.
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// listOrgProviders returns the providers of the given organization in the provider data directory.
+func listOrgProviders(providerDataDir string, org string) (provider.List, error) {
+	providers, err := provider.ListProviders(providerDataDir, org, slog.Default(), github.Client{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list providers of %s: %w", org, err)
+	}
+
+	// ListProviders matches namespaces by prefix
+	var result provider.List
+	for _, p := range providers {
+		if strings.EqualFold(p.Namespace, org) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// findSignedProvider returns the first provider whose latest release has checksums signed by the key, or nil if
+// there is none. Providers whose metadata or assets cannot be fetched are skipped and returned as unreachable, so that
+// a single misconfigured provider does not mask a match in the others. An error is only returned if ctx is done.
+func findSignedProvider(ctx context.Context, client GithubClient, key *crypto.Key, org string, providers provider.List) (*providerMatch, []error, error) {
+	var unreachable []error
+	for _, p := range providers {
+		if ctx.Err() != nil {
+			return nil, unreachable, ctx.Err()
+		}
+
+		meta, err := p.ReadMetadata()
+		if err != nil {
+			unreachable = append(unreachable, fmt.Errorf("%s/%s: %w", org, p.ProviderName, err))
+			continue
+		}
+		if len(meta.Versions) == 0 {
+			continue
+		}
+		// Versions are stored newest first
+		version := meta.Versions[0]
+
+		signed, err := releaseSignedBy(ctx, client, key, version)
+		if err != nil {
+			unreachable = append(unreachable, fmt.Errorf("%s/%s: %w", org, p.ProviderName, err))
+			continue
+		}
+		if signed {
+			return &providerMatch{Org: org, Provider: p.ProviderName, Version: version.Version}, unreachable, nil
+		}
+	}
+	return nil, unreachable, nil
+}
+
+// releaseSignedBy reports whether the checksums of the provider release were signed by the key. A release whose
+// checksums or signature cannot be downloaded, including because they do not exist, is reported as an error.
+func releaseSignedBy(ctx context.Context, client GithubClient, key *crypto.Key, version provider.Version) (bool, error) {
+	sums, err := downloadAsset(ctx, client, version.SHASumsURL)
+	if err != nil {
+		return false, err
+	}
+	signature, err := downloadAsset(ctx, client, version.SHASumsSignatureURL)
+	if err != nil {
+		return false, err
+	}
+	// The client returns no contents for assets that do not exist, the release cannot be checked
+	if sums == nil {
+		return false, fmt.Errorf("%s was not found", version.SHASumsURL)
+	}
+	if signature == nil {
+		return false, fmt.Errorf("%s was not found", version.SHASumsSignatureURL)
+	}
+
+	_, err = gpg.VerifyDetachedSignature(key, sums, signature)
+	return err == nil, nil
+}
+
+// downloadAsset downloads the asset with the client, giving up once ctx is done if the client supports it.
+func downloadAsset(ctx context.Context, client GithubClient, downloadURL string) ([]byte, error) {
+	if c, ok := client.(AssetContextClient); ok {
+		return c.DownloadAssetContentsContext(ctx, downloadURL)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.DownloadAssetContents(downloadURL)
+}
+
+// SignedProvider lists the releases of a provider whose checksums were signed by a key.
+type SignedProvider struct {
+	Namespace string   `json:"namespace"`
+	Provider  string   `json:"provider"`
+	Versions  []string `json:"versions"` // Newest first
+}
+
+// ScanSignedProviders reports which releases of the providers in providerDataDir have checksums signed by the key. Only
+// the providers of the given organizations are scanned, or all providers if none are given. If latestOnly is set, only
+// the latest release of each provider is checked. Releases that cannot be checked are skipped and returned as errors,
+// the returned error is only set if the providers could not be listed or ctx is done.
+func ScanSignedProviders(ctx context.Context, client GithubClient, key *crypto.Key, providerDataDir string, orgs []string, latestOnly bool) ([]SignedProvider, []error, error) {
+	var providers provider.List
+	if len(orgs) == 0 {
+		all, err := provider.ListProviders(providerDataDir, "", slog.Default(), github.Client{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list providers: %w", err)
+		}
+		providers = all
+	}
+	for _, org := range orgs {
+		orgProviders, err := listOrgProviders(providerDataDir, org)
+		if err != nil {
+			return nil, nil, err
+		}
+		providers = append(providers, orgProviders...)
+	}
+
+	coverage, unreachable, err := scanProviders(ctx, client, key, providers, latestOnly)
+	if err != nil {
+		return nil, unreachable, err
+	}
+	var signed []SignedProvider
+	for _, c := range coverage {
+		if len(c.Signed) > 0 {
+			signed = append(signed, SignedProvider{Namespace: c.Namespace, Provider: c.Provider, Versions: c.Signed})
+		}
+	}
+	return signed, unreachable, nil
+}
+
+// ProviderCoverage lists which releases of a provider have checksums signed by a key. Releases that could not be
+// checked are in neither list.
+type ProviderCoverage struct {
+	Namespace string   `json:"namespace"`
+	Provider  string   `json:"provider"`
+	Signed    []string `json:"signed"`   // Newest first
+	Unsigned  []string `json:"unsigned"` // Newest first
+}
+
+// scanProviders checks which releases of the providers have checksums signed by the key. If latestOnly is set, only
+// the latest release of each provider is checked. Releases that cannot be checked are skipped and returned as errors,
+// the returned error is only set if ctx is done.
+func scanProviders(ctx context.Context, client GithubClient, key *crypto.Key, providers provider.List, latestOnly bool) ([]ProviderCoverage, []error, error) {
+	var coverage []ProviderCoverage
+	var unreachable []error
+	for _, p := range providers {
+		meta, err := p.ReadMetadata()
+		if err != nil {
+			unreachable = append(unreachable, fmt.Errorf("%s/%s: %w", p.Namespace, p.ProviderName, err))
+			continue
+		}
+
+		versions := meta.Versions
+		if latestOnly && len(versions) > 1 {
+			versions = versions[:1]
+		}
+		result := ProviderCoverage{Namespace: p.Namespace, Provider: p.ProviderName}
+		for _, version := range versions {
+			if ctx.Err() != nil {
+				return nil, unreachable, ctx.Err()
+			}
+			ok, err := releaseSignedBy(ctx, client, key, version)
+			if err != nil {
+				unreachable = append(unreachable, fmt.Errorf("%s/%s %s: %w", p.Namespace, p.ProviderName, version.Version, err))
+				continue
+			}
+			if ok {
+				result.Signed = append(result.Signed, version.Version)
+			} else {
+				result.Unsigned = append(result.Unsigned, version.Version)
+			}
+		}
+		coverage = append(coverage, result)
+	}
+	return coverage, unreachable, nil
+}
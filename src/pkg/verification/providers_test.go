@@ -0,0 +1,424 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	neturl "net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/provider"
+)
+
+// writeTestProvider adds a provider with a single release to the provider data directory and returns the assets of
+// that release, with the checksums signed by the given key.
+func writeTestProvider(t *testing.T, dir string, org string, name string, signer *crypto.Key) map[string][]byte {
+	t.Helper()
+
+	base := "https://github.com/" + org + "/terraform-provider-" + name + "/releases/download/v1.0.0/"
+	p := provider.Provider{Namespace: org, ProviderName: name, Directory: dir}
+	err := p.WriteMetadata(provider.Metadata{Versions: []provider.Version{{
+		Version:             "1.0.0",
+		SHASumsURL:          base + "SHA256SUMS",
+		SHASumsSignatureURL: base + "SHA256SUMS.sig",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sums := []byte("abc  terraform-provider-" + name + "_1.0.0_linux_amd64.zip\n")
+	keyRing, err := crypto.NewKeyRing(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := keyRing.SignDetached(crypto.NewPlainMessage(sums))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]byte{
+		base + "SHA256SUMS":     sums,
+		base + "SHA256SUMS.sig": signature.GetBinary(),
+	}
+}
+
+func TestVerifyKeyInProviders(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "providers")
+	assets := map[string][]byte{}
+	for url, contents := range writeTestProvider(t, dir, "first", "foo", otherKey) {
+		assets[url] = contents
+	}
+	for url, contents := range writeTestProvider(t, dir, "second", "bar", key) {
+		assets[url] = contents
+	}
+	// Shares a prefix with "first" but must not be scanned for it
+	for url, contents := range writeTestProvider(t, dir, "firstly", "baz", key) {
+		assets[url] = contents
+	}
+	// Unreachable, its checksums are not found and the client returns no contents as it does for a 404
+	writeTestProvider(t, dir, "broken", "foo", key)
+	assetErrs := map[string]error{}
+	// GitHub cannot be reached to download its checksums
+	for url := range writeTestProvider(t, dir, "offline", "foo", key) {
+		assetErrs[url] = &neturl.Error{Op: "Get", URL: url, Err: errors.New("connection refused")}
+	}
+	client := fakeGithubClient{assets: assets, assetErrs: assetErrs}
+
+	tests := []struct {
+		name     string
+		opts     VerifyKeyOptions
+		status   Status
+		remarks  []string
+		evidence map[string]any
+	}{
+		{
+			name:    "signed provider in one of several orgs",
+			opts:    VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"first", "second"}},
+			status:  StatusSuccess,
+			remarks: []string{"Matched organization second: the key signed second/bar 1.0.0"},
+			evidence: map[string]any{
+				"namespace_type":   "organization",
+				"matched_orgs":     []string{"second"},
+				"matched_releases": []string{"second/bar 1.0.0"},
+				"providers":        []string{"second/bar"},
+			},
+		},
+		{
+			name:   "unreachable provider does not mask a match",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"broken", "second"}},
+			status: StatusSuccess,
+			remarks: []string{
+				"Provider unreachable, skipped: broken/foo: https://github.com/broken/terraform-provider-foo/releases/download/v1.0.0/SHA256SUMS was not found",
+				"Matched organization second: the key signed second/bar 1.0.0",
+			},
+		},
+		{
+			name:   "defaults to the membership org",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, Org: "second"},
+			status: StatusSuccess,
+		},
+		{
+			name:   "personal namespace defaults to the username",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, Username: "second", SkipOrgCheck: true},
+			status: StatusSuccess,
+		},
+		{
+			name:   "user namespace",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, Username: "second", Org: "first", UserNamespace: true},
+			status: StatusSuccess,
+			evidence: map[string]any{
+				"namespace_type":   "user",
+				"matched_orgs":     []string{"second"},
+				"matched_releases": []string{"second/bar 1.0.0"},
+				"providers":        []string{"second/bar"},
+			},
+		},
+		{
+			name:   "no signed provider",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"first"}},
+			status: StatusWarning,
+		},
+		{
+			name:    "only unreachable providers",
+			opts:    VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"broken"}, RequireSignedProvider: true},
+			status:  StatusFailure,
+			remarks: []string{"Provider unreachable, skipped: broken/foo: https://github.com/broken/terraform-provider-foo/releases/download/v1.0.0/SHA256SUMS was not found"},
+		},
+		{
+			name:   "github unreachable is not a negative verdict",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"first", "offline"}},
+			status: StatusError,
+		},
+		{
+			name:   "no signed provider when required",
+			opts:   VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"first"}, RequireSignedProvider: true},
+			status: StatusFailure,
+		},
+		{
+			name:    "no providers",
+			opts:    VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"third"}},
+			status:  StatusSkipped,
+			remarks: []string{"no providers found in the registry for third"},
+		},
+		{
+			name:    "new key",
+			opts:    VerifyKeyOptions{ProviderDataDir: dir, ProviderOrgs: []string{"second"}, NewKey: true, RequireSignedProvider: true},
+			status:  StatusSkipped,
+			remarks: []string{"no provider published yet"},
+		},
+		{
+			name:    "no provider data",
+			opts:    VerifyKeyOptions{ProviderOrgs: []string{"second"}},
+			status:  StatusSkipped,
+			remarks: []string{"no provider data directory configured"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Github = client
+			step := VerifyKeyInProviders(context.Background(), tt.opts, key)
+			assert.Len(t, step.SubSteps, 1)
+			assert.Equal(t, tt.status, step.SubSteps[0].Status)
+			if tt.remarks != nil {
+				assert.Equal(t, tt.remarks, step.SubSteps[0].Remarks)
+			}
+			if tt.evidence != nil {
+				assert.Equal(t, tt.evidence, step.SubSteps[0].Evidence)
+			}
+		})
+	}
+}
+
+func TestScanSignedProviders(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "providers")
+	assets := map[string][]byte{}
+	for url, contents := range writeTestProvider(t, dir, "first", "foo", otherKey) {
+		assets[url] = contents
+	}
+	for url, contents := range writeTestProvider(t, dir, "second", "bar", key) {
+		assets[url] = contents
+	}
+
+	// The key was rotated, the older release was signed by it but the latest one was not
+	rotated := provider.Provider{Namespace: "second", ProviderName: "baz", Directory: dir}
+	var versions []provider.Version
+	for _, release := range []struct {
+		version string
+		signer  *crypto.Key
+	}{{"2.0.0", otherKey}, {"1.0.0", key}} {
+		base := "https://github.com/second/terraform-provider-baz/releases/download/v" + release.version + "/"
+		versions = append(versions, provider.Version{Version: release.version, SHASumsURL: base + "SHA256SUMS", SHASumsSignatureURL: base + "SHA256SUMS.sig"})
+
+		sums := []byte("abc  terraform-provider-baz_" + release.version + "_linux_amd64.zip\n")
+		keyRing, err := crypto.NewKeyRing(release.signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := keyRing.SignDetached(crypto.NewPlainMessage(sums))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assets[base+"SHA256SUMS"] = sums
+		assets[base+"SHA256SUMS.sig"] = signature.GetBinary()
+	}
+	if err := rotated.WriteMetadata(provider.Metadata{Versions: versions}); err != nil {
+		t.Fatal(err)
+	}
+	client := fakeGithubClient{assets: assets}
+
+	tests := []struct {
+		name       string
+		orgs       []string
+		latestOnly bool
+		expected   []SignedProvider
+	}{
+		{
+			name: "whole registry",
+			expected: []SignedProvider{
+				{Namespace: "second", Provider: "bar", Versions: []string{"1.0.0"}},
+				{Namespace: "second", Provider: "baz", Versions: []string{"1.0.0"}},
+			},
+		},
+		{
+			name:       "latest releases only",
+			orgs:       []string{"second"},
+			latestOnly: true,
+			expected:   []SignedProvider{{Namespace: "second", Provider: "bar", Versions: []string{"1.0.0"}}},
+		},
+		{
+			name: "organization without signed releases",
+			orgs: []string{"first"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, unreachable, err := ScanSignedProviders(context.Background(), client, key, dir, tt.orgs, tt.latestOnly)
+			assert.NoError(t, err)
+			assert.Empty(t, unreachable)
+			assert.Equal(t, tt.expected, signed)
+		})
+	}
+}
+
+func TestVerifyKeyInProviders_FullCoverage(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "providers")
+	assets := map[string][]byte{}
+	for url, contents := range writeTestProvider(t, dir, "first", "foo", otherKey) {
+		assets[url] = contents
+	}
+	for url, contents := range writeTestProvider(t, dir, "second", "bar", key) {
+		assets[url] = contents
+	}
+	for url, contents := range writeTestProvider(t, dir, "second", "qux", otherKey) {
+		assets[url] = contents
+	}
+	client := fakeGithubClient{assets: assets}
+
+	opts := VerifyKeyOptions{Github: client, ProviderDataDir: dir, ProviderOrgs: []string{"first", "second"}, FullCoverage: true}
+	step := VerifyKeyInProviders(context.Background(), opts, key)
+	assert.Len(t, step.SubSteps, 1)
+	assert.Equal(t, StatusSuccess, step.SubSteps[0].Status)
+	assert.Equal(t, []string{
+		"Organization first: the key signed 0 of 1 checked release(s) of 1 provider(s)",
+		"Organization second: the key signed 1 of 2 checked release(s) of 2 provider(s)",
+	}, step.SubSteps[0].Remarks)
+	assert.Equal(t, map[string]any{
+		"namespace_type":   "organization",
+		"matched_orgs":     []string{"second"},
+		"matched_releases": []string{"second/bar 1.0.0"},
+		"coverage": []ProviderCoverage{
+			{Namespace: "first", Provider: "foo", Unsigned: []string{"1.0.0"}},
+			{Namespace: "second", Provider: "bar", Signed: []string{"1.0.0"}},
+			{Namespace: "second", Provider: "qux", Unsigned: []string{"1.0.0"}},
+		},
+		"providers": []string{"first/foo", "second/bar", "second/qux"},
+	}, step.SubSteps[0].Evidence)
+
+	// Without a signed release the full matrix is still reported
+	opts.ProviderOrgs = []string{"first"}
+	step = VerifyKeyInProviders(context.Background(), opts, key)
+	assert.Equal(t, StatusWarning, step.SubSteps[0].Status)
+	assert.Equal(t, []ProviderCoverage{{Namespace: "first", Provider: "foo", Unsigned: []string{"1.0.0"}}}, step.SubSteps[0].Evidence["coverage"])
+}
+
+func TestVerifyKeyInProviders_Registries(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public := filepath.Join(t.TempDir(), "providers")
+	mirror := filepath.Join(t.TempDir(), "providers")
+	empty := filepath.Join(t.TempDir(), "providers")
+	assets := map[string][]byte{}
+	for url, contents := range writeTestProvider(t, public, "first", "foo", otherKey) {
+		assets[url] = contents
+	}
+	for url, contents := range writeTestProvider(t, mirror, "first", "bar", key) {
+		assets[url] = contents
+	}
+	client := fakeGithubClient{assets: assets}
+
+	// Signed only in the mirror
+	opts := VerifyKeyOptions{
+		Github:          client,
+		ProviderDataDir: public,
+		Registries:      []ProviderRegistry{{Name: "mirror", ProviderDataDir: mirror}, {Name: "staging", ProviderDataDir: empty}},
+		ProviderOrgs:    []string{"first"},
+	}
+	step := VerifyKeyInProviders(context.Background(), opts, key)
+	assert.Len(t, step.SubSteps, 1)
+	assert.Equal(t, StatusSuccess, step.SubSteps[0].Status)
+	assert.Equal(t, []string{
+		"Registry mirror: Matched organization first: the key signed first/bar 1.0.0",
+		"Registry staging: no providers found for first",
+	}, step.SubSteps[0].Remarks)
+	assert.Equal(t, []RegistryScan{
+		{Registry: "opentofu"},
+		{Registry: "mirror", Matched: true, MatchedReleases: []string{"first/bar 1.0.0"}},
+		{Registry: "staging"},
+	}, step.SubSteps[0].Evidence["registries"])
+
+	// Signed in none of them
+	opts.Registries = opts.Registries[1:]
+	step = VerifyKeyInProviders(context.Background(), opts, key)
+	assert.Equal(t, StatusWarning, step.SubSteps[0].Status)
+	assert.Equal(t, []string{"the key has not signed the latest release of any reachable provider in first in any of the 2 registries"}, step.SubSteps[0].Errors)
+
+	// A private mirror alone
+	opts = VerifyKeyOptions{Github: client, Registries: []ProviderRegistry{{Name: "mirror", ProviderDataDir: mirror}}, ProviderOrgs: []string{"first"}}
+	step = VerifyKeyInProviders(context.Background(), opts, key)
+	assert.Equal(t, StatusSuccess, step.SubSteps[0].Status)
+	assert.Equal(t, []string{"Matched organization first: the key signed first/bar 1.0.0"}, step.SubSteps[0].Remarks)
+	assert.NotContains(t, step.SubSteps[0].Evidence, "registries")
+}
+
+func TestDiscoverOrgs(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "providers")
+	assets := map[string][]byte{}
+	for _, p := range []struct {
+		org    string
+		name   string
+		signer *crypto.Key
+	}{
+		{"first", "foo", otherKey},
+		{"second", "bar", key},
+		{"third", "baz", key},
+		{"third", "qux", key},
+		{"third", "quux", otherKey},
+	} {
+		for url, contents := range writeTestProvider(t, dir, p.org, p.name, p.signer) {
+			assets[url] = contents
+		}
+	}
+	client := fakeGithubClient{assets: assets}
+
+	orgs, unreachable, err := DiscoverOrgs(context.Background(), client, key, dir, []string{"first", "second", "third", "fourth"}, false)
+	assert.NoError(t, err)
+	assert.Empty(t, unreachable)
+	assert.Equal(t, []OrgMatch{
+		{
+			Org:       "third",
+			Providers: 3,
+			SignedProviders: []SignedProvider{
+				{Namespace: "third", Provider: "baz", Versions: []string{"1.0.0"}},
+				{Namespace: "third", Provider: "qux", Versions: []string{"1.0.0"}},
+			},
+			SignedReleases:  2,
+			CheckedReleases: 3,
+		},
+		{
+			Org:             "second",
+			Providers:       1,
+			SignedProviders: []SignedProvider{{Namespace: "second", Provider: "bar", Versions: []string{"1.0.0"}}},
+			SignedReleases:  1,
+			CheckedReleases: 1,
+		},
+		{Org: "first", Providers: 1, CheckedReleases: 1},
+		{Org: "fourth"},
+	}, orgs)
+}
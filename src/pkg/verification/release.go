@@ -0,0 +1,217 @@
+package verification
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/github"
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+// VerifyReleaseSignature checks that the key signed the checksums file of the GitHub release opts.ReleaseTag in
+// opts.ReleaseRepo, proving that the key actually signs the release artifacts rather than only registry data. The
+// checksums files and the release assets they cover are reported as evidence. If opts.VerifyReleaseDigests is set, the
+// assets listed in the signed checksums files are downloaded and their SHA-256 digests compared to the listed ones.
+func VerifyReleaseSignature(ctx context.Context, opts VerifyKeyOptions, key *crypto.Key) *Step {
+	verifyStep := &Step{
+		ID:       messageIDValidateRelease,
+		Name:     opts.Catalog.StepName(messageIDValidateRelease, "Validate release signature"),
+		progress: opts.Progress,
+	}
+	defer verifyStep.reportProgress()
+
+	// The release steps concern the provider published from the repository
+	if provider := releaseProvider(opts.ReleaseRepo); provider != "" {
+		defer func() {
+			for _, s := range verifyStep.SubSteps {
+				s.AddProviders(provider)
+			}
+		}()
+	}
+
+	release := fmt.Sprintf("%s@%s", opts.ReleaseRepo, opts.ReleaseTag)
+	name := opts.Catalog.stepName(StepIDReleaseSignature, release)
+	if key == nil {
+		verifyStep.SkipStep(name, "the key could not be parsed").withID(StepIDReleaseSignature)
+		return verifyStep
+	}
+
+	var remarks []string
+	var verifiedAssets, checksummedAssets []string
+	var assets map[string]github.ReleaseAsset
+	signedSums := make(map[string][]byte)
+	s := opts.runStepContext(ctx, verifyStep, StepIDReleaseSignature, name, false, func(_ context.Context) error {
+		ghRelease, err := opts.Github.GetReleaseByTag(opts.ReleaseRepo, opts.ReleaseTag)
+		if err != nil {
+			return checkReachable(err)
+		}
+
+		assets = make(map[string]github.ReleaseAsset, len(ghRelease.Assets))
+		for _, asset := range ghRelease.Assets {
+			assets[asset.Name] = asset
+		}
+
+		found := false
+		for _, sumsAsset := range ghRelease.Assets {
+			if !strings.HasSuffix(sumsAsset.Name, "SHA256SUMS") {
+				continue
+			}
+			sigAsset, ok := assets[sumsAsset.Name+".sig"]
+			if !ok {
+				remarks = append(remarks, fmt.Sprintf("%s has no signature, skipped", sumsAsset.Name))
+				continue
+			}
+			found = true
+
+			sums, err := opts.Github.DownloadAssetContents(sumsAsset.DownloadURL)
+			if err != nil {
+				return checkReachable(fmt.Errorf("could not download %s: %w", sumsAsset.Name, err))
+			}
+			signature, err := opts.Github.DownloadAssetContents(sigAsset.DownloadURL)
+			if err != nil {
+				return checkReachable(fmt.Errorf("could not download %s: %w", sigAsset.Name, err))
+			}
+			if _, err := gpg.VerifyDetachedSignature(key, sums, signature); err != nil {
+				return fmt.Errorf("the key did not sign %s of release %s: %w", sumsAsset.Name, release, err)
+			}
+
+			remarks = append(remarks, fmt.Sprintf("Verified %s with %s", sumsAsset.Name, sigAsset.Name))
+			verifiedAssets = append(verifiedAssets, sumsAsset.Name, sigAsset.Name)
+			signedSums[sumsAsset.Name] = sums
+			for _, checksummed := range checksummedFiles(sums) {
+				if _, ok := assets[checksummed]; ok {
+					checksummedAssets = append(checksummedAssets, checksummed)
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("release %s has no signed SHA256SUMS file", release)
+		}
+		return nil
+	})
+	s.Remarks = append(s.Remarks, remarks...)
+	if len(verifiedAssets) > 0 {
+		s.AddEvidence("release", release)
+		s.AddEvidence("verified_assets", verifiedAssets)
+		s.AddEvidence("checksummed_assets", checksummedAssets)
+	}
+
+	if opts.VerifyReleaseDigests {
+		digestsName := opts.Catalog.stepName(StepIDReleaseChecksums, release)
+		if s.Status != StatusSuccess {
+			verifyStep.SkipStep(digestsName, "the checksums are not signed by the key").withID(StepIDReleaseChecksums)
+			return verifyStep
+		}
+		verifyReleaseDigests(ctx, opts, verifyStep, digestsName, assets, signedSums)
+	}
+
+	return verifyStep
+}
+
+// releaseProvider returns the provider published from an "owner/terraform-provider-name" repository as "owner/name",
+// or an empty string for repositories that are not named like provider repositories.
+func releaseProvider(repo string) string {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return ""
+	}
+	name, ok = strings.CutPrefix(name, "terraform-provider-")
+	if !ok || owner == "" || name == "" {
+		return ""
+	}
+	return strings.ToLower(owner + "/" + name)
+}
+
+// verifyReleaseDigests checks that every entry of the signed checksums files is a SHA-256 digest and that it matches
+// the digest of the release asset it names. Entries for files that are not attached to the release are reported as
+// remarks, since some providers list artifacts published elsewhere.
+func verifyReleaseDigests(ctx context.Context, opts VerifyKeyOptions, parent *Step, name string, assets map[string]github.ReleaseAsset, signedSums map[string][]byte) {
+	var remarks, matched, mismatched []string
+	s := opts.runStepContext(ctx, parent, StepIDReleaseChecksums, name, false, func(_ context.Context) error {
+		sumsNames := make([]string, 0, len(signedSums))
+		for sumsName := range signedSums {
+			sumsNames = append(sumsNames, sumsName)
+		}
+		slices.Sort(sumsNames)
+
+		for _, sumsName := range sumsNames {
+			for _, entry := range checksumEntries(signedSums[sumsName]) {
+				if !isSHA256Digest(entry.Digest) {
+					return fmt.Errorf("%s lists %s with %q, which is not a SHA-256 digest", sumsName, entry.Name, entry.Digest)
+				}
+				asset, ok := assets[entry.Name]
+				if !ok {
+					remarks = append(remarks, fmt.Sprintf("%s is listed in %s but not attached to the release, skipped", entry.Name, sumsName))
+					continue
+				}
+				contents, err := opts.Github.DownloadAssetContents(asset.DownloadURL)
+				if err != nil {
+					return checkReachable(fmt.Errorf("could not download %s: %w", entry.Name, err))
+				}
+				digest := sha256.Sum256(contents)
+				if !strings.EqualFold(hex.EncodeToString(digest[:]), entry.Digest) {
+					mismatched = append(mismatched, entry.Name)
+					continue
+				}
+				matched = append(matched, entry.Name)
+			}
+		}
+
+		if len(mismatched) > 0 {
+			return fmt.Errorf("the SHA-256 digest of %s does not match the signed checksums", strings.Join(mismatched, ", "))
+		}
+		return nil
+	})
+	s.Remarks = append(s.Remarks, remarks...)
+	s.AddEvidence("matched_digests", matched)
+	if len(mismatched) > 0 {
+		s.AddEvidence("mismatched_digests", mismatched)
+	}
+}
+
+// checksumEntry is a "<hash>  <name>" line of a SHA256SUMS file.
+type checksumEntry struct {
+	Digest string
+	Name   string
+}
+
+// checksumEntries parses the entries of a SHA256SUMS file, one "<hash>  <name>" entry per line.
+func checksumEntries(sums []byte) []checksumEntry {
+	var entries []checksumEntry
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// A leading '*' marks a file hashed in binary mode
+		entries = append(entries, checksumEntry{Digest: fields[0], Name: strings.TrimPrefix(fields[1], "*")})
+	}
+	return entries
+}
+
+// checksummedFiles returns the file names listed in a SHA256SUMS file.
+func checksummedFiles(sums []byte) []string {
+	var names []string
+	for _, entry := range checksumEntries(sums) {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// isSHA256Digest reports whether digest is a hex encoded SHA-256 digest.
+func isSHA256Digest(digest string) bool {
+	if len(digest) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
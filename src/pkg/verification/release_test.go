@@ -0,0 +1,254 @@
+package verification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/github"
+)
+
+func TestVerifyReleaseSignature(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := "https://github.com/example/terraform-provider-example/releases/download/"
+	sums := []byte("abc  terraform-provider-example_1.0.0_linux_amd64.zip\ndef *terraform-provider-example_1.0.0_darwin_arm64.zip\n")
+	sign := func(signer *crypto.Key) []byte {
+		t.Helper()
+		keyRing, err := crypto.NewKeyRing(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := keyRing.SignDetached(crypto.NewPlainMessage(sums))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature.GetBinary()
+	}
+	release := func(tag string, names ...string) github.Release {
+		r := github.Release{TagName: tag}
+		for _, name := range names {
+			r.Assets = append(r.Assets, github.ReleaseAsset{Name: name, DownloadURL: base + tag + "/" + name})
+		}
+		return r
+	}
+
+	client := fakeGithubClient{
+		assets: map[string][]byte{
+			base + "v1.0.0/terraform-provider-example_1.0.0_SHA256SUMS":     sums,
+			base + "v1.0.0/terraform-provider-example_1.0.0_SHA256SUMS.sig": sign(key),
+			base + "v2.0.0/SHA256SUMS":                                      sums,
+			base + "v2.0.0/SHA256SUMS.sig":                                  sign(otherKey),
+		},
+		releases: map[string]github.Release{
+			"example/terraform-provider-example@v1.0.0": release("v1.0.0",
+				"terraform-provider-example_1.0.0_linux_amd64.zip",
+				"terraform-provider-example_1.0.0_darwin_arm64.zip",
+				"terraform-provider-example_1.0.0_SHA256SUMS",
+				"terraform-provider-example_1.0.0_SHA256SUMS.sig",
+			),
+			"example/terraform-provider-example@v2.0.0": release("v2.0.0", "SHA256SUMS", "SHA256SUMS.sig"),
+			"example/terraform-provider-example@v3.0.0": release("v3.0.0", "SHA256SUMS"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		tag      string
+		key      *crypto.Key
+		status   Status
+		errors   []string
+		remarks  []string
+		evidence map[string]any
+	}{
+		{
+			name:    "signed by the key",
+			tag:     "v1.0.0",
+			key:     key,
+			status:  StatusSuccess,
+			remarks: []string{"Verified terraform-provider-example_1.0.0_SHA256SUMS with terraform-provider-example_1.0.0_SHA256SUMS.sig"},
+			evidence: map[string]any{
+				"release":            "example/terraform-provider-example@v1.0.0",
+				"verified_assets":    []string{"terraform-provider-example_1.0.0_SHA256SUMS", "terraform-provider-example_1.0.0_SHA256SUMS.sig"},
+				"checksummed_assets": []string{"terraform-provider-example_1.0.0_linux_amd64.zip", "terraform-provider-example_1.0.0_darwin_arm64.zip"},
+				"providers":          []string{"example/example"},
+			},
+		},
+		{
+			name:   "signed by another key",
+			tag:    "v2.0.0",
+			key:    key,
+			status: StatusFailure,
+		},
+		{
+			name:    "unsigned checksums",
+			tag:     "v3.0.0",
+			key:     key,
+			status:  StatusFailure,
+			errors:  []string{"release example/terraform-provider-example@v3.0.0 has no signed SHA256SUMS file"},
+			remarks: []string{"SHA256SUMS has no signature, skipped"},
+		},
+		{
+			name:   "missing release",
+			tag:    "v4.0.0",
+			key:    key,
+			status: StatusFailure,
+			errors: []string{`no release found for tag "v4.0.0" in example/terraform-provider-example`},
+		},
+		{
+			name:    "unparsable key",
+			tag:     "v1.0.0",
+			status:  StatusSkipped,
+			remarks: []string{"the key could not be parsed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{Github: client, ReleaseRepo: "example/terraform-provider-example", ReleaseTag: tt.tag}
+			step := VerifyReleaseSignature(context.Background(), opts, tt.key)
+			assert.Equal(t, messageIDValidateRelease, step.ID)
+			assert.Len(t, step.SubSteps, 1)
+			s := step.SubSteps[0]
+			assert.Equal(t, StepIDReleaseSignature, s.ID)
+			assert.Equal(t, tt.status, s.Status)
+			if tt.errors != nil {
+				assert.Equal(t, tt.errors, s.Errors)
+			}
+			assert.Equal(t, tt.remarks, s.Remarks)
+			if tt.evidence != nil {
+				assert.Equal(t, tt.evidence, s.Evidence)
+			}
+		})
+	}
+}
+
+func TestVerifyReleaseSignature_Digests(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := "https://github.com/example/terraform-provider-example/releases/download/"
+	linux := []byte("linux artifact")
+	darwin := []byte("darwin artifact")
+	digest := func(contents []byte) string {
+		sum := sha256.Sum256(contents)
+		return hex.EncodeToString(sum[:])
+	}
+
+	client := fakeGithubClient{assets: map[string][]byte{}, releases: map[string]github.Release{}}
+	addRelease := func(tag string, sums string, artifacts map[string][]byte) {
+		r := github.Release{TagName: tag}
+		add := func(name string, contents []byte) {
+			r.Assets = append(r.Assets, github.ReleaseAsset{Name: name, DownloadURL: base + tag + "/" + name})
+			client.assets[base+tag+"/"+name] = contents
+		}
+		signature, err := keyRing.SignDetached(crypto.NewPlainMessage([]byte(sums)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"linux_amd64.zip", "darwin_arm64.zip"} {
+			if contents, ok := artifacts[name]; ok {
+				add(name, contents)
+			}
+		}
+		add("SHA256SUMS", []byte(sums))
+		add("SHA256SUMS.sig", signature.GetBinary())
+		client.releases["example/terraform-provider-example@"+tag] = r
+	}
+	addRelease("v1.0.0", digest(linux)+"  linux_amd64.zip\n"+digest(darwin)+" *darwin_arm64.zip\n",
+		map[string][]byte{"linux_amd64.zip": linux, "darwin_arm64.zip": darwin})
+	addRelease("v2.0.0", digest(linux)+"  linux_amd64.zip\n"+digest(linux)+"  darwin_arm64.zip\n",
+		map[string][]byte{"linux_amd64.zip": linux, "darwin_arm64.zip": darwin})
+	addRelease("v3.0.0", "d41d8cd98f00b204e9800998ecf8427e  linux_amd64.zip\n",
+		map[string][]byte{"linux_amd64.zip": linux})
+	addRelease("v4.0.0", digest(linux)+"  linux_amd64.zip\n"+digest(darwin)+"  darwin_arm64.zip\n",
+		map[string][]byte{"linux_amd64.zip": linux})
+
+	tests := []struct {
+		name     string
+		tag      string
+		status   Status
+		errors   []string
+		remarks  []string
+		evidence map[string]any
+	}{
+		{
+			name:   "matching digests",
+			tag:    "v1.0.0",
+			status: StatusSuccess,
+			evidence: map[string]any{
+				"matched_digests": []string{"linux_amd64.zip", "darwin_arm64.zip"},
+				"providers":       []string{"example/example"},
+			},
+		},
+		{
+			name:   "mismatched digest",
+			tag:    "v2.0.0",
+			status: StatusFailure,
+			errors: []string{"the SHA-256 digest of darwin_arm64.zip does not match the signed checksums"},
+			evidence: map[string]any{
+				"matched_digests":    []string{"linux_amd64.zip"},
+				"mismatched_digests": []string{"darwin_arm64.zip"},
+				"providers":          []string{"example/example"},
+			},
+		},
+		{
+			name:   "not a SHA-256 digest",
+			tag:    "v3.0.0",
+			status: StatusFailure,
+			errors: []string{`SHA256SUMS lists linux_amd64.zip with "d41d8cd98f00b204e9800998ecf8427e", which is not a SHA-256 digest`},
+		},
+		{
+			name:    "artifact not attached",
+			tag:     "v4.0.0",
+			status:  StatusSuccess,
+			remarks: []string{"darwin_arm64.zip is listed in SHA256SUMS but not attached to the release, skipped"},
+			evidence: map[string]any{
+				"matched_digests": []string{"linux_amd64.zip"},
+				"providers":       []string{"example/example"},
+			},
+		},
+		{
+			name:    "release not signed",
+			tag:     "v5.0.0",
+			status:  StatusSkipped,
+			remarks: []string{"the checksums are not signed by the key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{Github: client, ReleaseRepo: "example/terraform-provider-example", ReleaseTag: tt.tag, VerifyReleaseDigests: true}
+			step := VerifyReleaseSignature(context.Background(), opts, key)
+			assert.Len(t, step.SubSteps, 2)
+
+			s := step.SubSteps[1]
+			assert.Equal(t, StepIDReleaseChecksums, s.ID)
+			assert.Equal(t, tt.status, s.Status)
+			if tt.errors != nil {
+				assert.Equal(t, tt.errors, s.Errors)
+			}
+			assert.Equal(t, tt.remarks, s.Remarks)
+			if tt.evidence != nil {
+				assert.Equal(t, tt.evidence, s.Evidence)
+			}
+		})
+	}
+}
@@ -1,53 +1,314 @@
 package verification
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
 
+// RenderMarkdown renders the result as a markdown report.
 func (r *Result) RenderMarkdown() string {
-	var output string
+	var sb strings.Builder
+	_ = r.WriteMarkdownTo(&sb) // Writing to a strings.Builder does not fail
+	return sb.String()
+}
+
+// RenderMarkdownProblemsOnly renders the result as a markdown report that leaves out the steps that passed, for quick
+// triage of large reports.
+func (r *Result) RenderMarkdownProblemsOnly() string {
+	var sb strings.Builder
+	_ = r.WriteMarkdownProblemsTo(&sb) // Writing to a strings.Builder does not fail
+	return sb.String()
+}
+
+// WriteMarkdownTo writes the markdown report to w as it is rendered, rather than building it in memory first. The
+// first write error is returned and stops the output.
+func (r *Result) WriteMarkdownTo(w io.Writer) error {
+	return r.writeMarkdown(w, "")
+}
+
+// WriteMarkdownProblemsTo writes the markdown report to w like WriteMarkdownTo, but only includes the failed, warned and
+// skipped steps, preceded by a summary of how many passing steps were left out.
+func (r *Result) WriteMarkdownProblemsTo(w io.Writer) error {
+	return r.Filter((*Step).hasProblems).writeMarkdown(w, problemsSummary(r))
+}
+
+// writeMarkdown writes the markdown report, starting with the preamble if it is not empty.
+func (r *Result) writeMarkdown(w io.Writer, preamble string) error {
+	mw := &markdownWriter{w: w}
+	mw.preamble(r, preamble)
+	for _, step := range r.Steps {
+		mw.step(step)
+	}
+	return mw.err
+}
+
+// WriteMarkdownByProviderTo writes the markdown report to w grouped by provider rather than in step order. Each
+// provider associated with a step through its "providers" evidence gets a section listing those steps, followed by
+// the steps that concern the key as a whole in step order. Without any provider associations, the report is the same
+// as the one of WriteMarkdownTo.
+func (r *Result) WriteMarkdownByProviderTo(w io.Writer) error {
+	return r.writeMarkdownByProvider(w, "")
+}
+
+// WriteMarkdownProblemsByProviderTo writes the markdown report to w grouped by provider like WriteMarkdownByProviderTo,
+// but only includes the failed, warned and skipped steps like WriteMarkdownProblemsTo.
+func (r *Result) WriteMarkdownProblemsByProviderTo(w io.Writer) error {
+	return r.Filter((*Step).hasProblems).writeMarkdownByProvider(w, problemsSummary(r))
+}
+
+func (r *Result) writeMarkdownByProvider(w io.Writer, preamble string) error {
+	byProvider := make(map[string][]*Step)
+	var walk func(steps []*Step)
+	walk = func(steps []*Step) {
+		for _, step := range steps {
+			for _, provider := range step.Providers() {
+				byProvider[provider] = append(byProvider[provider], step)
+			}
+			walk(step.SubSteps)
+		}
+	}
+	walk(r.Steps)
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	mw := &markdownWriter{w: w}
+	mw.preamble(r, preamble)
+	for _, provider := range providers {
+		mw.printf("## Provider %s\n", provider)
+		for _, step := range byProvider[provider] {
+			mw.subStep(step)
+		}
+		mw.printf("\n")
+	}
+
+	// The steps of the key as a whole, leaving out the top level steps all of whose sub steps are grouped by provider
+	ungrouped := func(step *Step) bool { return len(step.Providers()) == 0 }
 	for _, step := range r.Steps {
-		output += fmt.Sprintf("## %s\n", step.Name)
-		for _, remark := range step.Remarks {
-			output += fmt.Sprintf("> [!NOTE]\n")
-			output += fmt.Sprintf("> %s\n\n", remark)
+		if !ungrouped(step) {
+			continue
+		}
+		rest := *step
+		rest.SubSteps = filterSteps(step.SubSteps, ungrouped)
+		if len(step.SubSteps) > 0 && len(rest.SubSteps) == 0 {
+			continue
+		}
+		mw.step(&rest)
+	}
+	return mw.err
+}
+
+// WriteJSONTo writes the result to w as indented JSON.
+func (r *Result) WriteJSONTo(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// markdownWriter writes formatted output until the first error, which is kept in err.
+type markdownWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (mw *markdownWriter) printf(format string, args ...any) {
+	if mw.err != nil {
+		return
+	}
+	_, mw.err = fmt.Fprintf(mw.w, format, args...)
+}
+
+// preamble warns that the result is partial if the verification was cancelled, followed by the preamble if it is not
+// empty.
+func (mw *markdownWriter) preamble(r *Result, preamble string) {
+	if r.Cancelled {
+		mw.printf("> [!WARNING]\n")
+		mw.printf("> Verification was cancelled before all steps completed, this result is partial.\n\n")
+	}
+	if preamble != "" {
+		mw.printf("%s\n\n", preamble)
+	}
+}
+
+// step writes a top level step with its sub steps.
+func (mw *markdownWriter) step(step *Step) {
+	mw.printf("## %s\n", step.Name)
+	for _, remark := range step.Remarks {
+		mw.printf("> [!NOTE]\n")
+		mw.printf("> %s\n\n", remark)
+	}
+	mw.status(step.Status)
+
+	for _, err := range step.Errors {
+		mw.printf("- %s\n", err)
+	}
+	mw.remediations(step)
+	mw.evidence(step)
+	for _, subStep := range step.SubSteps {
+		mw.subStep(subStep)
+	}
+	mw.printf("\n")
+}
+
+// subStep writes a sub step.
+func (mw *markdownWriter) subStep(step *Step) {
+	mw.printf("### %s\n", step.Name)
+	for _, remark := range step.Remarks {
+		mw.printf("> [!NOTE]\n")
+		mw.printf("> %s\n\n", remark)
+	}
+	mw.status(step.Status)
+
+	for _, err := range step.Errors {
+		mw.printf("- %s\n", err)
+	}
+	mw.docs(step)
+	mw.remediations(step)
+	mw.evidence(step)
+}
+
+func (mw *markdownWriter) status(status Status) {
+	if status == StatusSuccess {
+		mw.printf("✅ **Success**\n")
+	} else if status == StatusFailure {
+		mw.printf("❌ **Failure**\n")
+	} else if status == StatusNotRun {
+		mw.printf("⚠️ **Not Run**\n")
+	} else if status == StatusSkipped {
+		mw.printf("⚠️ **Skipped**\n")
+	} else if status == StatusWarning {
+		mw.printf("⚠️ **Warning**\n")
+	} else if status == StatusError {
+		mw.printf("🚫 **Error**\n")
+	}
+}
+
+// problemsSummary states how many steps passed and are left out of a report that only shows the problems.
+func problemsSummary(r *Result) string {
+	passed, problems := countProblems(r.Steps)
+	if problems == 0 {
+		return fmt.Sprintf("All %d step(s) passed.", passed)
+	}
+	return fmt.Sprintf("%d step(s) need attention, %d passing step(s) are not shown.", problems, passed)
+}
+
+// countProblems counts the steps without sub steps, as those are the checks that were actually run, by whether they
+// passed.
+func countProblems(steps []*Step) (passed int, problems int) {
+	for _, step := range steps {
+		if len(step.SubSteps) > 0 {
+			p, n := countProblems(step.SubSteps)
+			passed, problems = passed+p, problems+n
+			continue
 		}
 		if step.Status == StatusSuccess {
-			output += "✅ **Success**\n"
-		} else if step.Status == StatusFailure {
-			output += "❌ **Failure**\n"
-		} else if step.Status == StatusNotRun {
-			output += "⚠️ **Not Run**\n"
-		} else if step.Status == StatusSkipped {
-			output += "⚠️ **Skipped**\n"
-		} else if step.Status == StatusWarning {
-			output += "⚠️ **Warning**\n"
+			passed++
+		} else {
+			problems++
 		}
+	}
+	return passed, problems
+}
 
-		for _, err := range step.Errors {
-			output += fmt.Sprintf("- %s\n", err)
+// docs links the documentation of the step if it failed or warned, so that contributors know how to fix their key.
+func (mw *markdownWriter) docs(step *Step) {
+	if step.DocsURL == "" || (step.Status != StatusFailure && step.Status != StatusWarning) {
+		return
+	}
+	mw.printf("\nFor more information, see %s\n", step.DocsURL)
+}
+
+// remediations writes how to fix the step as tips, with a link to the documentation if there is one.
+func (mw *markdownWriter) remediations(step *Step) {
+	for _, remediation := range step.Remediations {
+		mw.printf("\n> [!TIP]\n")
+		mw.printf("> **%s**\n", remediation.Title)
+		mw.printf("> %s\n", remediation.Description)
+		if remediation.URL != "" {
+			mw.printf(">\n> For more information, see %s\n", remediation.URL)
 		}
-		for _, subStep := range step.SubSteps {
-			output += fmt.Sprintf("### %s\n", subStep.Name)
-			for _, remark := range subStep.Remarks {
-				output += fmt.Sprintf("> [!NOTE]\n")
-				output += fmt.Sprintf("> %s\n\n", remark)
-			}
-			if subStep.Status == StatusSuccess {
-				output += "✅ **Success**\n"
-			} else if subStep.Status == StatusFailure {
-				output += "❌ **Failure**\n"
-			} else if subStep.Status == StatusNotRun {
-				output += "⚠️ **Not Run**\n"
-			} else if subStep.Status == StatusSkipped {
-				output += "⚠️ **Skipped**\n"
-			} else if subStep.Status == StatusWarning {
-				output += "⚠️ **Warning**\n"
-			}
+	}
+}
 
-			for _, err := range subStep.Errors {
-				output += fmt.Sprintf("- %s\n", err)
-			}
+// evidence writes the evidence of the step as a list, sorted by key.
+func (mw *markdownWriter) evidence(step *Step) {
+	if len(step.Evidence) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(step.Evidence))
+	for key := range step.Evidence {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mw.printf("\n**Evidence**\n")
+	for _, key := range keys {
+		mw.printf("- %s: `%v`\n", key, step.Evidence[key])
+	}
+}
+
+// RenderProgress renders a single completed sub step as plain text, for streaming to a terminal while the
+// verification is still running.
+func RenderProgress(parent *Step, step *Step) string {
+	output := fmt.Sprintf("[%s] %s: %s\n", step.Status, parent.Name, step.Name)
+	for _, remark := range step.Remarks {
+		output += fmt.Sprintf("    %s\n", remark)
+	}
+	for _, err := range step.Errors {
+		output += fmt.Sprintf("    - %s\n", err)
+	}
+	for _, remediation := range step.Remediations {
+		output += fmt.Sprintf("    How to fix: %s. %s\n", remediation.Title, remediation.Description)
+	}
+	return output
+}
+
+// RenderSummary renders the overall outcome of each top level step, as a plain text conclusion to the streamed
+// progress.
+func (r *Result) RenderSummary() string {
+	return r.renderSummary(false)
+}
+
+// RenderProblemsSummary renders the summary like RenderSummary, but leaves out the top level steps that passed and
+// states how many passing steps were left out.
+func (r *Result) RenderProblemsSummary() string {
+	return r.renderSummary(true)
+}
+
+func (r *Result) renderSummary(problemsOnly bool) string {
+	steps := r.Steps
+	if problemsOnly {
+		steps = r.Filter((*Step).hasProblems).Steps
+	}
+
+	var output string
+	for _, step := range steps {
+		status := StatusSuccess
+		if step.hasStatus(StatusFailure) {
+			status = StatusFailure
+		} else if step.hasStatus(StatusError) {
+			status = StatusError
+		} else if step.DidWarn() {
+			status = StatusWarning
+		} else if problemsOnly {
+			status = StatusSkipped
+		}
+		output += fmt.Sprintf("[%s] %s\n", status, step.Name)
+		for _, err := range step.Errors {
+			output += fmt.Sprintf("    - %s\n", err)
 		}
-		output += "\n"
+	}
+	if problemsOnly {
+		passed, problems := countProblems(r.Steps)
+		output += fmt.Sprintf("%d step(s) need attention, %d passing step(s) are not shown\n", problems, passed)
+	}
+	if r.Cancelled {
+		output += "Verification was cancelled before all steps completed, this result is partial.\n"
 	}
 	return output
 }
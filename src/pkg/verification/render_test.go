@@ -1,6 +1,9 @@
 package verification
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +30,185 @@ func TestRender_Remarks(t *testing.T) {
 	rendered := result.RenderMarkdown()
 	assert.Equal(t, "## Step 1\n> [!NOTE]\n> Remark 1\n\n> [!NOTE]\n> Remark 2\n\n✅ **Success**\n\n", rendered)
 }
+
+func TestRender_Evidence(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Step 1", StatusSuccess)
+	sub := s.AddStep("Sub Step 1", StatusFailure, "Error 1")
+	sub.AddEvidence("version", "v1.0.0")
+	sub.AddEvidence("fingerprint", "ABCDEF")
+	sub.AddEvidence("orgs", []string{"a", "b"})
+
+	rendered := result.RenderMarkdown()
+	assert.Equal(t, "## Step 1\n✅ **Success**\n### Sub Step 1\n❌ **Failure**\n- Error 1\n\n**Evidence**\n- fingerprint: `ABCDEF`\n- orgs: `[a b]`\n- version: `v1.0.0`\n\n", rendered)
+}
+
+func TestRender_Cancelled(t *testing.T) {
+	result := Result{Cancelled: true}
+	result.AddStep("Step 1", StatusSuccess)
+
+	rendered := result.RenderMarkdown()
+	assert.Equal(t, "> [!WARNING]\n> Verification was cancelled before all steps completed, this result is partial.\n\n## Step 1\n✅ **Success**\n\n", rendered)
+}
+
+func TestRender_Error(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Step 1", StatusSuccess)
+	s.RunStep("Sub Step 1", func() error {
+		return inconclusive(errors.New("GitHub is unreachable: connection refused"))
+	})
+	s.AddStep("Sub Step 2", StatusWarning)
+
+	assert.True(t, result.DidFail())
+	assert.Equal(t, "## Step 1\n✅ **Success**\n### Sub Step 1\n🚫 **Error**\n- GitHub is unreachable: connection refused\n### Sub Step 2\n⚠️ **Warning**\n\n", result.RenderMarkdown())
+	assert.Equal(t, "[error] Step 1\n", result.RenderSummary())
+}
+
+func TestRenderProgress(t *testing.T) {
+	parent := &Step{Name: "Step 1"}
+	s := parent.AddStep("Sub Step 1", StatusFailure, "Error 1")
+	s.Remarks = append(s.Remarks, "Remark 1")
+
+	assert.Equal(t, "[failure] Step 1: Sub Step 1\n    Remark 1\n    - Error 1\n", RenderProgress(parent, s))
+}
+
+func TestRenderSummary(t *testing.T) {
+	result := Result{Cancelled: true}
+	result.AddStep("Step 1", StatusSuccess).AddStep("Sub Step 1", StatusWarning)
+	result.AddStep("Step 2", StatusFailure, "Error 1")
+
+	rendered := result.RenderSummary()
+	assert.Equal(t, "[warning] Step 1\n[failure] Step 2\n    - Error 1\nVerification was cancelled before all steps completed, this result is partial.\n", rendered)
+}
+
+func TestRender_ProblemsOnly(t *testing.T) {
+	result := Result{}
+	result.AddStep("Step 1", StatusSuccess).AddStep("Sub Step 1", StatusSuccess)
+	s := result.AddStep("Step 2", StatusSuccess)
+	s.AddStep("Sub Step 2", StatusSuccess)
+	s.AddStep("Sub Step 3", StatusWarning, "Warning 1")
+	s.AddStep("Sub Step 4", StatusSkipped)
+	result.AddStep("Step 3", StatusFailure, "Error 1")
+
+	rendered := result.RenderMarkdownProblemsOnly()
+	assert.Equal(t, "3 step(s) need attention, 2 passing step(s) are not shown.\n\n## Step 2\n✅ **Success**\n### Sub Step 3\n⚠️ **Warning**\n- Warning 1\n### Sub Step 4\n⚠️ **Skipped**\n\n## Step 3\n❌ **Failure**\n- Error 1\n\n", rendered)
+}
+
+func TestRender_ProblemsOnlyAllPassed(t *testing.T) {
+	result := Result{}
+	result.AddStep("Step 1", StatusSuccess).AddStep("Sub Step 1", StatusSuccess)
+
+	rendered := result.RenderMarkdownProblemsOnly()
+	assert.Equal(t, "All 1 step(s) passed.\n\n", rendered)
+}
+
+func TestRenderProblemsSummary(t *testing.T) {
+	result := Result{}
+	result.AddStep("Step 1", StatusSuccess).AddStep("Sub Step 1", StatusSuccess)
+	result.AddStep("Step 2", StatusSuccess).AddStep("Sub Step 2", StatusSkipped)
+	result.AddStep("Step 3", StatusFailure, "Error 1")
+
+	rendered := result.RenderProblemsSummary()
+	assert.Equal(t, "[skipped] Step 2\n[failure] Step 3\n    - Error 1\n2 step(s) need attention, 1 passing step(s) are not shown\n", rendered)
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestWriteMarkdownTo(t *testing.T) {
+	result := Result{}
+	result.AddStep("Step 1", StatusSuccess).AddStep("Sub Step 1", StatusFailure, "Error 1")
+
+	var buf bytes.Buffer
+	assert.NoError(t, result.WriteMarkdownTo(&buf))
+	assert.Equal(t, result.RenderMarkdown(), buf.String())
+
+	// Writing stops at the first error
+	w := &failingWriter{}
+	assert.EqualError(t, result.WriteMarkdownTo(w), "disk full")
+	assert.Equal(t, 1, w.writes)
+}
+
+func TestWriteJSONTo(t *testing.T) {
+	result := Result{Status: OutcomeFail}
+	result.AddStep("Step 1", StatusFailure, "Error 1")
+
+	var buf bytes.Buffer
+	assert.NoError(t, result.WriteJSONTo(&buf))
+	expected, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(expected)+"\n", buf.String())
+}
+
+func TestRender_Docs(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Step 1", StatusSuccess)
+	failed := s.AddStep("Sub Step 1", StatusFailure, "Error 1")
+	failed.DocsURL = "https://example.com/fix"
+	passed := s.AddStep("Sub Step 2", StatusSuccess)
+	passed.DocsURL = "https://example.com/fix"
+
+	rendered := result.RenderMarkdown()
+	assert.Equal(t, "## Step 1\n✅ **Success**\n### Sub Step 1\n❌ **Failure**\n- Error 1\n\nFor more information, see https://example.com/fix\n### Sub Step 2\n✅ **Success**\n\n", rendered)
+}
+
+func TestRender_Remediations(t *testing.T) {
+	result := Result{}
+	s := result.AddStep("Step 1", StatusFailure)
+	failed := s.AddStep("Sub Step 1", StatusFailure, "Error 1")
+	failed.AddRemediation(Remediation{Title: "Fix it", Description: "Do this and that.", URL: "https://example.com/fix"})
+	failed.AddRemediation(Remediation{Title: "Or this", Description: "Do something else."})
+
+	rendered := result.RenderMarkdown()
+	assert.Equal(t, "## Step 1\n❌ **Failure**\n### Sub Step 1\n❌ **Failure**\n- Error 1\n"+
+		"\n> [!TIP]\n> **Fix it**\n> Do this and that.\n>\n> For more information, see https://example.com/fix\n"+
+		"\n> [!TIP]\n> **Or this**\n> Do something else.\n\n", rendered)
+
+	assert.Equal(t, "[failure] Step 1: Sub Step 1\n    - Error 1\n    How to fix: Fix it. Do this and that.\n    How to fix: Or this. Do something else.\n", RenderProgress(s, failed))
+
+	var buf bytes.Buffer
+	assert.NoError(t, result.WriteJSONTo(&buf))
+	assert.Contains(t, buf.String(), `"remediations": [
+            {
+              "title": "Fix it",
+              "description": "Do this and that.",
+              "url": "https://example.com/fix"
+            },`)
+}
+
+func TestWriteMarkdownByProviderTo(t *testing.T) {
+	result := Result{}
+	result.AddStep("Validate key", StatusSuccess).AddStep("Key is valid", StatusSuccess)
+	result.AddStep("Validate providers", StatusSuccess).AddStep("Signed a provider", StatusSuccess).AddProviders("b/bar", "a/foo")
+	result.AddStep("Validate release", StatusFailure).AddStep("Signed the release", StatusFailure, "Error 1").AddProviders("a/foo")
+
+	var buf bytes.Buffer
+	assert.NoError(t, result.WriteMarkdownByProviderTo(&buf))
+	assert.Equal(t, "## Provider a/foo\n"+
+		"### Signed a provider\n✅ **Success**\n\n**Evidence**\n- providers: `[a/foo b/bar]`\n"+
+		"### Signed the release\n❌ **Failure**\n- Error 1\n\n**Evidence**\n- providers: `[a/foo]`\n\n"+
+		"## Provider b/bar\n"+
+		"### Signed a provider\n✅ **Success**\n\n**Evidence**\n- providers: `[a/foo b/bar]`\n\n"+
+		"## Validate key\n✅ **Success**\n### Key is valid\n✅ **Success**\n\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, result.WriteMarkdownProblemsByProviderTo(&buf))
+	assert.Equal(t, "1 step(s) need attention, 2 passing step(s) are not shown.\n\n"+
+		"## Provider a/foo\n"+
+		"### Signed the release\n❌ **Failure**\n- Error 1\n\n**Evidence**\n- providers: `[a/foo]`\n\n", buf.String())
+
+	// Without provider associations the report stays in step order
+	plain := Result{}
+	plain.AddStep("Validate key", StatusSuccess).AddStep("Key is valid", StatusSuccess)
+	buf.Reset()
+	assert.NoError(t, plain.WriteMarkdownByProviderTo(&buf))
+	assert.Equal(t, plain.RenderMarkdown(), buf.String())
+}
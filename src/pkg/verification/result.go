@@ -1,5 +1,7 @@
 package verification
 
+import "fmt"
+
 type Status string
 
 const (
@@ -8,10 +10,32 @@ const (
 	StatusNotRun  Status = "not_run"
 	StatusSkipped Status = "skipped"
 	StatusWarning Status = "warning"
+	StatusError   Status = "error" // The step could not reach a verdict, for example because GitHub was unreachable
+)
+
+// Outcome is the overall outcome of a verification.
+type Outcome string
+
+const (
+	OutcomePass Outcome = "pass" // All steps succeeded or were skipped
+	OutcomeFail Outcome = "fail" // At least one step failed or ended in an error
+	OutcomeWarn Outcome = "warn" // At least one step produced a warning, none failed
 )
 
 type Result struct {
-	Steps []*Step `json:"steps"`
+	Status     Outcome `json:"status,omitempty"` // Overall outcome, set by ComputeStatus once all steps have run
+	Steps      []*Step `json:"steps"`
+	Cancelled  bool    `json:"cancelled,omitempty"`   // Set when the verification was interrupted before all steps completed
+	ArmoredKey string  `json:"armored_key,omitempty"` // The verified public key, re-armored, if requested
+
+	Metadata *Metadata `json:"metadata,omitempty"` // Describes the run that produced the result, set by the caller
+}
+
+// Metadata describes the run that produced a Result.
+type Metadata struct {
+	APIRequests int64  `json:"api_requests"`           // Number of requests sent to GitHub, including retries and asset downloads
+	PullRequest int    `json:"pull_request,omitempty"` // Number of the pull request or issue the key was submitted in, if known
+	RegistryRef string `json:"registry_ref,omitempty"` // Commit of the registry repository the provider and key data was read from, if pinned
 }
 
 func (r *Result) AddStep(name string, status Status, errors ...string) *Step {
@@ -24,11 +48,78 @@ func (r *Result) AddStep(name string, status Status, errors ...string) *Step {
 	return &step
 }
 
-func (r *Result) DidFail() bool {
+// ComputeStatus derives the overall outcome from the steps and stores it in Status.
+func (r *Result) ComputeStatus() Outcome {
+	r.Status = OutcomePass
 	for _, step := range r.Steps {
 		if step.DidFail() {
+			r.Status = OutcomeFail
+			break
+		}
+		if step.DidWarn() {
+			r.Status = OutcomeWarn
+		}
+	}
+	return r.Status
+}
+
+// Outcome returns the overall outcome, computing it from the steps if ComputeStatus has not been called yet.
+func (r *Result) Outcome() Outcome {
+	if r.Status != "" {
+		return r.Status
+	}
+	return (&Result{Steps: r.Steps}).ComputeStatus()
+}
+
+func (r *Result) DidFail() bool {
+	return r.Outcome() == OutcomeFail
+}
+
+func (r *Result) DidWarn() bool {
+	for _, step := range r.Steps {
+		if step.DidWarn() {
 			return true
 		}
 	}
 	return false
 }
+
+// Filter returns a copy of the result with only the steps for which keep returns true. Sub steps are filtered the same
+// way, a step that is left out is left out with all of its sub steps. The kept steps are shallow copies, so that the
+// original result is not changed, and the overall outcome is computed for the filtered steps.
+func (r *Result) Filter(keep func(*Step) bool) *Result {
+	filtered := *r
+	filtered.Steps = filterSteps(r.Steps, keep)
+	filtered.ComputeStatus()
+	return &filtered
+}
+
+func filterSteps(steps []*Step, keep func(*Step) bool) []*Step {
+	var kept []*Step
+	for _, step := range steps {
+		if !keep(step) {
+			continue
+		}
+		s := *step
+		s.SubSteps = filterSteps(step.SubSteps, keep)
+		kept = append(kept, &s)
+	}
+	return kept
+}
+
+// CombineResults merges the results of verifying several keys into a single report. The top level steps of each result
+// are prefixed with its label, typically the key file, to tell them apart. The given results are left untouched, the
+// prefixed steps are copies that share their sub steps with the originals.
+func CombineResults(labels []string, results []*Result) *Result {
+	combined := &Result{}
+	for i, result := range results {
+		for _, step := range result.Steps {
+			labelled := *step
+			labelled.Name = fmt.Sprintf("%s: %s", labels[i], step.Name)
+			combined.Steps = append(combined.Steps, &labelled)
+		}
+		combined.Cancelled = combined.Cancelled || result.Cancelled
+	}
+	combined.ComputeStatus()
+	return combined
+}
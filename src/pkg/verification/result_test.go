@@ -1,6 +1,7 @@
 package verification
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +30,98 @@ func TestDidFail_SubStepFailed(t *testing.T) {
 
 	assert.True(t, result.DidFail())
 }
+
+func TestComputeStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []Status
+		expected Outcome
+	}{
+		{name: "no steps", expected: OutcomePass},
+		{name: "success and skipped", statuses: []Status{StatusSuccess, StatusSkipped}, expected: OutcomePass},
+		{name: "warning", statuses: []Status{StatusSuccess, StatusWarning}, expected: OutcomeWarn},
+		{name: "warning and failure", statuses: []Status{StatusWarning, StatusFailure, StatusWarning}, expected: OutcomeFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Result{}
+			s := result.AddStep("Step 1", StatusSuccess)
+			for _, status := range tt.statuses {
+				s.AddStep("Sub Step", status)
+			}
+
+			assert.Equal(t, tt.expected, result.ComputeStatus())
+			assert.Equal(t, tt.expected, result.Status)
+			assert.Equal(t, tt.expected == OutcomeFail, result.DidFail())
+		})
+	}
+}
+
+func TestDidFail_UsesStatus(t *testing.T) {
+	result := Result{Status: OutcomeFail}
+	result.AddStep("Step 1", StatusSuccess)
+
+	assert.True(t, result.DidFail())
+}
+
+func TestMetadata_JSON(t *testing.T) {
+	data, err := json.Marshal(Metadata{APIRequests: 3})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"api_requests": 3}`, string(data))
+
+	data, err = json.Marshal(Metadata{APIRequests: 3, PullRequest: 1234})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"api_requests": 3, "pull_request": 1234}`, string(data))
+}
+
+func TestCombineResults(t *testing.T) {
+	first := &Result{}
+	first.AddStep("Validate GPG key", StatusSuccess)
+	second := &Result{Cancelled: true}
+	second.AddStep("Validate GPG key", StatusWarning)
+
+	combined := CombineResults([]string{"a.asc", "b.asc"}, []*Result{first, second})
+	assert.Equal(t, []string{"a.asc: Validate GPG key", "b.asc: Validate GPG key"}, []string{combined.Steps[0].Name, combined.Steps[1].Name})
+	assert.Equal(t, OutcomeWarn, combined.Status)
+	assert.True(t, combined.Cancelled)
+	// The results of the individual keys keep their step names
+	assert.Equal(t, "Validate GPG key", first.Steps[0].Name)
+	assert.Equal(t, "Validate GPG key", second.Steps[0].Name)
+
+	assert.Equal(t, OutcomePass, CombineResults(nil, nil).Status)
+}
+
+func TestResult_Filter(t *testing.T) {
+	result := &Result{Cancelled: true, Metadata: &Metadata{APIRequests: 3}}
+	key := result.AddStep("Validate GPG key", StatusSuccess)
+	key.AddStep("Key is not expired", StatusSuccess)
+	key.AddStep("Key has a separate subkey", StatusWarning)
+	github := result.AddStep("Validate Github user", StatusSuccess)
+	github.AddStep("User is a member of the organization", StatusFailure)
+	result.ComputeStatus()
+	assert.Equal(t, OutcomeFail, result.Status)
+
+	noFailures := result.Filter(func(s *Step) bool { return !s.hasStatus(StatusFailure) })
+	assert.Equal(t, OutcomeWarn, noFailures.Status)
+	assert.True(t, noFailures.Cancelled)
+	assert.Equal(t, result.Metadata, noFailures.Metadata)
+	assert.Len(t, noFailures.Steps, 1)
+	assert.Equal(t, "Validate GPG key", noFailures.Steps[0].Name)
+	assert.Len(t, noFailures.Steps[0].SubSteps, 2)
+
+	problems := result.Filter((*Step).hasProblems)
+	assert.Equal(t, OutcomeFail, problems.Status)
+	assert.Len(t, problems.Steps, 2)
+	assert.Len(t, problems.Steps[0].SubSteps, 1)
+	assert.Equal(t, "Key has a separate subkey", problems.Steps[0].SubSteps[0].Name)
+
+	// The original result is left untouched
+	assert.Equal(t, OutcomeFail, result.Status)
+	assert.Len(t, result.Steps, 2)
+	assert.Len(t, result.Steps[0].SubSteps, 2)
+
+	none := result.Filter(func(*Step) bool { return false })
+	assert.Empty(t, none.Steps)
+	assert.Equal(t, OutcomePass, none.Status)
+}
@@ -0,0 +1,75 @@
+package verification
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+// VerifyRotation checks that newKey is a rotation of oldKey: either the old key certified the user IDs of the new key,
+// or it was revoked as superseded with a reason that names the new key. A superseded revocation that does not name the
+// new key is ambiguous and only produces a warning. The time of the revocation check and the step names are taken from
+// opts.
+func VerifyRotation(opts VerifyKeyOptions, oldKey *crypto.Key, newKey *crypto.Key) *Step {
+	verifyStep := &Step{
+		ID:       messageIDValidateRotation,
+		Name:     opts.Catalog.StepName(messageIDValidateRotation, "Validate key rotation"),
+		progress: opts.Progress,
+	}
+	defer verifyStep.reportProgress()
+
+	var remarks []string
+	evidence := make(map[string]any)
+	ambiguous := false
+	s := verifyStep.RunStep(opts.Catalog.StepName(messageIDRotation, "New key replaces the old key"), func() error {
+		if strings.EqualFold(oldKey.GetFingerprint(), newKey.GetFingerprint()) {
+			return fmt.Errorf("the old and the new key are the same key")
+		}
+
+		certified := gpg.CertifiedBy(newKey, oldKey)
+		for _, userID := range certified {
+			remarks = append(remarks, fmt.Sprintf("The old key certified the user ID %s of the new key", userID))
+		}
+		evidence["certified_user_ids"] = certified
+
+		superseded, namesNewKey := false, false
+		for _, r := range gpg.Revocations(oldKey, opts.now()) {
+			if r.UserID != "" || !r.Superseded() {
+				continue
+			}
+			superseded = true
+			remarks = append(remarks, fmt.Sprintf("The old key was revoked: %s", r))
+			namesNewKey = namesNewKey || mentionsKey(r.Text, newKey)
+		}
+		evidence["superseded"] = superseded
+		evidence["revocation_names_new_key"] = namesNewKey
+
+		switch {
+		case len(certified) > 0 || namesNewKey:
+			return nil
+		case superseded:
+			ambiguous = true
+			return fmt.Errorf("the old key was revoked as superseded, but neither the revocation nor a certification names the new key")
+		default:
+			return fmt.Errorf("no rotation relationship found, the old key neither certified the new key nor was revoked as superseded")
+		}
+	}).withID(messageIDRotation)
+	s.Remarks = append(s.Remarks, remarks...)
+	s.addEvidence(evidence)
+	if ambiguous {
+		s.FailureToWarning()
+	}
+
+	return verifyStep
+}
+
+// mentionsKey returns true if the text contains the fingerprint or the long key ID of the key, in any case and with or
+// without the spaces gpg groups fingerprints with.
+func mentionsKey(text string, key *crypto.Key) bool {
+	normalized := strings.ToUpper(strings.ReplaceAll(text, " ", ""))
+	fingerprint := strings.ToUpper(key.GetFingerprint())
+	return strings.Contains(normalized, fingerprint) || strings.Contains(normalized, fingerprint[len(fingerprint)-16:])
+}
@@ -0,0 +1,121 @@
+package verification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/gpg"
+)
+
+func TestVerifyRotation(t *testing.T) {
+	generate := func() *crypto.Key {
+		t.Helper()
+		key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	// reparse round trips the public part of the key, as the verification would read it from a file
+	reparse := func(key *crypto.Key) *crypto.Key {
+		t.Helper()
+		armored, err := key.GetArmoredPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := gpg.ParseKey(armored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	oldKey, newKey := generate(), generate()
+	unrelated := reparse(generate())
+
+	certified := generate()
+	if err := certified.GetEntity().SignIdentity("Test User <test@example.com>", oldKey.GetEntity(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	supersededNamed := generate()
+	if err := supersededNamed.GetEntity().RevokeKey(packet.KeySuperseded, "Replaced by "+strings.ToUpper(newKey.GetFingerprint()), nil); err != nil {
+		t.Fatal(err)
+	}
+	supersededUnnamed := generate()
+	if err := supersededUnnamed.GetEntity().RevokeKey(packet.KeySuperseded, "Rotated", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		oldKey   *crypto.Key
+		newKey   *crypto.Key
+		status   Status
+		errors   []string
+		evidence map[string]any
+	}{
+		{
+			name:   "certified by the old key",
+			oldKey: oldKey,
+			newKey: reparse(certified),
+			status: StatusSuccess,
+			evidence: map[string]any{
+				"certified_user_ids":       []string{"Test User <test@example.com>"},
+				"superseded":               false,
+				"revocation_names_new_key": false,
+			},
+		},
+		{
+			name:   "superseded by the new key",
+			oldKey: reparse(supersededNamed),
+			newKey: reparse(newKey),
+			status: StatusSuccess,
+			evidence: map[string]any{
+				"certified_user_ids":       []string(nil),
+				"superseded":               true,
+				"revocation_names_new_key": true,
+			},
+		},
+		{
+			name:   "superseded without naming the new key",
+			oldKey: reparse(supersededUnnamed),
+			newKey: reparse(newKey),
+			status: StatusWarning,
+			errors: []string{"the old key was revoked as superseded, but neither the revocation nor a certification names the new key"},
+		},
+		{
+			name:   "unrelated keys",
+			oldKey: reparse(oldKey),
+			newKey: unrelated,
+			status: StatusFailure,
+			errors: []string{"no rotation relationship found, the old key neither certified the new key nor was revoked as superseded"},
+		},
+		{
+			name:   "same key",
+			oldKey: unrelated,
+			newKey: unrelated,
+			status: StatusFailure,
+			errors: []string{"the old and the new key are the same key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := VerifyRotation(VerifyKeyOptions{}, tt.oldKey, tt.newKey)
+			assert.Equal(t, messageIDValidateRotation, step.ID)
+			assert.Len(t, step.SubSteps, 1)
+			rotation := step.SubSteps[0]
+			assert.Equal(t, messageIDRotation, rotation.ID)
+			assert.Equal(t, tt.status, rotation.Status)
+			assert.Equal(t, tt.errors, rotation.Errors)
+			if tt.evidence != nil {
+				assert.Equal(t, tt.evidence, rotation.Evidence)
+			}
+		})
+	}
+}
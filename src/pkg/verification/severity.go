@@ -0,0 +1,56 @@
+package verification
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SeverityOverrides changes how a failing check of a step is reported, keyed by step identifier. StatusFailure and
+// StatusWarning replace the default severity of the step's definition, as well as any option that escalates it, such
+// as Strict. StatusSkipped keeps the step from running at all.
+type SeverityOverrides map[string]Status
+
+// ParseSeverityOverrides parses a comma separated list of step-id=warning|failure|skip overrides.
+func ParseSeverityOverrides(list string) (SeverityOverrides, error) {
+	overrides := make(SeverityOverrides)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, severity, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid severity override %q, expected step-id=warning|failure|skip", item)
+		}
+		id = strings.TrimSpace(id)
+		if !isKnownStepID(id) {
+			return nil, fmt.Errorf("unknown step %q, expected one of %s", id, strings.Join(stepIDs, ", "))
+		}
+		if _, ok := overrides[id]; ok {
+			return nil, fmt.Errorf("step %q has more than one severity override", id)
+		}
+		switch strings.TrimSpace(severity) {
+		case "warning":
+			overrides[id] = StatusWarning
+		case "failure":
+			overrides[id] = StatusFailure
+		case "skip":
+			overrides[id] = StatusSkipped
+		default:
+			return nil, fmt.Errorf("invalid severity %q for step %q, expected warning, failure or skip", severity, id)
+		}
+	}
+	return overrides, nil
+}
+
+// severity returns how a failing check of the step is reported: the override if there is one, StatusFailure if the
+// step is escalated and the default severity of its definition otherwise.
+func (o SeverityOverrides) severity(definition StepDefinition, escalate bool) Status {
+	if override, ok := o[definition.ID]; ok {
+		return override
+	}
+	if escalate {
+		return StatusFailure
+	}
+	return definition.Severity
+}
@@ -1,55 +1,236 @@
 package verification
 
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+)
+
 type Step struct {
+	ID      string   `json:"id,omitempty"` // Stable identifier, independent of the possibly translated name
 	Name    string   `json:"name"`
 	Status  Status   `json:"status"`
 	Errors  []string `json:"errors"`
 	Remarks []string `json:"remarks"`
 
+	// Evidence holds structured data the step based its outcome on, such as the matched fingerprint or release. Unlike
+	// Remarks it is meant to be consumed by tools rather than read.
+	Evidence map[string]any `json:"evidence,omitempty"`
+
+	DocsURL   string `json:"docs_url,omitempty"`   // Explains how to fix a failing step, from its definition
+	ErrorCode string `json:"error_code,omitempty"` // Stable code of the failure, from the error or the step definition
+
+	// Remediations explain how to fix the problem the step found. Remarks are kept for incidental notes.
+	Remediations []Remediation `json:"remediations,omitempty"`
+
 	SubSteps []*Step `json:"sub_steps"`
+
+	progress ProgressFunc // Called for every sub step once it is complete
+	pending  *Step        // The most recent sub step, not yet passed to progress
 }
 
+// Remediation is a structured explanation of how to fix a failed or warning step, so that tools can show it as a "how
+// to fix" card rather than as a free-text note.
+type Remediation struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"` // Documentation with more details, optional
+}
+
+// ProgressFunc is called with each sub step once it has completed, while the verification is still running.
+type ProgressFunc func(parent *Step, step *Step)
+
+// AddStep adds a sub step. The previously added sub step is considered complete at this point and is reported as
+// progress, so that remarks or status changes applied after RunStep returns are included.
 func (s *Step) AddStep(name string, status Status, errors ...string) *Step {
+	s.reportProgress()
 	step := Step{
-		Name:   name,
-		Status: status,
-		Errors: errors,
+		Name:     name,
+		Status:   status,
+		Errors:   errors,
+		progress: s.progress,
 	}
 	s.SubSteps = append(s.SubSteps, &step)
+	s.pending = &step
 	return &step
 }
 
+// reportProgress passes the pending sub step, if any, to the progress function.
+func (s *Step) reportProgress() {
+	if s.pending != nil && s.progress != nil {
+		s.progress(s, s.pending)
+	}
+	s.pending = nil
+}
+
 func (s *Step) FailureToWarning() {
 	if s.Status == StatusFailure {
 		s.Status = StatusWarning
 	}
 }
 
+// RunStep runs fn as a sub step. The step fails if fn returns an error, or ends in an error if the error was marked
+// with inconclusive.
 func (s *Step) RunStep(name string, fn func() error) *Step {
 	step := s.AddStep(name, StatusNotRun)
 	err := fn()
-	if err != nil {
+	var inconclusiveErr inconclusiveError
+	if errors.As(err, &inconclusiveErr) {
+		step.AddError(err)
+		step.Status = StatusError
+		step.ErrorCode = errorCode(err)
+	} else if err != nil {
 		step.AddError(err)
 		step.Status = StatusFailure
+		step.ErrorCode = errorCode(err)
 	} else {
 		step.Status = StatusSuccess
 	}
 	return step
 }
 
+// RunStepContext runs fn as a sub step, passing it ctx. If ctx is already done the step is not run and is marked as
+// skipped instead.
+func (s *Step) RunStepContext(ctx context.Context, name string, fn func(ctx context.Context) error) *Step {
+	if ctx.Err() != nil {
+		return s.SkipStep(name, "cancelled")
+	}
+	return s.RunStep(name, func() error {
+		return fn(ctx)
+	})
+}
+
 func (s *Step) AddError(err error) {
 	s.Errors = append(s.Errors, err.Error())
 }
 
+// DidFail reports whether the step or any of its sub steps failed or ended in an error.
 func (s *Step) DidFail() bool {
-	if s.Status == StatusFailure {
+	return s.hasStatus(StatusFailure) || s.hasStatus(StatusError)
+}
+
+// hasStatus reports whether the step or any of its sub steps has the status.
+func (s *Step) hasStatus(status Status) bool {
+	if s.Status == status {
 		return true
 	}
 
 	for _, step := range s.SubSteps {
-		if step.DidFail() {
+		if step.hasStatus(status) {
 			return true
 		}
 	}
 	return false
 }
+
+func (s *Step) DidWarn() bool {
+	if s.Status == StatusWarning {
+		return true
+	}
+
+	for _, step := range s.SubSteps {
+		if step.DidWarn() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProblems reports whether the step or any of its sub steps did not succeed, that is failed, warned or was skipped.
+func (s *Step) hasProblems() bool {
+	if s.Status != StatusSuccess {
+		return true
+	}
+
+	for _, step := range s.SubSteps {
+		if step.hasProblems() {
+			return true
+		}
+	}
+	return false
+}
+
+// AddEvidence records a piece of structured evidence under the given key, replacing any previous value.
+func (s *Step) AddEvidence(key string, value any) {
+	if s.Evidence == nil {
+		s.Evidence = make(map[string]any)
+	}
+	s.Evidence[key] = value
+}
+
+// AddProviders associates the step with the given providers, as "namespace/name", under the "providers" evidence. The
+// renderers use it to group the report by provider.
+func (s *Step) AddProviders(providers ...string) {
+	merged := s.Providers()
+	for _, provider := range providers {
+		if !slices.Contains(merged, provider) {
+			merged = append(merged, provider)
+		}
+	}
+	sort.Strings(merged)
+	s.AddEvidence("providers", merged)
+}
+
+// Providers returns the providers the step is associated with, including in a result that was read back from JSON.
+func (s *Step) Providers() []string {
+	switch providers := s.Evidence["providers"].(type) {
+	case []string:
+		return slices.Clone(providers)
+	case []any:
+		var result []string
+		for _, provider := range providers {
+			if name, ok := provider.(string); ok {
+				result = append(result, name)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// AddRemediation records how to fix the problem the step found.
+func (s *Step) AddRemediation(remediation Remediation) {
+	s.Remediations = append(s.Remediations, remediation)
+}
+
+// addEvidence records all the given evidence. Steps collect their evidence while running and add it afterwards, as
+// the step itself is only returned once it has completed.
+func (s *Step) addEvidence(evidence map[string]any) {
+	for key, value := range evidence {
+		s.AddEvidence(key, value)
+	}
+}
+
+// withID sets the stable identifier of the step and returns it.
+func (s *Step) withID(id string) *Step {
+	s.ID = id
+	return s
+}
+
+// SkipStep adds a sub step that was not run, recording the reason as a remark.
+func (s *Step) SkipStep(name string, reason string) *Step {
+	step := s.AddStep(name, StatusSkipped)
+	step.Remarks = append(step.Remarks, reason)
+	return step
+}
+
+// inconclusiveError marks an error that kept a step from reaching a verdict, such as a connectivity failure, so that
+// it is not mistaken for a negative result.
+type inconclusiveError struct {
+	err error
+}
+
+// inconclusive marks err as keeping the step from reaching a verdict.
+func inconclusive(err error) error {
+	return inconclusiveError{err: err}
+}
+
+func (e inconclusiveError) Error() string {
+	return e.err.Error()
+}
+
+func (e inconclusiveError) Unwrap() error {
+	return e.err
+}
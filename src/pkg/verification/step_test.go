@@ -0,0 +1,77 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStepContext(t *testing.T) {
+	step := Step{}
+	ran := false
+	s := step.RunStepContext(context.Background(), "Sub Step 1", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+
+	assert.True(t, ran)
+	assert.Equal(t, StatusSuccess, s.Status)
+}
+
+func TestRunStepContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	step := Step{}
+	s := step.RunStepContext(ctx, "Sub Step 1", func(ctx context.Context) error {
+		t.Fatal("step should not run once the context is cancelled")
+		return nil
+	})
+
+	assert.Equal(t, StatusSkipped, s.Status)
+	assert.Equal(t, []string{"cancelled"}, s.Remarks)
+}
+
+func TestProgress(t *testing.T) {
+	var reported []string
+	step := &Step{Name: "Step 1", progress: func(parent *Step, step *Step) {
+		reported = append(reported, string(step.Status)+" "+step.Name)
+	}}
+
+	step.RunStep("Sub Step 1", func() error {
+		return nil
+	})
+	assert.Empty(t, reported, "a sub step is only reported once it can no longer change")
+
+	s := step.RunStep("Sub Step 2", func() error {
+		return errors.New("boom")
+	})
+	s.FailureToWarning()
+	step.SkipStep("Sub Step 3", "filtered")
+	step.reportProgress()
+
+	assert.Equal(t, []string{"success Sub Step 1", "warning Sub Step 2", "skipped Sub Step 3"}, reported)
+}
+
+func TestStepProviders(t *testing.T) {
+	s := &Step{}
+	assert.Empty(t, s.Providers())
+
+	s.AddProviders("b/bar", "a/foo")
+	s.AddProviders("a/foo", "c/baz")
+	assert.Equal(t, []string{"a/foo", "b/bar", "c/baz"}, s.Providers())
+
+	// Read back from a JSON result
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Step
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"a/foo", "b/bar", "c/baz"}, decoded.Providers())
+}
@@ -0,0 +1,372 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opentofu/registry-stable/internal/github"
+)
+
+// Stable identifiers for the verification steps, used to select which steps are run.
+const (
+	StepIDTrailingData     = "trailing-data"
+	StepIDExpiry           = "expiry"
+	StepIDValidity         = "validity"
+	StepIDSelfSigAge       = "self-signature-age"
+	StepIDRevocation       = "revocation"
+	StepIDRevocationSigs   = "revocation-signatures"
+	StepIDDenylist         = "denylist"
+	StepIDRevokers         = "designated-revokers"
+	StepIDSigning          = "signing"
+	StepIDCrossCert        = "cross-certification"
+	StepIDTofuCompat       = "tofu-compatibility"
+	StepIDSubkeys          = "subkeys"
+	StepIDEncryption       = "encryption"
+	StepIDPreferences      = "preferences"
+	StepIDSigHashes        = "signature-hashes"
+	StepIDIdentity         = "identity"
+	StepIDSingleIdentity   = "single-identity"
+	StepIDPrimaryUID       = "primary-uid"
+	StepIDFilename         = "filename"
+	StepIDSignature        = "signature"
+	StepIDOrgMembership    = "org-membership"
+	StepIDOrgDomain        = "org-domain"
+	StepIDGithubAccount    = "github-account"
+	StepIDGithubEmail      = "github-email"
+	StepIDGithubKey        = "github-key"
+	StepIDProviders        = "providers"
+	StepIDReleaseSignature = "release-signature"
+	StepIDReleaseChecksums = "release-checksums"
+)
+
+// StepDefinition describes a verification step that can be selected with a StepFilter. The definitions are the single
+// source of the step names, their documentation and how severe a failing check is.
+type StepDefinition struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"` // English name of the step, some contain a verb for a value such as the organization
+	Description string `json:"description"`
+	Rationale   string `json:"rationale"`          // Why the check matters, for contributors to understand the requirement
+	DocsURL     string `json:"docs_url,omitempty"` // Explains how to fix the key if the step fails, optional
+	Severity    Status `json:"severity"`           // StatusFailure, or StatusWarning if a failing check only warns by default
+	ErrorCode   string `json:"error_code"`         // Stable code of a failing check, for aggregating failures across runs
+}
+
+// Error codes of failures caused by GitHub rather than by the checked key or account. They take precedence over the
+// error code of the step.
+const (
+	ErrorCodeUnreachable      = "GH_UNREACHABLE"
+	ErrorCodeRateLimit        = "GH_RATE_LIMIT"
+	ErrorCodePermissionDenied = "GH_PERMISSION_DENIED"
+)
+
+// stepDefinitions lists every step that can be filtered, in the order the steps are run.
+var stepDefinitions = []StepDefinition{
+	{
+		ID:          StepIDTrailingData,
+		Name:        "Key data contains nothing but the key",
+		Description: "The key data contains nothing after the public key block",
+		Rationale:   "Anything after the key, such as a private key or a signature pasted by mistake, ends up in the registry with it",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_TRAILING_DATA",
+	},
+	{
+		ID:          StepIDExpiry,
+		Name:        "Key is not expired",
+		Description: "The key is not expired",
+		Rationale:   "An expired key is no longer valid, new provider releases could not be signed with it",
+		DocsURL:     "https://docs.github.com/en/authentication/managing-commit-signature-verification/updating-an-expired-gpg-key",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_EXPIRED",
+	},
+	{
+		ID:          StepIDValidity,
+		Name:        "Key expires within %d years",
+		Description: "The key does not remain valid for longer than the maximum validity",
+		Rationale:   "A key that never expires stays trusted forever if it is lost or compromised without being revoked",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_LONG_VALIDITY",
+	},
+	{
+		ID:          StepIDSelfSigAge,
+		Name:        "Self-signature was renewed within %d years",
+		Description: "The most recent user ID self-signature is not older than the maximum age",
+		Rationale:   "The self-signature carries the expiry and preferences of the key, an old one may hold stale settings",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_STALE_SELF_SIGNATURE",
+	},
+	{
+		ID:          StepIDRevocation,
+		Name:        "Key is not revoked",
+		Description: "The key is not revoked",
+		Rationale:   "A revoked key must no longer be trusted, its owner has declared it lost, compromised or replaced",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_REVOKED",
+	},
+	{
+		ID:          StepIDRevocationSigs,
+		Name:        "Revocation signatures are valid",
+		Description: "Every revocation signature of the key verifies, with the key of its designated revoker if it made it",
+		Rationale:   "A revocation signature that does not verify is ignored rather than trusted, but it hints at a corrupted or tampered key",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_INVALID_REVOCATION",
+	},
+	{
+		ID:          StepIDDenylist,
+		Name:        "Key is not known to be compromised",
+		Description: "The key is not listed as compromised in the denylist",
+		Rationale:   "Keys known to be compromised must not be added to the registry, whoever holds them could sign malicious providers",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_DENYLISTED",
+	},
+	{
+		ID:          StepIDRevokers,
+		Name:        "Key's designated revokers are known",
+		Description: "The designated revokers of the key are known registry keys",
+		Rationale:   "A designated revoker can revoke the key on the owner's behalf, an unknown revoker could invalidate the key at will",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_DESIGNATED_REVOKER",
+	},
+	{
+		ID:          StepIDSigning,
+		Name:        "Key can be used for signing",
+		Description: "The key can be used for signing",
+		Rationale:   "Provider releases are verified with signatures made by the key, a key that cannot sign is of no use to the registry",
+		DocsURL:     "https://docs.github.com/en/authentication/managing-commit-signature-verification/generating-a-new-gpg-key",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_NO_SIGN_CAP",
+	},
+	{
+		ID:          StepIDCrossCert,
+		Name:        "Signing subkeys are cross-certified",
+		Description: "Signing subkeys are cross-certified by the primary key",
+		Rationale:   "Without the cross-certification, anyone could attach somebody else's signing subkey to their own key",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_NO_CROSS_CERT",
+	},
+	{
+		ID:          StepIDTofuCompat,
+		Name:        "OpenTofu can verify signatures made by the key",
+		Description: "A signing (sub)key of the key uses a format and algorithm OpenTofu accepts provider signatures from",
+		Rationale:   "A key that passes every other check is still useless if OpenTofu cannot verify the signatures it makes",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_TOFU_INCOMPATIBLE",
+	},
+	{
+		ID:          StepIDSubkeys,
+		Name:        "Key has a separate subkey",
+		Description: "The key has subkeys, rather than using its primary key for everything",
+		Rationale:   "A certify-only primary key with a separate signing subkey limits the damage if the signing key is compromised",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_BAD_SUBKEY",
+	},
+	{
+		ID:          StepIDEncryption,
+		Name:        "Key can be used for encryption if it declares it",
+		Description: "An encryption (sub)key the key declares is valid and a message can be encrypted to it",
+		Rationale:   "Encryption is not needed to sign providers, but a declared capability that does not work hints at a neglected key",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_ENCRYPTION_UNUSABLE",
+	},
+	{
+		ID:          StepIDPreferences,
+		Name:        "Key declares acceptable hash preferences",
+		Description: "The key declares acceptable hash preferences, informational unless SHA-1 preferences are rejected",
+		Rationale:   "Signatures follow the key's hash preferences, a key that prefers SHA-1 produces signatures that can be forged",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_WEAK_PREFERENCES",
+	},
+	{
+		ID:          StepIDSigHashes,
+		Name:        "Key self-signatures do not use SHA1",
+		Description: "The self-signatures of the key do not use SHA-1",
+		Rationale:   "Self-signatures made with SHA-1 can be forged, which lets an attacker change the key's user IDs or expiry",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_WEAK_SIGNATURE_HASH",
+	},
+	{
+		ID:          StepIDIdentity,
+		Name:        "Key has a valid identity and email. (Email is preferable but optional)",
+		Description: "The key has a valid identity and, preferably, an email address",
+		Rationale:   "The user ID tells users of the registry who owns the key and how to reach them",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_BAD_IDENTITY",
+	},
+	{
+		ID:          StepIDSingleIdentity,
+		Name:        "Key's user IDs belong to a single identity",
+		Description: "The user IDs of the key belong to a single identity",
+		Rationale:   "User IDs of unrelated people on one key suggest the key is shared or was not generated for this submission",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_MULTIPLE_IDENTITIES",
+	},
+	{
+		ID:          StepIDPrimaryUID,
+		Name:        "Key designates a single primary user ID",
+		Description: "The key designates a single primary user ID",
+		Rationale:   "Tools display the primary user ID as the owner of the key, several primary user IDs make that ambiguous",
+		Severity:    StatusWarning,
+		ErrorCode:   "GPG_PRIMARY_UID",
+	},
+	{
+		ID:          StepIDFilename,
+		Name:        "Key file name matches the key fingerprint",
+		Description: "The key file name matches the key fingerprint",
+		Rationale:   "The registry stores keys under their fingerprint, so that a key can be found and replaced unambiguously",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_FILENAME_MISMATCH",
+	},
+	{
+		ID:          StepIDSignature,
+		Name:        "Key made the supplied signature",
+		Description: "The key made the supplied signature",
+		Rationale:   "Signing a message proves that the submitter controls the private key, not only its public part",
+		Severity:    StatusFailure,
+		ErrorCode:   "GPG_BAD_SIGNATURE",
+	},
+	{
+		ID:          StepIDOrgMembership,
+		Name:        "User is a member of the organization %s",
+		Description: "The GitHub user is a member of the organization",
+		Rationale:   "Only members of an organization may submit keys that sign the organization's providers",
+		Severity:    StatusFailure,
+		ErrorCode:   "GH_NOT_MEMBER",
+	},
+	{
+		ID:          StepIDOrgDomain,
+		Name:        "Key email uses a verified domain of the organization %s",
+		Description: "An email of the key's user IDs belongs to a domain the GitHub organization has verified",
+		Rationale:   "An email on a domain the organization verified ties the key to an identity the organization controls",
+		Severity:    StatusWarning,
+		ErrorCode:   "GH_UNVERIFIED_DOMAIN",
+	},
+	{
+		ID:          StepIDGithubAccount,
+		Name:        "GitHub account %s is an active user account",
+		Description: "The GitHub account is an active user account",
+		Rationale:   "Keys should belong to a person who can be held accountable, not to a bot or a suspended account",
+		Severity:    StatusWarning,
+		ErrorCode:   "GH_INACTIVE_ACCOUNT",
+	},
+	{
+		ID:          StepIDGithubEmail,
+		Name:        "Key email matches the public email of GitHub account %s",
+		Description: "An email of the key's user IDs is the public email of the GitHub account",
+		Rationale:   "A key email that matches the GitHub account strengthens the link between the key and its submitter",
+		Severity:    StatusWarning,
+		ErrorCode:   "GH_EMAIL_MISMATCH",
+	},
+	{
+		ID:          StepIDGithubKey,
+		Name:        "Key is registered on the GitHub account of %s",
+		Description: "The key is registered on the GitHub account of the user",
+		Rationale:   "A key registered on the submitter's GitHub account shows that the account owner uses the key",
+		DocsURL:     "https://docs.github.com/en/authentication/managing-commit-signature-verification/adding-a-gpg-key-to-your-github-account",
+		Severity:    StatusFailure,
+		ErrorCode:   "GH_KEY_NOT_REGISTERED",
+	},
+	{
+		ID:          StepIDProviders,
+		Name:        "Key has signed a provider in %s",
+		Description: "The key has signed the latest release of a provider in the namespace",
+		Rationale:   "A key that signed the namespace's providers is the key OpenTofu needs to verify them",
+		Severity:    StatusWarning,
+		ErrorCode:   "REG_NO_SIGNED_PROVIDER",
+	},
+	{
+		ID:          StepIDReleaseSignature,
+		Name:        "Key signed the checksums of release %s",
+		Description: "The key signed the SHA256SUMS file of the given GitHub release",
+		Rationale:   "Signing the release's checksums shows that the key is the one used to publish the provider",
+		Severity:    StatusFailure,
+		ErrorCode:   "GH_RELEASE_NOT_SIGNED",
+	},
+	{
+		ID:          StepIDReleaseChecksums,
+		Name:        "Signed checksums match the artifacts of release %s",
+		Description: "The signed SHA256SUMS file lists SHA-256 digests that match the release's artifacts",
+		Rationale:   "A signature over checksums that do not match the artifacts does not protect the artifacts users download",
+		Severity:    StatusFailure,
+		ErrorCode:   "GH_RELEASE_DIGEST_MISMATCH",
+	},
+}
+
+// stepIDs lists the identifiers of stepDefinitions.
+var stepIDs = func() []string {
+	ids := make([]string, len(stepDefinitions))
+	for i, step := range stepDefinitions {
+		ids[i] = step.ID
+	}
+	return ids
+}()
+
+// Steps returns the definitions of all steps that can be selected with a StepFilter, in the order they are run.
+func Steps() []StepDefinition {
+	return append([]StepDefinition(nil), stepDefinitions...)
+}
+
+// stepDefinition returns the definition of the step with the given identifier.
+func stepDefinition(id string) StepDefinition {
+	for _, step := range stepDefinitions {
+		if step.ID == id {
+			return step
+		}
+	}
+	panic(fmt.Sprintf("no definition for step %q", id))
+}
+
+// stepName returns the name of the step with the given identifier, translated by the catalog and formatted with args.
+func (c Catalog) stepName(id string, args ...any) string {
+	name := c.StepName(id, stepDefinition(id).Name)
+	if len(args) > 0 {
+		name = fmt.Sprintf(name, args...)
+	}
+	return name
+}
+
+// runStep runs the step with the given identifier as a sub step of parent, unless the filter or a severity override
+// skips it. If the definition of the step only warns by default, a failure is downgraded to a warning unless escalate
+// is set. A severity override in opts.Severity takes precedence over both.
+func (opts VerifyKeyOptions) runStep(parent *Step, id string, name string, escalate bool, fn func() error) *Step {
+	if opts.Severity[id] == StatusSkipped {
+		return parent.SkipStep(name, "skipped by a severity override").withID(id)
+	}
+	step := opts.Filter.RunStep(parent, id, name, fn)
+	return opts.finishStep(step, escalate)
+}
+
+// runStepContext is the context aware variant of runStep.
+func (opts VerifyKeyOptions) runStepContext(ctx context.Context, parent *Step, id string, name string, escalate bool, fn func(ctx context.Context) error) *Step {
+	if opts.Severity[id] == StatusSkipped {
+		return parent.SkipStep(name, "skipped by a severity override").withID(id)
+	}
+	step := opts.Filter.RunStepContext(ctx, parent, id, name, fn)
+	return opts.finishStep(step, escalate)
+}
+
+// finishStep records the documentation and the error code of the step and applies its severity.
+func (opts VerifyKeyOptions) finishStep(step *Step, escalate bool) *Step {
+	definition := stepDefinition(step.ID)
+	step.DocsURL = definition.DocsURL
+	if step.ErrorCode == "" && (step.Status == StatusFailure || step.Status == StatusError) {
+		step.ErrorCode = definition.ErrorCode
+	}
+	if opts.Severity.severity(definition, escalate) == StatusWarning {
+		step.FailureToWarning()
+	}
+	return step
+}
+
+// errorCode returns the error code of failures caused by err no matter which step they occur in, or an empty string if
+// the error code of the step applies.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, github.ErrRetryBudgetExhausted):
+		return ErrorCodeRateLimit
+	case github.IsUnreachable(err):
+		return ErrorCodeUnreachable
+	case errors.Is(err, github.ErrPermissionDenied):
+		return ErrorCodePermissionDenied
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/github"
+)
+
+func TestStepDefinitions(t *testing.T) {
+	seen := make(map[string]bool)
+	codes := make(map[string]bool)
+	for _, step := range stepDefinitions {
+		assert.False(t, seen[step.ID], "duplicate step %s", step.ID)
+		seen[step.ID] = true
+		assert.Regexp(t, `^(GPG|GH|REG)_[A-Z_]+$`, step.ErrorCode, step.ID)
+		assert.False(t, codes[step.ErrorCode], "duplicate error code %s", step.ErrorCode)
+		codes[step.ErrorCode] = true
+		assert.NotEmpty(t, step.Name, step.ID)
+		assert.NotEmpty(t, step.Description, step.ID)
+		assert.NotEmpty(t, step.Rationale, step.ID)
+		assert.Contains(t, []Status{StatusFailure, StatusWarning}, step.Severity, step.ID)
+	}
+}
+
+func TestRunStep_Severity(t *testing.T) {
+	failing := func() error { return errors.New("failed") }
+
+	tests := []struct {
+		name     string
+		id       string
+		escalate bool
+		severity SeverityOverrides
+		status   Status
+	}{
+		{
+			name:   "failure",
+			id:     StepIDExpiry,
+			status: StatusFailure,
+		},
+		{
+			name:   "warning",
+			id:     StepIDPrimaryUID,
+			status: StatusWarning,
+		},
+		{
+			name:     "escalated warning",
+			id:       StepIDPrimaryUID,
+			escalate: true,
+			status:   StatusFailure,
+		},
+		{
+			name:     "failure overridden to warning",
+			id:       StepIDExpiry,
+			severity: SeverityOverrides{StepIDExpiry: StatusWarning},
+			status:   StatusWarning,
+		},
+		{
+			name:     "warning overridden to failure",
+			id:       StepIDValidity,
+			severity: SeverityOverrides{StepIDValidity: StatusFailure},
+			status:   StatusFailure,
+		},
+		{
+			name:     "override takes precedence over escalation",
+			id:       StepIDSigHashes,
+			escalate: true,
+			severity: SeverityOverrides{StepIDSigHashes: StatusWarning},
+			status:   StatusWarning,
+		},
+		{
+			name:     "skipped",
+			id:       StepIDExpiry,
+			severity: SeverityOverrides{StepIDExpiry: StatusSkipped},
+			status:   StatusSkipped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := &Step{}
+			opts := VerifyKeyOptions{Severity: tt.severity}
+			step := opts.runStep(parent, tt.id, opts.Catalog.stepName(tt.id), tt.escalate, failing)
+			assert.Equal(t, tt.id, step.ID)
+			assert.Equal(t, tt.status, step.Status)
+			if tt.status != StatusSkipped {
+				assert.Equal(t, stepDefinition(tt.id).DocsURL, step.DocsURL)
+			}
+		})
+	}
+}
+
+func TestRunStep_ErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		err  error
+		code string
+	}{
+		{
+			name: "passing step",
+			id:   StepIDExpiry,
+		},
+		{
+			name: "failing step",
+			id:   StepIDExpiry,
+			err:  errors.New("key is expired"),
+			code: "GPG_EXPIRED",
+		},
+		{
+			name: "warning step",
+			id:   StepIDGithubAccount,
+			err:  errors.New("the account is of type Bot, not a user account"),
+			code: "GH_INACTIVE_ACCOUNT",
+		},
+		{
+			name: "github unreachable",
+			id:   StepIDOrgMembership,
+			err:  checkReachable(&url.Error{Op: "Get", URL: "https://api.github.com", Err: errors.New("connection refused")}),
+			code: ErrorCodeUnreachable,
+		},
+		{
+			name: "rate limited",
+			id:   StepIDGithubKey,
+			err:  checkReachable(fmt.Errorf("failed: %w", github.ErrRetryBudgetExhausted)),
+			code: ErrorCodeRateLimit,
+		},
+		{
+			name: "permission denied",
+			id:   StepIDOrgMembership,
+			err:  fmt.Errorf("failed to get user: %w", github.ErrPermissionDenied),
+			code: ErrorCodePermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := VerifyKeyOptions{}
+			step := opts.runStep(&Step{}, tt.id, opts.Catalog.stepName(tt.id), false, func() error { return tt.err })
+			assert.Equal(t, tt.code, step.ErrorCode)
+		})
+	}
+}
+
+func TestParseSeverityOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		expected SeverityOverrides
+		err      string
+	}{
+		{
+			name:     "empty",
+			expected: SeverityOverrides{},
+		},
+		{
+			name:     "overrides",
+			list:     "validity=failure, expiry = warning,subkeys=skip",
+			expected: SeverityOverrides{StepIDValidity: StatusFailure, StepIDExpiry: StatusWarning, StepIDSubkeys: StatusSkipped},
+		},
+		{
+			name: "unknown step",
+			list: "near-expiry=failure",
+			err:  `unknown step "near-expiry"`,
+		},
+		{
+			name: "unknown severity",
+			list: "expiry=error",
+			err:  `invalid severity "error" for step "expiry", expected warning, failure or skip`,
+		},
+		{
+			name: "missing severity",
+			list: "expiry",
+			err:  `invalid severity override "expiry", expected step-id=warning|failure|skip`,
+		},
+		{
+			name: "duplicate step",
+			list: "expiry=warning,expiry=failure",
+			err:  `step "expiry" has more than one severity override`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := ParseSeverityOverrides(tt.list)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, overrides)
+		})
+	}
+}
+
+func TestCatalogStepName(t *testing.T) {
+	german, err := NewCatalog("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var english Catalog
+
+	assert.Equal(t, "Key expires within 5 years", english.stepName(StepIDValidity, 5))
+	assert.Equal(t, "Schlüssel läuft innerhalb von 5 Jahren ab", german.stepName(StepIDValidity, 5))
+	assert.Equal(t, "Key is not expired", english.stepName(StepIDExpiry))
+}
@@ -0,0 +1,117 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// VerifyKeyOptions configures how the GPG key is loaded, who it is verified against and which optional checks are
+// enforced.
+type VerifyKeyOptions struct {
+	KeyData               []byte             // The key itself, takes precedence over KeyFile and KeyEnv
+	KeyFile               string             // Location of the key on the filesystem
+	KeyEnv                string             // Name of the environment variable containing the base64 encoded key
+	Username              string             // GitHub username to verify the key against
+	Org                   string             // GitHub organization the user must be a member of, the namespace of the key is Username if empty
+	SkipOrgCheck          bool               // Skip the organization membership check, for keys of personal namespaces
+	UserNamespace         bool               // The key is for the personal namespace of Username, its providers are scanned instead of Org's
+	RejectSHA1Prefs       bool               // Fail if the key prefers SHA-1 as its hash algorithm, the preferences are only reported otherwise
+	Strict                bool               // Fail if the key prefers SHA-1, and rather than warn if its self-signatures use it
+	Now                   func() time.Time   // Returns the time to check expiry and revocation at, defaults to time.Now
+	MaxValidityYears      int                // Keys valid for longer are reported as a warning, defaults to 10 years
+	MaxSelfSigAgeYears    int                // Warn if the latest self-signature is older, the check is skipped if zero
+	CheckEncryption       bool               // Warn if the key declares encryption capability but cannot be encrypted to
+	AllowSuperseded       bool               // Warn, rather than fail, if the key was only revoked because it was superseded
+	RejectTrailingData    bool               // Fail, rather than warn, if the key data contains anything after the public key block
+	Denylist              map[string]bool    // Upper case fingerprints of compromised keys, the check is skipped if nil
+	CheckFilename         bool               // Verify that the key file name matches the key fingerprint
+	CheckTofuCompat       bool               // Verify that OpenTofu could verify provider signatures made by the key
+	ShowNotations         bool               // Report user ID notations and identity proofs as remarks
+	EchoKey               bool               // Include the re-armored public key in the result
+	SignatureFile         string             // Detached signature the key owner made over MessageFile, optional
+	MessageFile           string             // Message signed by SignatureFile
+	CompareGithubKey      bool               // Require the key to be registered on the GitHub account of Username
+	CompareGithubKeyExact bool               // Additionally require the registered key to be identical, not only to share the fingerprint
+	CheckOrgDomains       bool               // Warn if no email of the key belongs to a verified domain of Org
+	CompareGithubEmail    bool               // Warn if no email of the key is the public email of the GitHub account of Username
+	RejectInactiveAccount bool               // Fail, rather than warn, if the GitHub account is a bot or appears suspended
+	Filter                StepFilter         // Selects which steps are run
+	Severity              SeverityOverrides  // Overrides the severity of individual steps, optional
+	Progress              ProgressFunc       // Called with each step as it completes, optional
+	Catalog               Catalog            // Translated step names, English if nil
+	ProviderDataDir       string             // Directory containing the provider data, the providers scan is skipped if empty and no Registries are given
+	Registries            []ProviderRegistry // Further registries, such as private mirrors, whose providers the key may have signed, the outcome in each is recorded
+	KeyDataDir            string             // Directory containing the registry's GPG keys, used to locate designated revokers
+	ProviderOrgs          []string           // Organizations whose providers may have been signed by the key, defaults to the namespace of the key
+	FullCoverage          bool               // Check every release of every provider instead of stopping at the first signed latest release, and record which ones the key signed
+	NewKey                bool               // The key is registered before the first release, the provider signature check is skipped
+	RequireSignedProvider bool               // Fail, rather than warn, if the key signed none of the providers
+	ReleaseRepo           string             // GitHub repository (owner/name) of a release whose checksums the key must have signed, optional
+	ReleaseTag            string             // Tag of the release in ReleaseRepo
+	VerifyReleaseDigests  bool               // Download the release assets and compare them to the signed checksums
+	Github                GithubClient       // Client used for all GitHub lookups, not required if Local is set
+	Local                 bool               // Only run the key and signature checks, without GitHub or the registry
+}
+
+// namespace returns the registry namespace the key is submitted for: the organization, or the user's own namespace if
+// opts.UserNamespace is set or no organization is given.
+func (opts VerifyKeyOptions) namespace() string {
+	if opts.userNamespace() {
+		return opts.Username
+	}
+	return opts.Org
+}
+
+// userNamespace returns true if the key is submitted for the user's own namespace rather than an organization.
+func (opts VerifyKeyOptions) userNamespace() bool {
+	return opts.UserNamespace || opts.Org == ""
+}
+
+// now returns the time the key is verified at.
+func (opts VerifyKeyOptions) now() time.Time {
+	if opts.Now != nil {
+		return opts.Now()
+	}
+	return time.Now()
+}
+
+// Verify runs all verification steps and returns the result without rendering it.
+// An error is only returned if the verification could not be performed at all; failing checks are reported in the
+// returned Result.
+func Verify(ctx context.Context, opts VerifyKeyOptions) (*Result, error) {
+	if opts.Github == nil && !opts.Local {
+		return nil, fmt.Errorf("a GitHub client is required to verify the key")
+	}
+	if opts.Local {
+		// Designated revokers are otherwise looked up among the registry keys
+		opts.KeyDataDir = ""
+	}
+
+	result := &Result{}
+
+	keyStep, key := VerifyKey(opts)
+	result.Steps = append(result.Steps, keyStep)
+	if opts.EchoKey && key != nil {
+		// GetArmoredPublicKey only serializes the public part, so no secret material can end up in the result
+		armored, err := key.GetArmoredPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to armor the verified key: %w", err)
+		}
+		result.ArmoredKey = armored
+	}
+	if !opts.Local {
+		result.Steps = append(result.Steps, VerifyGithubUser(ctx, opts, key))
+		result.Steps = append(result.Steps, VerifyKeyInProviders(ctx, opts, key))
+		if opts.ReleaseRepo != "" {
+			result.Steps = append(result.Steps, VerifyReleaseSignature(ctx, opts, key))
+		}
+	}
+
+	if ctx.Err() != nil {
+		result.Cancelled = true
+	}
+	result.ComputeStatus()
+
+	return result, nil
+}
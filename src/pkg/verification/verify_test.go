@@ -0,0 +1,325 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentofu/registry-stable/internal/github"
+)
+
+type fakeGithubClient struct {
+	member    bool
+	err       error
+	assets    map[string][]byte
+	assetErrs map[string]error
+	gpgKeys   []github.GPGKey
+	user      *github.User              // Defaults to an active user account
+	releases  map[string]github.Release // Keyed by "<repository>@<tag>"
+	domains   []string                  // Verified domains of every organization
+}
+
+func (f fakeGithubClient) GetOrgVerifiedDomains(_ string) ([]string, error) {
+	return f.domains, nil
+}
+
+func (f fakeGithubClient) IsUserInOrganization(_ string, _ string) (bool, error) {
+	return f.member, f.err
+}
+
+func (f fakeGithubClient) GetUserGPGKeys(_ string) ([]github.GPGKey, error) {
+	return f.gpgKeys, f.err
+}
+
+func (f fakeGithubClient) GetUser(username string) (github.User, error) {
+	if f.user == nil {
+		return github.User{Login: username, Type: "User"}, f.err
+	}
+	return *f.user, f.err
+}
+
+func (f fakeGithubClient) DownloadAssetContents(downloadURL string) ([]byte, error) {
+	return f.assets[downloadURL], f.assetErrs[downloadURL]
+}
+
+func (f fakeGithubClient) GetReleaseByTag(repository string, tag string) (github.Release, error) {
+	release, ok := f.releases[repository+"@"+tag]
+	if !ok {
+		return github.Release{}, fmt.Errorf("no release found for tag %q in %s", tag, repository)
+	}
+	return release, nil
+}
+
+func writeTestKey(t *testing.T) string {
+	t.Helper()
+
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.asc")
+	err = os.WriteFile(path, []byte(publicKey), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerify(t *testing.T) {
+	keyFile := writeTestKey(t)
+
+	tests := []struct {
+		name       string
+		opts       VerifyKeyOptions
+		expectFail bool
+	}{
+		{
+			name:       "valid key and member",
+			opts:       VerifyKeyOptions{KeyFile: keyFile, Github: fakeGithubClient{member: true}},
+			expectFail: false,
+		},
+		{
+			name:       "valid key but not a member",
+			opts:       VerifyKeyOptions{KeyFile: keyFile, Github: fakeGithubClient{member: false}},
+			expectFail: true,
+		},
+		{
+			name:       "github error",
+			opts:       VerifyKeyOptions{KeyFile: keyFile, Github: fakeGithubClient{err: errors.New("boom")}},
+			expectFail: true,
+		},
+		{
+			name:       "missing key file",
+			opts:       VerifyKeyOptions{KeyFile: filepath.Join(t.TempDir(), "missing.asc"), Github: fakeGithubClient{member: true}},
+			expectFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Verify(context.Background(), test.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectFail, result.DidFail())
+		})
+	}
+}
+
+func TestVerify_RequiresGithubClient(t *testing.T) {
+	_, err := Verify(context.Background(), VerifyKeyOptions{})
+	assert.ErrorContains(t, err, "a GitHub client is required")
+}
+
+func TestVerify_EchoKey(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Submit the private key to make sure that no secret material is echoed back
+	armoredPrivate, err := key.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.asc")
+	err = os.WriteFile(keyFile, []byte(armoredPrivate), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Verify(context.Background(), VerifyKeyOptions{KeyFile: keyFile, EchoKey: true, Github: fakeGithubClient{member: true}})
+	assert.NoError(t, err)
+	assert.Contains(t, result.ArmoredKey, "BEGIN PGP PUBLIC KEY BLOCK")
+	assert.NotContains(t, result.ArmoredKey, "PRIVATE")
+
+	echoed, err := crypto.NewKeyFromArmored(result.ArmoredKey)
+	assert.NoError(t, err)
+	assert.False(t, echoed.IsPrivate())
+	assert.Equal(t, key.GetFingerprint(), echoed.GetFingerprint())
+}
+
+func TestVerify_DetachedSignature(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signer.SignDetached(crypto.NewPlainMessage([]byte("nonce")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"key.asc":   []byte(publicKey),
+		"nonce.sig": signature.GetBinary(),
+		"nonce.txt": []byte("nonce"),
+		"other.txt": []byte("other"),
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		message    string
+		expectFail bool
+	}{
+		{
+			name:       "signed message",
+			message:    "nonce.txt",
+			expectFail: false,
+		},
+		{
+			name:       "different message",
+			message:    "other.txt",
+			expectFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Verify(context.Background(), VerifyKeyOptions{
+				KeyFile:       filepath.Join(dir, "key.asc"),
+				SignatureFile: filepath.Join(dir, "nonce.sig"),
+				MessageFile:   filepath.Join(dir, test.message),
+				Github:        fakeGithubClient{member: true},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectFail, result.DidFail())
+		})
+	}
+}
+
+func TestVerify_Local(t *testing.T) {
+	key, err := crypto.GenerateKey("Test User", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crypto.GenerateKey("Other User", "other@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sums := []byte("abc  terraform-provider-foo_1.0.0_linux_amd64.zip\n")
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"key.asc":    []byte(publicKey),
+		"SHA256SUMS": sums,
+	}
+	for name, signer := range map[string]*crypto.Key{"SHA256SUMS.sig": key, "other.sig": other} {
+		keyRing, err := crypto.NewKeyRing(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := keyRing.SignDetached(crypto.NewPlainMessage(sums))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = signature.GetBinary()
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		signature  string
+		issuer     string
+		expectFail bool
+	}{
+		{
+			name:      "signed by the key",
+			signature: "SHA256SUMS.sig",
+			issuer:    key.GetHexKeyID(),
+		},
+		{
+			name:       "signed by another key",
+			signature:  "other.sig",
+			issuer:     other.GetHexKeyID(),
+			expectFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Verify(context.Background(), VerifyKeyOptions{
+				KeyFile:       filepath.Join(dir, "key.asc"),
+				SignatureFile: filepath.Join(dir, test.signature),
+				MessageFile:   filepath.Join(dir, "SHA256SUMS"),
+				Local:         true,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectFail, result.DidFail())
+			// Only the key checks are run
+			assert.Len(t, result.Steps, 1)
+
+			signatureStep := result.Steps[0].SubSteps[len(result.Steps[0].SubSteps)-1]
+			assert.Contains(t, signatureStep.Remarks, "Signature was made by key ID "+strings.ToUpper(test.issuer))
+		})
+	}
+}
+
+func TestVerify_StepIDs(t *testing.T) {
+	keyFile := writeTestKey(t)
+
+	tests := []struct {
+		name string
+		opts VerifyKeyOptions
+	}{
+		{
+			name: "all steps",
+			opts: VerifyKeyOptions{KeyFile: keyFile, CheckFilename: true, CompareGithubKey: true, Github: fakeGithubClient{member: true}},
+		},
+		{
+			name: "filtered steps",
+			opts: VerifyKeyOptions{KeyFile: keyFile, Filter: StepFilter{skip: map[string]bool{StepIDExpiry: true, StepIDOrgMembership: true}}, Github: fakeGithubClient{member: true}},
+		},
+		{
+			name: "unparseable key",
+			opts: VerifyKeyOptions{KeyData: []byte("not a key"), CompareGithubKey: true, ProviderDataDir: t.TempDir(), Github: fakeGithubClient{member: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Verify(context.Background(), test.opts)
+			assert.NoError(t, err)
+			for _, step := range result.Steps {
+				assert.NotEmpty(t, step.ID, step.Name)
+				for _, subStep := range step.SubSteps {
+					assert.NotEmpty(t, subStep.ID, subStep.Name)
+					// Every step that can be filtered must be listed among the step definitions
+					if subStep.ID != messageIDParse {
+						assert.True(t, isKnownStepID(subStep.ID), subStep.ID)
+					}
+				}
+			}
+		})
+	}
+}